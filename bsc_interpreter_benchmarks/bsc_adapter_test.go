@@ -0,0 +1,183 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Adapter executing engine neutral corpus vectors on the BSC interpreter.
+// Vectors run as a call from corpus.DefaultCaller to corpus.DefaultRecipient
+// with the recipient's storage committed to the trie, so that original
+// values are seen by SSTORE gas accounting, and with sender, recipient and
+// precompiles pre-warmed as done by a transaction. From Cancun the
+// transaction carries the blobs of corpus.BlobHashes. The revision is selected
+// with -revision and defaults to Cancun.
+package main
+
+import (
+	"errors"
+	"flag"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/holiman/uint256"
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+var revisionFlag = flag.String("revision", corpus.DefaultRevision.String(), "EVM revision corpus vectors are executed in")
+
+// corpusRevision returns the revision selected with -revision.
+func corpusRevision() corpus.Revision {
+	revision, err := corpus.ParseRevision(*revisionFlag)
+	if err != nil {
+		panic(err)
+	}
+	return revision
+}
+
+// bscRunner executes a single vector repeatedly. Every run is rolled back,
+// so each iteration observes the same pre-state.
+type bscRunner struct {
+	evm         *vm.EVM
+	statedb     *state.StateDB
+	interpreter *vm.EVMInterpreter
+	contract    *vm.Contract
+	input       []byte
+	gas         uint64
+	logs        []*types.Log // emitted by the last run
+}
+
+func newBSCRunner(vector corpus.Vector) (*bscRunner, error) {
+	return newBSCRunnerWithConfig(vector, vm.Config{})
+}
+
+func newBSCRunnerWithConfig(vector corpus.Vector, config vm.Config) (*bscRunner, error) {
+	caller := common.Address(corpus.DefaultCaller)
+	recipient := common.Address(corpus.DefaultRecipient)
+
+	// Commit the pre-state and reopen it to make it the committed state of
+	// the transaction.
+	db := state.NewDatabase(triedb.NewDatabase(rawdb.NewMemoryDatabase(), nil), nil)
+	statedb, err := state.New(common.Hash{}, db)
+	if err != nil {
+		return nil, err
+	}
+	statedb.CreateAccount(caller)
+	statedb.CreateAccount(recipient)
+	statedb.SetCode(recipient, vector.Code)
+	for key, value := range vector.Storage {
+		statedb.SetState(recipient, common.Hash(key), common.Hash(value))
+	}
+	root, _, err := statedb.Commit(0, false, false)
+	if err != nil {
+		return nil, err
+	}
+	if statedb, err = state.New(root, db); err != nil {
+		return nil, err
+	}
+	return newBSCRunnerOnState(vector, statedb, nil, config), nil
+}
+
+// newBSCRunnerOnState sets up a vector executing on a given committed
+// state, in which the storage of the recipient is the vector's. The storage
+// keys of the recipient in accessList are pre-warmed as by an EIP-2930
+// access list.
+func newBSCRunnerOnState(vector corpus.Vector, statedb *state.StateDB, accessList []common.Hash, config vm.Config) *bscRunner {
+	caller := common.Address(corpus.DefaultCaller)
+	recipient := common.Address(corpus.DefaultRecipient)
+	revision := corpusRevision()
+	evm := vm.NewEVM(bscBlockContext(revision), statedb, bscChainConfig(revision), config)
+	txContext := vm.TxContext{Origin: caller, GasPrice: big.NewInt(0)}
+	if revision >= corpus.Cancun {
+		for _, hash := range corpus.BlobHashes {
+			txContext.BlobHashes = append(txContext.BlobHashes, common.Hash(hash))
+		}
+	}
+	evm.SetTxContext(txContext)
+	rules := evm.ChainConfig().Rules(evm.Context.BlockNumber, evm.Context.Random != nil, evm.Context.Time)
+	var list types.AccessList
+	if len(accessList) > 0 {
+		list = types.AccessList{{Address: recipient, StorageKeys: accessList}}
+	}
+	statedb.Prepare(rules, caller, evm.Context.Coinbase, &recipient, vm.ActivePrecompiles(rules), list)
+
+	contract := vm.NewContract(vm.AccountRef(caller), vm.AccountRef(recipient), uint256.NewInt(0), vector.Gas)
+	contract.SetCallCode(&recipient, crypto.Keccak256Hash(vector.Code), vector.Code)
+
+	return &bscRunner{
+		evm:         evm,
+		statedb:     statedb,
+		interpreter: evm.Interpreter(),
+		contract:    contract,
+		input:       vector.Input,
+		gas:         vector.Gas,
+	}
+}
+
+// run executes the vector once and reverts all state changes afterwards.
+func (r *bscRunner) run() corpus.Outcome {
+	output, err := r.exec()
+	return bscOutcome(r.gas, r.contract.Gas, output, err)
+}
+
+// exec executes the vector once like run and returns the output and error
+// of the interpreter.
+func (r *bscRunner) exec() ([]byte, error) {
+	snapshot := r.statedb.Snapshot()
+	r.contract.Gas = r.gas
+	output, err := r.interpreter.Run(r.contract, r.input, false)
+	// The StateDB collects logs under the empty transaction hash. The
+	// rollback drops them but leaves them in place until the next run.
+	r.logs = r.statedb.GetLogs(common.Hash{}, 0, common.Hash{})
+	r.statedb.RevertToSnapshot(snapshot)
+	return output, err
+}
+
+// runWithLogs executes the vector once like run and adds the logs emitted
+// to the outcome. Benchmarks use run, keeping the conversion of the logs
+// out of their timing.
+func (r *bscRunner) runWithLogs() corpus.Outcome {
+	outcome := r.run()
+	if outcome.Status == corpus.Success {
+		outcome.Logs = bscLogs(r.logs)
+	}
+	return outcome
+}
+
+// bscLogs converts the logs collected by the StateDB into engine neutral
+// logs. The data is shared, the topics are copied into a single slice.
+func bscLogs(logs []*types.Log) []corpus.Log {
+	if len(logs) == 0 {
+		return nil
+	}
+	count := 0
+	for _, log := range logs {
+		count += len(log.Topics)
+	}
+	topics := make([]corpus.Hash, 0, count)
+	res := make([]corpus.Log, len(logs))
+	for i, log := range logs {
+		start := len(topics)
+		for _, topic := range log.Topics {
+			topics = append(topics, corpus.Hash(topic))
+		}
+		res[i] = corpus.Log{Topics: topics[start:len(topics):len(topics)], Data: log.Data}
+	}
+	return res
+}
+
+// bscOutcome converts the result of an interpreter run into an engine
+// neutral outcome. Errors other than reverts consume all gas, as done by
+// the EVM for the outermost call.
+func bscOutcome(gas, gasLeft uint64, output []byte, err error) corpus.Outcome {
+	switch {
+	case err == nil:
+		return corpus.Outcome{Status: corpus.Success, Output: output, GasUsed: gas - gasLeft}
+	case errors.Is(err, vm.ErrExecutionReverted):
+		return corpus.Outcome{Status: corpus.Revert, Output: output, GasUsed: gas - gasLeft}
+	default:
+		return corpus.Outcome{Status: corpus.Failure, GasUsed: gas}
+	}
+}
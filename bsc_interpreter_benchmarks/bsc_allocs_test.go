@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Allocation attribution report for the BSC interpreter. Run with:
+// go test -run TestAllocationReport -alloc-report
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/sonicoperations/evmbench/allocs"
+)
+
+var allocReport = flag.Bool("alloc-report", false, "print the per-vector allocation breakdown")
+
+// bscAllocationRules attribute BSC interpreter allocations to the parts of a
+// run. StateDB allocations are reported as host allocations, matching the
+// treatment of the run context on the Tosca side.
+func bscAllocationRules() []allocs.Rule {
+	pkg := allocs.CallerPackage()
+	return append([]allocs.Rule{
+		{Category: "stack allocation", Prefixes: []string{"vm.newstack"}},
+		{Category: "memory growth", Prefixes: []string{"vm.NewMemory", "vm.(*Memory).Resize", "vm.(*Memory).Set"}},
+		{Category: "result copy", Prefixes: []string{"vm.(*Memory).GetCopy", "vm.opReturn", "vm.opRevert"}},
+		{Category: "log topics", Prefixes: []string{"vm.makeLog"}},
+		{Category: "jumpdest analysis", Prefixes: []string{"vm.codeBitmap", "vm.(*Contract).isCode", "vm.(*Contract).validJumpdest"}},
+		{Category: "host: precompiles", Prefixes: []string{"vm.RunPrecompiledContract", "vm.(*ecrecover)", "vm.(*sha256hash)", "vm.(*bigModExp)"}},
+		{Category: "host: state", Prefixes: []string{"state."}},
+		{Category: "call frames", Prefixes: []string{"vm.(*EVM)."}},
+		{Category: "execution context", Prefixes: []string{"vm.(*EVMInterpreter).Run"}, SiteOnly: true},
+	}, allocs.HarnessRules(pkg)...)
+}
+
+func TestAllocationReport(t *testing.T) {
+	if !*allocReport {
+		t.Skip("enable with -alloc-report")
+	}
+	rules := bscAllocationRules()
+	var reports []allocs.Report
+
+	// The loop body of BenchmarkInterpreterSimpleOperations, with the code
+	// decoded on every iteration to match the legacy LFVM vector.
+	interpreter, contract := createInterpreterAndContract()
+	reports = append(reports, allocs.Measure("bsc", "legacy/SimpleArithmetic", 1000, rules, func() {
+		code, _ := hex.DecodeString(simpleArithmeticCode)
+		_, _ = execInterpreterDirect(interpreter, contract, code, 10_000)
+	}))
+
+	for _, contract := range loadContracts(t) {
+		for _, vector := range contract.Vectors() {
+			runner, err := newBSCRunner(vector)
+			if err != nil {
+				t.Fatalf("Failed to set up vector %s: %v", vector.Name, err)
+			}
+			reports = append(reports, allocs.Measure("bsc", vector.Name, 1000, rules, func() {
+				runner.run()
+			}))
+		}
+	}
+	if err := allocs.WriteMarkdown(os.Stdout, reports...); err != nil {
+		t.Fatal(err)
+	}
+}
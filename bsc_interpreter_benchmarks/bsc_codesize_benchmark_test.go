@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Code size scaling benchmarks for the JUMPDEST analysis of the BSC
+// interpreter. Analyses the code of the code size families of the shared
+// corpus, as done on the first jump into a contract whose analysis is not
+// cached. evmbench codesize relates the times to LFVM's code conversion.
+//
+// go-ethereum runs the analysis only inside the interpreter, on the first
+// JUMP of a contract, so timing it through the public path would add the
+// setup of an EVM frame and the jump to every sample, which dominates for
+// small code. The analysis is called through go:linkname instead, pinned
+// to the BSC version of bscLinkedVersion; TestLinkedBSC_MatchesPin and
+// TestCodeBitmap_AgreesWithJumps check the pin and the linked function on
+// every test run.
+package main
+
+import (
+	"runtime/debug"
+	"testing"
+	_ "unsafe" // for go:linkname
+
+	"github.com/sonicoperations/evmbench/asm"
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// bscLinkedVersion is the BSC version the go:linkname declarations of this
+// module were written against. Review them when go.mod replaces
+// go-ethereum with another version and update the pin.
+const bscLinkedVersion = "v1.5.10"
+
+// bscCodeBitmap is the JUMPDEST analysis of go-ethereum, returning a bit
+// vector marking push data.
+//
+//go:linkname bscCodeBitmap github.com/ethereum/go-ethereum/core/vm.codeBitmap
+func bscCodeBitmap(code []byte) []byte
+
+func BenchmarkCodeAnalysis(b *testing.B) {
+	for _, family := range corpus.CodeSizeFamilies() {
+		for _, vector := range family.Vectors() {
+			b.Run(vector.Name+"/bitmap", func(b *testing.B) {
+				b.SetBytes(int64(len(vector.Code)))
+				for i := 0; i < b.N; i++ {
+					bscCodeBitmap(vector.Code)
+				}
+			})
+		}
+	}
+}
+
+func TestCodeBitmap_MarksPushData(t *testing.T) {
+	// PUSH2 0x5b5b JUMPDEST: only the last byte is code besides the PUSH2.
+	bits := bscCodeBitmap([]byte{0x61, 0x5b, 0x5b, 0x5b})
+	if len(bits) == 0 || bits[0] != 0b0110 {
+		t.Errorf("unexpected bitmap %08b", bits)
+	}
+}
+
+func TestLinkedBSC_MatchesPin(t *testing.T) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		t.Fatal("no build info")
+	}
+	for _, module := range info.Deps {
+		if module.Path != "github.com/ethereum/go-ethereum" {
+			continue
+		}
+		if module.Replace != nil {
+			module = module.Replace
+		}
+		if module.Version != bscLinkedVersion {
+			t.Errorf("go-ethereum %s differs from BSC %s the go:linkname declarations were written for", module.Version, bscLinkedVersion)
+		}
+	}
+}
+
+func TestCodeBitmap_AgreesWithJumps(t *testing.T) {
+	// Both jump to offset 4, once a JUMPDEST and once push data.
+	for source, status := range map[string]corpus.Status{
+		"PUSH1 4 JUMP STOP JUMPDEST STOP": corpus.Success,
+		"PUSH1 4 JUMP PUSH1 0x5b STOP":    corpus.Failure,
+	} {
+		code := asm.MustAssemble(source)
+		outcome, err := runBSCVector(corpus.Vector{Name: source, Code: code, Gas: 100})
+		if err != nil {
+			t.Fatalf("%s: %v", source, err)
+		}
+		pushData := bscCodeBitmap(code)[0]&(1<<4) != 0
+		if outcome.Status != status || pushData != (status == corpus.Failure) {
+			t.Errorf("%s: status %s, offset 4 marked as push data: %t", source, outcome.Status, pushData)
+		}
+	}
+}
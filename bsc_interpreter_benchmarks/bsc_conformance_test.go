@@ -0,0 +1,491 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Step adapter executing conformance states generated from Tosca's CT
+// specification on the BSC interpreter. BSC offers no way to resume
+// execution from an arbitrary state, so the adapter drives the jump table
+// of an EVMInterpreter instruction by instruction the way
+// EVMInterpreter.Run does, setting the unexported stack, memory and
+// interpreter fields the state prescribes. The layout of BSC's operation
+// type is verified against its mirror before any state is executed.
+//
+// Instructions reach the world state through bscConformanceDB, which
+// answers from the conformance state the way Tosca's host interface does:
+// balances are not moved by SELFDESTRUCT and nested calls and contract
+// creations are not supported.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/big"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+	"github.com/sonicoperations/evmbench/conformance"
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// bscOperation mirrors the unexported operation type of BSC's jump table.
+type bscOperation struct {
+	execute     func(pc *uint64, interpreter *vm.EVMInterpreter, scope *vm.ScopeContext) ([]byte, error)
+	constantGas uint64
+	dynamicGas  func(evm *vm.EVM, contract *vm.Contract, stack *vm.Stack, mem *vm.Memory, memorySize uint64) (uint64, error)
+	minStack    int
+	maxStack    int
+	memorySize  func(stack *vm.Stack) (size uint64, overflow bool)
+	undefined   bool
+}
+
+// bscStopToken returns the unexported error BSC's STOP, RETURN and
+// SELFDESTRUCT use to end the execution, after verifying that
+// bscOperation matches the layout of BSC's operation type.
+var bscStopToken = sync.OnceValues(func() (error, error) {
+	actual := reflect.TypeOf(vm.JumpTable{}).Elem().Elem()
+	mirror := reflect.TypeOf(bscOperation{})
+	if actual.NumField() != mirror.NumField() || actual.Size() != mirror.Size() {
+		return nil, fmt.Errorf("BSC operation type %v does not match %v", actual, mirror)
+	}
+	for i := 0; i < actual.NumField(); i++ {
+		a, m := actual.Field(i), mirror.Field(i)
+		if a.Name != m.Name || a.Offset != m.Offset || !m.Type.AssignableTo(a.Type) {
+			return nil, fmt.Errorf("BSC operation field %s %v does not match %s %v", a.Name, a.Type, m.Name, m.Type)
+		}
+	}
+	// STOP ignores its arguments.
+	table := bscJumpTable(vm.NewEVM(vm.BlockContext{BlockNumber: new(big.Int)}, nil, bscChainConfig(corpus.Istanbul), vm.Config{}).Interpreter())
+	_, stop := table[vm.STOP].execute(new(uint64), nil, nil)
+	return stop, nil
+})
+
+// bscField returns a settable view of an unexported field of a struct.
+func bscField(v reflect.Value, name string) reflect.Value {
+	field := v.FieldByName(name)
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+}
+
+func bscJumpTable(interpreter *vm.EVMInterpreter) *[256]*bscOperation {
+	return (*[256]*bscOperation)(bscField(reflect.ValueOf(interpreter).Elem(), "table").UnsafePointer())
+}
+
+// bscUnsupported lists the instructions resolved from the call journal of
+// the specification, which the adapter cannot provide.
+var bscUnsupported = map[vm.OpCode]bool{
+	vm.CALL: true, vm.CALLCODE: true, vm.DELEGATECALL: true, vm.STATICCALL: true, vm.CREATE: true, vm.CREATE2: true,
+}
+
+// bscConformanceStep executes up to steps instructions of a running state.
+func bscConformanceStep(state conformance.State, steps int) (result conformance.State, err error) {
+	stopToken, err := bscStopToken()
+	if err != nil {
+		return conformance.State{}, err
+	}
+	// Like in the specification, terminal states are not stepped further.
+	if state.Status != conformance.Running {
+		return state, nil
+	}
+	if state.Gas < 0 {
+		return conformance.State{}, fmt.Errorf("negative gas %d", state.Gas)
+	}
+	// Unexpected uses of the world state surface as nil dereferences of the
+	// embedded StateDB.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("BSC interpreter panicked: %v", r)
+		}
+	}()
+	state.Storage = maps.Clone(state.Storage)
+	state.Transient = maps.Clone(state.Transient)
+	state.WarmAccounts = maps.Clone(state.WarmAccounts)
+	state.Logs = slices.Clone(state.Logs)
+	state.SelfDestructs = slices.Clone(state.SelfDestructs)
+
+	db := &bscConformanceDB{state: &state}
+	evm := vm.NewEVM(bscConformanceBlockContext(&state), db, bscConformanceChainConfig(&state), vm.Config{})
+	evm.SetTxContext(vm.TxContext{
+		Origin:     common.Address(state.Block.Origin),
+		GasPrice:   new(big.Int).SetBytes(state.Block.GasPrice[:]),
+		BlobHashes: bscHashes(state.Block.BlobHashes),
+	})
+	interpreter := evm.Interpreter()
+	table := bscJumpTable(interpreter)
+	bscField(reflect.ValueOf(interpreter).Elem(), "readOnly").SetBool(state.ReadOnly)
+	bscField(reflect.ValueOf(interpreter).Elem(), "returnData").SetBytes(bytes.Clone(state.LastCallReturnData))
+
+	address := common.Address(state.Address)
+	contract := vm.NewContract(vm.AccountRef(state.Caller), vm.AccountRef(address), new(uint256.Int).SetBytes32(state.Value[:]), uint64(state.Gas))
+	contract.SetCallCode(&address, crypto.Keccak256Hash(state.Code), state.Code)
+	contract.Input = state.CallData
+
+	stack := new(vm.Stack)
+	data := make([]uint256.Int, len(state.Stack), 1024)
+	for i, value := range state.Stack {
+		data[i].SetBytes32(value[:])
+	}
+	bscField(reflect.ValueOf(stack).Elem(), "data").Set(reflect.ValueOf(data))
+	mem := vm.NewMemory()
+	if size := uint64(len(state.Memory)); size > 0 {
+		mem.Resize(size)
+		mem.Set(0, size, state.Memory)
+		words := (size + 31) / 32
+		bscField(reflect.ValueOf(mem).Elem(), "lastGasCost").SetUint(words*params.MemoryGas + words*words/params.QuadCoeffDiv)
+	}
+	scope := &vm.ScopeContext{Memory: mem, Stack: stack, Contract: contract}
+
+	pc := state.Pc
+	for ; steps > 0 && state.Status == conformance.Running; steps-- {
+		op := contract.GetOp(pc)
+		if bscUnsupported[op] {
+			return conformance.State{}, fmt.Errorf("%v is not supported", op)
+		}
+		operation := table[op]
+		if size := len(stack.Data()); size < operation.minStack || size > operation.maxStack {
+			state.Status = conformance.Failed
+			break
+		}
+		if contract.Gas < operation.constantGas {
+			state.Status = conformance.Failed
+			break
+		}
+		contract.Gas -= operation.constantGas
+		var memorySize uint64
+		if operation.dynamicGas != nil {
+			if operation.memorySize != nil {
+				size, overflow := operation.memorySize(stack)
+				words := bscWordSize(size)
+				if overflow || words > math.MaxUint64/32 {
+					state.Status = conformance.Failed
+					break
+				}
+				memorySize = words * 32
+			}
+			cost, err := operation.dynamicGas(evm, contract, stack, mem, memorySize)
+			if err != nil || contract.Gas < cost {
+				state.Status = conformance.Failed
+				break
+			}
+			contract.Gas -= cost
+		}
+		if memorySize > 0 {
+			mem.Resize(memorySize)
+		}
+		if op == vm.SELFDESTRUCT {
+			db.beneficiary = stack.Back(0).Bytes20()
+		}
+
+		res, err := operation.execute(&pc, interpreter, scope)
+		switch {
+		case err == nil:
+			pc++
+		case err == stopToken:
+			state.Status, state.ReturnData = conformance.Stopped, res
+		case errors.Is(err, vm.ErrExecutionReverted):
+			state.Status, state.ReturnData = conformance.Reverted, res
+		default:
+			state.Status = conformance.Failed
+		}
+	}
+
+	state.Pc, state.Gas = pc, int64(contract.Gas)
+	state.Stack = state.Stack[:0:0]
+	for _, value := range stack.Data() {
+		state.Stack = append(state.Stack, corpus.Hash(value.Bytes32()))
+	}
+	state.Memory = bytes.Clone(mem.Data())
+	state.LastCallReturnData = bytes.Clone(bscField(reflect.ValueOf(interpreter).Elem(), "returnData").Bytes())
+	return state, nil
+}
+
+// bscWordSize rounds a memory size up to words like BSC's toWordSize.
+func bscWordSize(size uint64) uint64 {
+	if size > math.MaxUint64-31 {
+		return math.MaxUint64/32 + 1
+	}
+	return (size + 31) / 32
+}
+
+func bscHashes(hashes []corpus.Hash) []common.Hash {
+	res := make([]common.Hash, len(hashes))
+	for i, hash := range hashes {
+		res[i] = common.Hash(hash)
+	}
+	return res
+}
+
+// bscConformanceChainConfig returns the chain configuration of a state's
+// revision with its chain id.
+func bscConformanceChainConfig(state *conformance.State) *params.ChainConfig {
+	config := bscChainConfig(state.Revision)
+	config.ChainID = new(big.Int).SetBytes(state.Block.ChainID[:])
+	return config
+}
+
+// bscConformanceBlockContext returns the block environment of a state.
+// PrevRandao provides the difficulty before Paris.
+func bscConformanceBlockContext(state *conformance.State) vm.BlockContext {
+	block := &state.Block
+	context := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash:     func(number uint64) common.Hash { return common.Hash(state.BlockHash(number)) },
+		Coinbase:    common.Address(block.Coinbase),
+		GasLimit:    block.GasLimit,
+		BlockNumber: new(big.Int).SetUint64(block.Number),
+		Time:        block.Timestamp,
+		Difficulty:  new(big.Int).SetBytes(block.PrevRandao[:]),
+		BaseFee:     new(big.Int).SetBytes(block.BaseFee[:]),
+		BlobBaseFee: new(big.Int).SetBytes(block.BlobBaseFee[:]),
+	}
+	if state.Revision >= corpus.Paris {
+		random := common.Hash(block.PrevRandao)
+		context.Random = &random
+	}
+	return context
+}
+
+// bscConformanceDB is the world state of a conformance state. It provides
+// the methods instructions other than calls and creations use; all others
+// panic on the embedded nil StateDB.
+type bscConformanceDB struct {
+	vm.StateDB
+	state       *conformance.State
+	beneficiary common.Address // of the SELFDESTRUCT being executed
+}
+
+func (db *bscConformanceDB) account(address common.Address) conformance.Account {
+	return db.state.Accounts[corpus.Address(address)]
+}
+
+func (db *bscConformanceDB) GetBalance(address common.Address) *uint256.Int {
+	balance := db.account(address).Balance
+	return new(uint256.Int).SetBytes32(balance[:])
+}
+
+// AddBalance and SubBalance are only used by SELFDESTRUCT, whose balance
+// transfer is left to the host in Tosca's model.
+func (db *bscConformanceDB) AddBalance(common.Address, *uint256.Int, tracing.BalanceChangeReason) uint256.Int {
+	return uint256.Int{}
+}
+
+func (db *bscConformanceDB) SubBalance(common.Address, *uint256.Int, tracing.BalanceChangeReason) uint256.Int {
+	return uint256.Int{}
+}
+
+func (db *bscConformanceDB) GetCode(address common.Address) []byte {
+	return db.account(address).Code
+}
+
+func (db *bscConformanceDB) GetCodeSize(address common.Address) int {
+	return len(db.account(address).Code)
+}
+
+func (db *bscConformanceDB) GetCodeHash(address common.Address) common.Hash {
+	return crypto.Keccak256Hash(db.account(address).Code)
+}
+
+func (db *bscConformanceDB) Exist(address common.Address) bool {
+	_, found := db.state.Accounts[corpus.Address(address)]
+	return found
+}
+
+// Empty ignores nonces, which are not part of a conformance state.
+func (db *bscConformanceDB) Empty(address common.Address) bool {
+	account := db.account(address)
+	return account.Balance == corpus.Hash{} && len(account.Code) == 0
+}
+
+func (db *bscConformanceDB) GetState(_ common.Address, key common.Hash) common.Hash {
+	return common.Hash(db.state.Storage[corpus.Hash(key)].Current)
+}
+
+func (db *bscConformanceDB) GetCommittedState(_ common.Address, key common.Hash) common.Hash {
+	return common.Hash(db.state.Storage[corpus.Hash(key)].Original)
+}
+
+func (db *bscConformanceDB) SetState(_ common.Address, key, value common.Hash) common.Hash {
+	if db.state.Storage == nil {
+		db.state.Storage = make(map[corpus.Hash]conformance.Slot)
+	}
+	slot := db.state.Storage[corpus.Hash(key)]
+	previous := slot.Current
+	slot.Current = corpus.Hash(value)
+	db.state.Storage[corpus.Hash(key)] = slot
+	return common.Hash(previous)
+}
+
+func (db *bscConformanceDB) GetTransientState(_ common.Address, key common.Hash) common.Hash {
+	return common.Hash(db.state.Transient[corpus.Hash(key)])
+}
+
+func (db *bscConformanceDB) SetTransientState(_ common.Address, key, value common.Hash) {
+	if db.state.Transient == nil {
+		db.state.Transient = make(map[corpus.Hash]corpus.Hash)
+	}
+	db.state.Transient[corpus.Hash(key)] = corpus.Hash(value)
+}
+
+func (db *bscConformanceDB) AddRefund(gas uint64) { db.state.Refund += int64(gas) }
+func (db *bscConformanceDB) SubRefund(gas uint64) { db.state.Refund -= int64(gas) }
+func (db *bscConformanceDB) GetRefund() uint64    { return uint64(db.state.Refund) }
+
+func (db *bscConformanceDB) AddressInAccessList(address common.Address) bool {
+	return db.state.WarmAccounts[corpus.Address(address)]
+}
+
+func (db *bscConformanceDB) SlotInAccessList(address common.Address, key common.Hash) (bool, bool) {
+	return db.AddressInAccessList(address), db.state.Storage[corpus.Hash(key)].Warm
+}
+
+func (db *bscConformanceDB) AddAddressToAccessList(address common.Address) {
+	if db.state.WarmAccounts == nil {
+		db.state.WarmAccounts = make(map[corpus.Address]bool)
+	}
+	db.state.WarmAccounts[corpus.Address(address)] = true
+}
+
+func (db *bscConformanceDB) AddSlotToAccessList(_ common.Address, key common.Hash) {
+	if db.state.Storage == nil {
+		db.state.Storage = make(map[corpus.Hash]conformance.Slot)
+	}
+	slot := db.state.Storage[corpus.Hash(key)]
+	slot.Warm = true
+	db.state.Storage[corpus.Hash(key)] = slot
+}
+
+func (db *bscConformanceDB) HasSelfDestructed(address common.Address) bool {
+	return address == common.Address(db.state.Address) && db.state.HasSelfDestructed
+}
+
+func (db *bscConformanceDB) SelfDestruct(address common.Address) uint256.Int {
+	db.state.HasSelfDestructed = true
+	db.state.SelfDestructs = append(db.state.SelfDestructs, conformance.SelfDestruct{
+		Account:     corpus.Address(address),
+		Beneficiary: corpus.Address(db.beneficiary),
+	})
+	return uint256.Int{}
+}
+
+func (db *bscConformanceDB) SelfDestruct6780(address common.Address) (uint256.Int, bool) {
+	return db.SelfDestruct(address), true
+}
+
+func (db *bscConformanceDB) AddLog(log *types.Log) {
+	entry := conformance.Log{Data: bytes.Clone(log.Data)}
+	for _, topic := range log.Topics {
+		entry.Topics = append(entry.Topics, corpus.Hash(topic))
+	}
+	db.state.Logs = append(db.state.Logs, entry)
+}
+
+func (db *bscConformanceDB) Witness() *stateless.Witness { return nil }
+
+// conformanceState returns a running state of code in the corpus
+// environment of bscRunner.
+func conformanceState(code []byte, gas int64) conformance.State {
+	return conformance.State{
+		Status:       conformance.Running,
+		Revision:     corpusRevision(),
+		Gas:          gas,
+		Code:         code,
+		Address:      corpus.DefaultRecipient,
+		Caller:       corpus.DefaultCaller,
+		WarmAccounts: map[corpus.Address]bool{corpus.DefaultRecipient: true, corpus.DefaultCaller: true},
+		Block:        conformance.Block{Number: 1},
+	}
+}
+
+func TestConformanceStep_MatchesRun(t *testing.T) {
+	// SSTORE(0, 1+2); MSTORE(0x40, SLOAD(0)); MSTORE8(0x80, 1); LOG1; RETURN(0x40, 0x20)
+	code := []byte{
+		0x60, 0x01, 0x60, 0x02, 0x01, 0x60, 0x00, 0x55,
+		0x60, 0x00, 0x54, 0x60, 0x40, 0x52,
+		0x60, 0x01, 0x60, 0x80, 0x53,
+		0x60, 0x07, 0x60, 0x20, 0x60, 0x40, 0xa1,
+		0x60, 0x20, 0x60, 0x40, 0xf3,
+	}
+	const gas = 100_000
+	runner, err := newBSCRunner(corpus.Vector{Code: code, Gas: gas})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := runner.run()
+
+	state := conformanceState(code, gas)
+	for state.Status == conformance.Running {
+		if state, err = bscConformanceStep(state, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state.Status != conformance.Stopped || !bytes.Equal(state.ReturnData, want.Output) || uint64(gas-state.Gas) != want.GasUsed {
+		t.Errorf("stepping ended in %s with output %x after %d gas, Run returned %+v", state.Status, state.ReturnData, gas-state.Gas, want)
+	}
+	if slot := state.Storage[corpus.Hash{}]; slot.Current != (corpus.Hash{31: 3}) || !slot.Warm {
+		t.Errorf("unexpected slot %+v", slot)
+	}
+	if len(state.Logs) != 1 || state.Logs[0].Topics[0] != (corpus.Hash{31: 7}) || len(state.Logs[0].Data) != 32 {
+		t.Errorf("unexpected logs %+v", state.Logs)
+	}
+	// Both memory expansions are charged across separate steps.
+	if len(state.Memory) != 0xa0 {
+		t.Errorf("unexpected memory size %d", len(state.Memory))
+	}
+}
+
+func TestConformanceStep_ResumesStates(t *testing.T) {
+	add := conformanceState([]byte{0x01, 0x00}, 10)
+	add.Stack = []corpus.Hash{{31: 1}, {31: 2}, {31: 3}}
+	add.Memory = make(corpus.Bytes, 64)
+	state, err := bscConformanceStep(add, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Status != conformance.Running || state.Pc != 1 || state.Gas != 7 || len(state.Stack) != 2 || state.Stack[1] != (corpus.Hash{31: 5}) {
+		t.Errorf("unexpected state after ADD %+v", state)
+	}
+	if len(add.Stack) != 3 {
+		t.Errorf("input state was modified")
+	}
+
+	// MSTORE within the existing memory costs no expansion.
+	mstore := conformanceState([]byte{0x52}, 10)
+	mstore.Stack = []corpus.Hash{{31: 1}, {31: 0x20}}
+	mstore.Memory = make(corpus.Bytes, 64)
+	if state, err = bscConformanceStep(mstore, 1); err != nil || state.Gas != 7 {
+		t.Errorf("unexpected MSTORE gas %d, error %v", state.Gas, err)
+	}
+
+	outOfGas := conformanceState([]byte{0x01}, 2)
+	outOfGas.Stack = []corpus.Hash{{}, {}}
+	invalidJump := conformanceState([]byte{0x56}, 10)
+	invalidJump.Stack = []corpus.Hash{{31: 5}}
+	failures := map[string]conformance.State{
+		"out of gas":      outOfGas,
+		"stack underflow": conformanceState([]byte{0x01}, 10),
+		"invalid jump":    invalidJump,
+	}
+	for name, state := range failures {
+		if result, err := bscConformanceStep(state, 1); err != nil || result.Status != conformance.Failed {
+			t.Errorf("%s: unexpected status %s, error %v", name, result.Status, err)
+		}
+	}
+
+	call := conformanceState([]byte{0xf1}, 10)
+	if _, err := bscConformanceStep(call, 1); err == nil {
+		t.Errorf("CALL must not be supported")
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Contract deployment benchmarks for the BSC interpreter. Executes the
+// deployment vectors of the shared corpus, whose factory runs the initcode
+// of the BEP20 token and the workload contracts with CREATE and CREATE2
+// through evm.Create and evm.Create2, and checks the created address, code
+// hash and code size.
+package main
+
+import (
+	"testing"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// loadDeployments returns the deployment vectors executed in the revision.
+func loadDeployments(tb testing.TB) []corpus.Vector {
+	tb.Helper()
+	vectors, err := corpus.Deployments(corpusRevision())
+	if err != nil {
+		tb.Fatalf("Failed to load deployments: %v", err)
+	}
+	if len(vectors) == 0 {
+		tb.Skipf("deployments require %s", corpus.DeploymentsSince)
+	}
+	return vectors
+}
+
+func TestDeployments(t *testing.T) {
+	checkVectors(t, "bsc", loadDeployments(t), runBSCVector)
+}
+
+func BenchmarkDeployments(b *testing.B) {
+	for _, vector := range loadDeployments(b) {
+		b.Run(vector.Name, func(b *testing.B) {
+			runner, err := newBSCRunner(vector)
+			if err != nil {
+				b.Fatalf("Failed to set up vector: %v", err)
+			}
+			run := func() {
+				checkStatus(b, vector, runner.run())
+			}
+			run() // warm-up
+			b.ResetTimer()
+			session := startPerf(b)
+			for i := 0; i < b.N; i++ {
+				run()
+			}
+			stopPerf(b, session, vector)
+		})
+	}
+}
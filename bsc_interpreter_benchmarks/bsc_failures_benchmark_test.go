@@ -0,0 +1,39 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Failure path benchmarks for the BSC interpreter. Executes the failure
+// vectors of the shared corpus, which run out of gas, jump to invalid
+// destinations, under- or overflow the stack, hit INVALID or revert, and
+// times the cost of failing.
+package main
+
+import (
+	"testing"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+func TestFailures(t *testing.T) {
+	checkVectors(t, "bsc", corpus.FailureVectors(), runBSCVector)
+}
+
+func BenchmarkFailures(b *testing.B) {
+	for _, vector := range corpus.FailureVectors() {
+		b.Run(vector.Name, func(b *testing.B) {
+			runner, err := newBSCRunner(vector)
+			if err != nil {
+				b.Fatalf("Failed to set up vector: %v", err)
+			}
+			run := func() {
+				checkStatus(b, vector, runner.run())
+			}
+			run() // warm-up
+			b.ResetTimer()
+			session := startPerf(b)
+			for i := 0; i < b.N; i++ {
+				run()
+			}
+			stopPerf(b, session, vector)
+		})
+	}
+}
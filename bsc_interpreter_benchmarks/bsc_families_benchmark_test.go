@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Synthetic vector family benchmarks for the BSC interpreter. Executes the
+// families of the shared corpus, whose members differ in a single parameter
+// such as the number of dispatched instructions, for linear fits with
+// evmbench fit.
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+func TestFamilies(t *testing.T) {
+	for _, family := range corpus.Families() {
+		t.Run(family.Name, func(t *testing.T) {
+			if corpusRevision() < family.Since {
+				t.Skipf("%s requires %s", family.Name, family.Since)
+			}
+			var gasUsed []uint64
+			for _, vector := range family.Vectors() {
+				_, member, _ := strings.Cut(vector.Name, "/")
+				t.Run(member, func(t *testing.T) {
+					outcome, err := runBSCVector(vector)
+					if err != nil {
+						t.Fatalf("Execution failed: %v", err)
+					}
+					gasUsed = append(gasUsed, outcome.GasUsed)
+					if err := vector.Expect.Check(outcome); err != nil {
+						t.Error(err)
+					}
+				})
+			}
+			if err := family.CheckGas(gasUsed); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func BenchmarkFamilies(b *testing.B) {
+	for _, family := range corpus.Families() {
+		for _, vector := range family.Vectors() {
+			b.Run(vector.Name, func(b *testing.B) {
+				if corpusRevision() < family.Since {
+					b.Skipf("%s requires %s", family.Name, family.Since)
+				}
+				runner, err := newBSCRunner(vector)
+				if err != nil {
+					b.Fatalf("Failed to set up vector: %v", err)
+				}
+				run := func() {
+					checkStatus(b, vector, runner.run())
+				}
+				run() // warm-up
+				b.ResetTimer()
+				session := startPerf(b)
+				for i := 0; i < b.N; i++ {
+					run()
+				}
+				stopPerf(b, session, vector)
+			})
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Mixed workload of the BSC interpreter under the garbage collector.
+// Executes the soak mix, the contract corpus, the failure vectors and, from
+// Shanghai, the deployments, round-robin on one long-lived EVM and times
+// every execution, so that the tail latencies caused by GC assists and
+// pauses show up next to the throughput. Latencies are kept per vector and
+// reported as geometric means over the vectors. evmbench gcsweep runs it
+// under a grid of GOGC and GOMEMLIMIT values.
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sonicoperations/evmbench/latency"
+)
+
+func BenchmarkMixedWorkload(b *testing.B) {
+	mix := soakWorkload(b).Mix
+	runners := newSoakRunners(b, mix)
+	evm := runners[0].evm
+	for _, runner := range runners {
+		evm.StateDB = runner.statedb
+		runner.run()
+	}
+	latencies := make([]latency.Histogram, len(mix))
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runner := runners[i%len(runners)]
+		start := time.Now()
+		evm.StateDB = runner.statedb
+		runner.run()
+		latencies[i%len(mix)].Record(time.Since(start))
+	}
+	b.StopTimer()
+	runtime.ReadMemStats(&after)
+	latency.Report(latencies, b.ReportMetric)
+	b.ReportMetric(float64(after.NumGC-before.NumGC)/b.Elapsed().Seconds(), "gc/s")
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/holiman/uint256"
+	"github.com/sonicoperations/evmbench/corpus"
 )
 
 // --- Test vectors ----------------------------------------------------------
@@ -32,6 +33,12 @@ const (
 )
 
 // Test patterns that benefit from super instructions
+//
+// BSC runs these as plain EVM code: the pinned bnb-chain/bsc v1.5.10 has no
+// opcode fusion (there is no core/opcodeCompiler package and vm.Config has
+// no option enabling one), so there is no optimized BSC mode to compare
+// against lfvm-si here. Revisit when the pin moves to a release shipping
+// the optimizer.
 var superInstructionPatternsFromTosca = map[string]string{
 	// Stack Manipulation Super Instructions
 	"SWAP1_POP":   "60016002809150600360048091506005600680915060076008809150600960108091506000", // Multiple SWAP1_POP
@@ -87,8 +94,16 @@ func createMinimalBSCEVM() *vm.EVM {
 	balance := uint256.NewInt(1000000000000000000) // 1 BNB
 	statedb.SetBalance(testAddr, balance, tracing.BalanceChangeUnspecified)
 
+	vmConfig := vm.Config{}
+
+	return vm.NewEVM(bscBlockContext(corpus.Cancun), statedb, bscChainConfig(corpus.Cancun), vmConfig)
+}
+
+// bscChainConfig returns the BSC testnet configuration with all forks up to
+// the given revision enabled from genesis.
+func bscChainConfig(revision corpus.Revision) *params.ChainConfig {
 	// BSC Chain configuration (BSC Testnet config)
-	chainConfig := &params.ChainConfig{
+	config := &params.ChainConfig{
 		ChainID:             big.NewInt(97), // BSC Testnet
 		HomesteadBlock:      big.NewInt(0),
 		DAOForkBlock:        nil,
@@ -101,17 +116,31 @@ func createMinimalBSCEVM() *vm.EVM {
 		PetersburgBlock:     big.NewInt(0),
 		IstanbulBlock:       big.NewInt(0),
 		MuirGlacierBlock:    big.NewInt(0),
-		BerlinBlock:         big.NewInt(0),
-		LondonBlock:         big.NewInt(0),
-		ArrowGlacierBlock:   big.NewInt(0),
-		GrayGlacierBlock:    big.NewInt(0),
-		MergeNetsplitBlock:  big.NewInt(0),
-		ShanghaiTime:        new(uint64), // Enable Shanghai for PUSH0
-		CancunTime:          new(uint64), // Enable Cancun for PUSH0
 	}
-	vmConfig := vm.Config{}
+	if revision >= corpus.Berlin {
+		config.BerlinBlock = big.NewInt(0)
+	}
+	if revision >= corpus.London {
+		config.LondonBlock = big.NewInt(0)
+		config.ArrowGlacierBlock = big.NewInt(0)
+		config.GrayGlacierBlock = big.NewInt(0)
+	}
+	if revision >= corpus.Paris {
+		config.MergeNetsplitBlock = big.NewInt(0)
+	}
+	if revision >= corpus.Shanghai {
+		config.ShanghaiTime = new(uint64) // Enable Shanghai for PUSH0
+	}
+	if revision >= corpus.Cancun {
+		config.CancunTime = new(uint64)
+	}
+	return config
+}
 
-	blockContext := vm.BlockContext{
+// bscBlockContext returns the block environment shared by all benchmarks.
+// From Paris on, the EVM follows merge rules if a random value is set.
+func bscBlockContext(revision corpus.Revision) vm.BlockContext {
+	context := vm.BlockContext{
 		CanTransfer: core.CanTransfer,
 		Transfer:    core.Transfer,
 		GetHash:     func(uint64) common.Hash { return common.Hash{} },
@@ -122,8 +151,13 @@ func createMinimalBSCEVM() *vm.EVM {
 		GasLimit:    10000000000,   // 10B gas limit
 		BaseFee:     big.NewInt(0), // BSC has 0 base fee
 	}
-
-	return vm.NewEVM(blockContext, statedb, chainConfig, vmConfig)
+	if revision >= corpus.Paris {
+		context.Random = &common.Hash{}
+	}
+	if revision >= corpus.Cancun {
+		context.BlobBaseFee = big.NewInt(corpus.BlobBaseFee)
+	}
+	return context
 }
 
 // execInterpreterDirect calls the BSC EVMInterpreter.Run method directly with pre-created interpreter and contract
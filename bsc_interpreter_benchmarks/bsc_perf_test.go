@@ -0,0 +1,96 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Hardware performance counters for BSC interpreter benchmarks. Run with:
+// go test -bench BenchmarkContracts -perf [-perf-out bsc.ndjson]
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/perf"
+)
+
+var (
+	perfEnabled = flag.Bool("perf", false, "collect hardware performance counters in benchmarks")
+	perfOut     = flag.String("perf-out", "", "write performance counter records as NDJSON to this file")
+
+	perfCollector   perf.Collector
+	perfUnavailable bool
+	perfPartial     bool
+)
+
+// startPerf starts the counters of a benchmark if enabled. Benchmarks run
+// without counters if they are unavailable.
+func startPerf(b *testing.B) *perf.Session {
+	if !*perfEnabled || perfUnavailable {
+		return nil
+	}
+	session, err := perf.Start()
+	if err != nil {
+		b.Logf("Running without performance counters: %v", err)
+		perfUnavailable = true
+		return nil
+	}
+	if missing := session.Unavailable(); len(missing) > 0 && !perfPartial {
+		b.Logf("Performance counters not supported: %v", missing)
+		perfPartial = true
+	}
+	return session
+}
+
+// stopPerf stops the counters and reports them as benchmark metrics,
+// normalized by the EVM instructions executed per run of the vector.
+func stopPerf(b *testing.B, session *perf.Session, vector corpus.Vector) {
+	if session == nil {
+		return
+	}
+	b.StopTimer()
+	counts, err := session.Stop()
+	if err != nil {
+		b.Fatalf("Failed to read performance counters: %v", err)
+	}
+	steps, err := countSteps(vector)
+	if err != nil {
+		b.Fatalf("Failed to count steps: %v", err)
+	}
+	record := perf.Record{Engine: "bsc", Vector: vector.Name, Runs: b.N, Steps: steps, Counts: counts}
+	record.ReportMetrics(b.ReportMetric)
+	perfCollector.Path = *perfOut
+	if err := perfCollector.Add(record); err != nil {
+		b.Fatalf("Failed to write performance counters: %v", err)
+	}
+}
+
+// countSteps returns the number of EVM instructions executed by a vector,
+// including those of nested calls.
+func countSteps(vector corpus.Vector) (uint64, error) {
+	var steps uint64
+	hooks := &tracing.Hooks{
+		OnOpcode: func(uint64, byte, uint64, uint64, tracing.OpContext, []byte, int, error) {
+			steps++
+		},
+	}
+	runner, err := newBSCRunnerWithConfig(vector, vm.Config{Tracer: hooks})
+	if err != nil {
+		return 0, err
+	}
+	runner.run()
+	return steps, nil
+}
+
+func TestCountSteps_CountsExecutedInstructions(t *testing.T) {
+	// PUSH1 1; PUSH1 2; ADD; POP; STOP
+	vector := corpus.Vector{Name: "simple", Code: []byte{0x60, 0x01, 0x60, 0x02, 0x01, 0x50, 0x00}, Gas: 100_000}
+	steps, err := countSteps(vector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if steps != 5 {
+		t.Errorf("unexpected steps, wanted 5, got %d", steps)
+	}
+}
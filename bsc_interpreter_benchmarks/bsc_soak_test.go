@@ -0,0 +1,137 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Soak mode of the BSC interpreter. Runs a mix of the contract corpus, the
+// failure vectors and, from Shanghai, the deployments on a single
+// long-lived EVM for the duration of -soak and samples the heap, GC pauses,
+// goroutines and the entries of the JUMPDEST analysis cache into the time
+// series of -soak-out. Every -soak-fresh-th execution calls a contract with
+// code never seen before, whose new code hash makes the analysis cache keyed
+// by it analyse and add the code. Fails if memory, the cache or goroutines
+// keep growing after the warm-up. Run it with:
+// go test -run TestSoak -soak 10m -timeout 0
+package main
+
+import (
+	"flag"
+	"fmt"
+	"testing"
+	"time"
+	_ "unsafe" // for go:linkname
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/holiman/uint256"
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/soak"
+)
+
+var (
+	soakFlag         = flag.Duration("soak", 0, "run TestSoak for this duration")
+	soakIntervalFlag = flag.Duration("soak-interval", 5*time.Second, "interval between the runtime samples of TestSoak")
+	soakOutFlag      = flag.String("soak-out", "", "write the runtime samples of TestSoak as NDJSON time series to this file")
+	soakFreshFlag    = flag.Int("soak-fresh", 10, "every n-th execution of TestSoak calls code with a new code hash, 0 for never")
+)
+
+// bscCodeBitmapCache is the process wide cache of JUMPDEST analyses by code
+// hash, bounded to 2000 entries, linked like bscCodeBitmap.
+//
+//go:linkname bscCodeBitmapCache github.com/ethereum/go-ethereum/core/vm.codeBitmapCache
+var bscCodeBitmapCache *lru.Cache
+
+// setFreshCode makes the runner call the given code in a new contract, as
+// the EVM creates one per call; the JUMPDEST analyses a contract collects
+// are never dropped.
+func (r *bscRunner) setFreshCode(code []byte) {
+	recipient := common.Address(corpus.DefaultRecipient)
+	r.contract = vm.NewContract(vm.AccountRef(corpus.DefaultCaller), vm.AccountRef(recipient), uint256.NewInt(0), r.gas)
+	r.contract.SetCallCode(&recipient, crypto.Keccak256Hash(code), code)
+}
+
+// soakWorkload returns the soak mix executed in the revision.
+func soakWorkload(tb testing.TB) soak.Workload {
+	tb.Helper()
+	workload, err := soak.NewWorkload(corpusRevision(), *soakFreshFlag)
+	if err != nil {
+		tb.Fatalf("Failed to load the soak mix: %v", err)
+	}
+	return workload
+}
+
+// newSoakRunners sets up a runner per vector, all executing on the EVM of
+// the first; the EVM is switched to the StateDB of a runner before it runs.
+func newSoakRunners(tb testing.TB, vectors []corpus.Vector) []*bscRunner {
+	tb.Helper()
+	runners := make([]*bscRunner, len(vectors))
+	for i, vector := range vectors {
+		runner, err := newBSCRunner(vector)
+		if err != nil {
+			tb.Fatalf("Failed to set up vector %s: %v", vector.Name, err)
+		}
+		if i > 0 {
+			runner.evm, runner.interpreter = runners[0].evm, runners[0].interpreter
+		}
+		runners[i] = runner
+	}
+	return runners
+}
+
+func TestSoak(t *testing.T) {
+	if *soakFlag == 0 {
+		t.Skip("use -soak <duration> to run the soak mode")
+	}
+	workload := soakWorkload(t)
+	mix, fresh := workload.Mix, workload.Fresh
+	runners := newSoakRunners(t, append(append([]corpus.Vector{}, mix...), fresh...))
+	runners, freshRunners := runners[:len(mix)], runners[len(mix):]
+	evm := runners[0].evm
+
+	run := func(runner *bscRunner, vector corpus.Vector) error {
+		evm.StateDB = runner.statedb
+		outcome := runner.run()
+		if vector.Expect != nil && outcome.Status != vector.Expect.Status {
+			return fmt.Errorf("%s: status %s, want %s", vector.Name, outcome.Status, vector.Expect.Status)
+		}
+		return nil
+	}
+	step := func(n uint64) error {
+		i, code := workload.Step(n)
+		if code == nil {
+			return run(runners[i], mix[i])
+		}
+		freshRunners[i].setFreshCode(code)
+		return run(freshRunners[i], fresh[i])
+	}
+	samples, err := soak.Run(soak.Config{
+		Engine:   "bsc",
+		Duration: *soakFlag,
+		Interval: *soakIntervalFlag,
+		Out:      *soakOutFlag,
+	}, step, bscCodeBitmapCache.Len)
+	if err != nil {
+		t.Fatalf("Soak failed: %v", err)
+	}
+	final := samples[len(samples)-1]
+	t.Logf("%d runs in %v, %d bytes live heap, %d GC cycles, %d cache entries", final.Runs, final.Elapsed, final.HeapLive, final.GCCycles, final.CacheEntries)
+	if err := soak.Check(samples, soak.DefaultLimits); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSoakRunners_ShareEVM(t *testing.T) {
+	fresh := soakWorkload(t).Fresh
+	runners := newSoakRunners(t, fresh)
+	for n, runner := range runners {
+		runners[0].evm.StateDB = runner.statedb
+		runner.setFreshCode(soak.FreshCode(fresh[n].Code, uint64(n)))
+		outcome := runner.runWithLogs()
+		if err := fresh[n].Expect.Check(outcome); err != nil {
+			t.Errorf("%s: %v", fresh[n].Name, err)
+		}
+		if runner.evm != runners[0].evm {
+			t.Errorf("%s: runner has its own EVM", fresh[n].Name)
+		}
+	}
+}
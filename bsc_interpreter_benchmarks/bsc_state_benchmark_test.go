@@ -0,0 +1,274 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// State size sensitivity of SLOAD and SSTORE on the BSC interpreter. A
+// state of 10^3 to 10^7 accounts and as many storage slots of the
+// recipient is generated deterministically into a Pebble database per
+// size, committed to a hash scheme trie and reused by later runs. Slots
+// are accessed statedb-cold, not yet loaded by a fresh StateDB without
+// access list, and warm, already loaded by the StateDB and in the access
+// list, with the snapshot layer on and off. Tosca's context keeps its state in maps, so there is
+// no backend to compare on that side.
+package main
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/pebble"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/hashdb"
+	"github.com/holiman/uint256"
+	"github.com/sonicoperations/evmbench/asm"
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+var (
+	stateSizesFlag = flag.String("state-sizes", "1e3,1e5", "comma separated numbers of accounts and slots of the states of BenchmarkStateSize, up to 1e7")
+	stateDirFlag   = flag.String("state-dir", filepath.Join(os.TempDir(), "evmbench-state"), "directory the states of BenchmarkStateSize are generated into and reused from")
+)
+
+const (
+	stateAccesses  = 64      // slots accessed by every execution
+	stateBatch     = 100_000 // accounts and slots committed at once while generating
+	stateCacheMiB  = 256     // of the database, the clean trie node cache and the snapshot
+	stateCacheSize = stateCacheMiB << 20
+)
+
+// stateAccessCode holds the programs accessing the slots whose keys are
+// given as input, one word each.
+var stateAccessCode = map[string][]byte{
+	"sload": asm.MustAssemble(`
+		PUSH1 0
+		loop: JUMPDEST
+		DUP1 CALLDATASIZE GT ISZERO PUSH @done JUMPI
+		DUP1 CALLDATALOAD SLOAD POP
+		PUSH1 32 ADD PUSH @loop JUMP
+		done: JUMPDEST STOP`),
+	"sstore": asm.MustAssemble(`
+		PUSH1 0
+		loop: JUMPDEST
+		DUP1 CALLDATASIZE GT ISZERO PUSH @done JUMPI
+		DUP1 PUSH1 1 ADD DUP2 CALLDATALOAD SSTORE
+		PUSH1 32 ADD PUSH @loop JUMP
+		done: JUMPDEST STOP`),
+}
+
+// stateKey returns the key of the i-th slot of a generated state.
+func stateKey(i int) common.Hash {
+	var word [32]byte
+	binary.BigEndian.PutUint64(word[24:], uint64(i))
+	return crypto.Keccak256Hash(word[:])
+}
+
+// stateValue returns the value of the i-th slot of a generated state,
+// never equal to the small values stored by the sstore program.
+func stateValue(i int) common.Hash {
+	value := common.Hash{0: 0x5a}
+	binary.BigEndian.PutUint64(value[24:], uint64(i+1))
+	return value
+}
+
+// stateAddress returns the i-th account of a generated state.
+func stateAddress(i int) common.Address {
+	return common.BytesToAddress(crypto.Keccak256([]byte(fmt.Sprintf("account %d", i))))
+}
+
+// stateKeys returns the keys of the slots accessed by the n-th execution,
+// spread over the state by a multiplicative hash of their index.
+func stateKeys(n, size int) []common.Hash {
+	keys := make([]common.Hash, stateAccesses)
+	for j := range keys {
+		keys[j] = stateKey(int(uint64(n*stateAccesses+j) * 2654435761 % uint64(size)))
+	}
+	return keys
+}
+
+// stateInput returns the keys as input of the access programs.
+func stateInput(keys []common.Hash) []byte {
+	input := make([]byte, 0, 32*len(keys))
+	for _, key := range keys {
+		input = append(input, key[:]...)
+	}
+	return input
+}
+
+// benchState is a generated state on disk.
+type benchState struct {
+	disk      ethdb.Database
+	trie      *triedb.Database
+	snapshots *snapshot.Tree
+	root      common.Hash
+}
+
+// openBenchState opens the state of the given size in the -state-dir
+// directory, generating it first if no earlier run did. The snapshot is
+// built on first use as well and journaled for later runs.
+func openBenchState(tb testing.TB, size int) *benchState {
+	tb.Helper()
+	dir := filepath.Join(*stateDirFlag, fmt.Sprintf("state-%d", size))
+	rootFile := dir + ".root" // written once the state is complete
+	kv, err := pebble.New(dir, stateCacheMiB, 256, "", false)
+	if err != nil {
+		tb.Fatalf("Failed to open state database: %v", err)
+	}
+	disk := rawdb.NewDatabase(kv)
+	// Nodes keep trie nodes in a clean cache, unlike hashdb.Defaults.
+	trie := triedb.NewDatabase(disk, &triedb.Config{HashDB: &hashdb.Config{CleanCacheSize: stateCacheSize}})
+	st := &benchState{disk: disk, trie: trie}
+	tb.Cleanup(func() {
+		if st.snapshots != nil {
+			st.snapshots.Release()
+		}
+		st.trie.Close()
+		disk.Close()
+	})
+
+	if data, err := os.ReadFile(rootFile); err == nil {
+		st.root = common.HexToHash(string(data))
+	} else {
+		tb.Logf("Generating state of %d accounts and slots in %s", size, dir)
+		if st.root, err = generateBenchState(st.trie, size); err != nil {
+			tb.Fatalf("Failed to generate state: %v", err)
+		}
+		if err := os.WriteFile(rootFile, []byte(st.root.Hex()), 0o644); err != nil {
+			tb.Fatalf("Failed to write state root: %v", err)
+		}
+	}
+	if st.snapshots, err = snapshot.New(snapshot.Config{CacheSize: stateCacheMiB}, disk, st.trie, st.root, 128, false); err != nil {
+		tb.Fatalf("Failed to open snapshot: %v", err)
+	}
+	if _, err := st.snapshots.Journal(st.root); err != nil {
+		tb.Fatalf("Failed to journal snapshot: %v", err)
+	}
+	return st
+}
+
+// generateBenchState writes size accounts with a balance and a nonce, and
+// size slots of the recipient, in batches committed to the trie database.
+func generateBenchState(tdb *triedb.Database, size int) (common.Hash, error) {
+	db := state.NewDatabase(tdb, nil)
+	root := types.EmptyRootHash
+	for start := 0; start < size; start += stateBatch {
+		statedb, err := state.New(root, db)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if start == 0 {
+			statedb.CreateAccount(common.Address(corpus.DefaultCaller))
+			statedb.CreateAccount(common.Address(corpus.DefaultRecipient))
+			statedb.SetNonce(common.Address(corpus.DefaultRecipient), 1, tracing.NonceChangeUnspecified)
+		}
+		for i := start; i < min(start+stateBatch, size); i++ {
+			statedb.SetBalance(stateAddress(i), uint256.NewInt(1), tracing.BalanceChangeUnspecified)
+			statedb.SetNonce(stateAddress(i), 1, tracing.NonceChangeUnspecified)
+			statedb.SetState(common.Address(corpus.DefaultRecipient), stateKey(i), stateValue(i))
+		}
+		if root, _, err = statedb.Commit(0, false, false); err != nil {
+			return common.Hash{}, err
+		}
+		if err := tdb.Commit(root, false); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	return root, nil
+}
+
+// stateSizes returns the sizes selected with -state-sizes.
+func stateSizes(tb testing.TB) []int {
+	var sizes []int
+	for _, field := range strings.Split(*stateSizesFlag, ",") {
+		size, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || size < stateAccesses || size > 1e7 {
+			tb.Fatalf("Invalid state size %q", field)
+		}
+		sizes = append(sizes, int(size))
+	}
+	return sizes
+}
+
+// formatStateSize names powers of ten like 1e6.
+func formatStateSize(size int) string {
+	exponent := math.Log10(float64(size))
+	if exponent == math.Trunc(exponent) {
+		return fmt.Sprintf("1e%.0f", exponent)
+	}
+	return strconv.Itoa(size)
+}
+
+// BenchmarkStateSize times executions accessing stateAccesses slots with
+// SLOAD or SSTORE, named <size>/<trie|snapshot>/<op>-<statedb-cold|warm>,
+// and reports the time per slot. Statedb-cold executions run on a fresh
+// StateDB, set up outside the timing, and access different slots every
+// iteration; warm ones repeat the same slots on a StateDB that loaded them
+// before. The database is not reopened, so the Pebble, trie clean and
+// snapshot caches keep what earlier iterations read: statedb-cold measures
+// slots the StateDB has not loaded, not slots read from disk.
+func BenchmarkStateSize(b *testing.B) {
+	for _, size := range stateSizes(b) {
+		b.Run(formatStateSize(size), func(b *testing.B) {
+			st := openBenchState(b, size)
+			for _, backend := range []string{"trie", "snapshot"} {
+				snapshots := st.snapshots
+				if backend == "trie" {
+					snapshots = nil
+				}
+				db := state.NewDatabase(st.trie, snapshots)
+				for _, op := range []string{"sload", "sstore"} {
+					vector := corpus.Vector{Code: stateAccessCode[op], Gas: 10_000_000}
+					b.Run(backend+"/"+op+"-statedb-cold", func(b *testing.B) {
+						for i := 0; i < b.N; i++ {
+							b.StopTimer()
+							statedb, err := state.New(st.root, db)
+							if err != nil {
+								b.Fatalf("Failed to open state: %v", err)
+							}
+							runner := newBSCRunnerOnState(vector, statedb, nil, vm.Config{})
+							runner.input = stateInput(stateKeys(i, size))
+							b.StartTimer()
+							if outcome := runner.run(); outcome.Status != corpus.Success {
+								b.Fatalf("Execution failed: %+v", outcome)
+							}
+						}
+						b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*stateAccesses), "ns/slot")
+					})
+					b.Run(backend+"/"+op+"-warm", func(b *testing.B) {
+						statedb, err := state.New(st.root, db)
+						if err != nil {
+							b.Fatalf("Failed to open state: %v", err)
+						}
+						keys := stateKeys(0, size)
+						runner := newBSCRunnerOnState(vector, statedb, keys, vm.Config{})
+						runner.input = stateInput(keys)
+						if outcome := runner.run(); outcome.Status != corpus.Success {
+							b.Fatalf("Execution failed: %+v", outcome)
+						}
+						b.ResetTimer()
+						for i := 0; i < b.N; i++ {
+							if outcome := runner.run(); outcome.Status != corpus.Success {
+								b.Fatalf("Execution failed: %+v", outcome)
+							}
+						}
+						b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*stateAccesses), "ns/slot")
+					})
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Ethereum GeneralStateTests on the BSC interpreter. The fixtures are read
+// from a local checkout given with -statetests, or the corpus sample if
+// unset, and executed with the state test driver of BSC's go-ethereum
+// fork, which sets up the pre-state in a state.StateDB, applies the
+// transaction and checks the post-state root and the logs hash.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/tests"
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/statetest"
+)
+
+var (
+	stateTestsFlag   = flag.String("statetests", "", "GeneralStateTests fixture directory or file; the corpus sample if empty")
+	stateTestFilter  = flag.String("statetest-filter", "", "regular expression selecting subtests by <test>/<fork>/d<i>g<j>v<k>")
+	stateTestResults = flag.String("statetest-results", "", "write the state test results as NDJSON to this file")
+	stateTestVectors = flag.String("statetest-vectors", "", "write failed state tests converted to corpus vectors to this JSON file")
+	vectorFile       = flag.String("vector-file", "", "JSON file of vectors timed by BenchmarkVectorFile")
+)
+
+func TestStateTests(t *testing.T) {
+	filter, err := regexp.Compile(*stateTestFilter)
+	if err != nil {
+		t.Fatalf("Invalid -statetest-filter: %v", err)
+	}
+	options := statetest.Options{Fixtures: *stateTestsFlag, Results: *stateTestResults, Vectors: *stateTestVectors}
+	loaded, err := options.Load()
+	if err != nil {
+		t.Fatalf("Failed to load state tests: %v", err)
+	}
+	res := statetest.Run(loaded, filter, "bsc", runBSCStateTest)
+	if err := options.Report(t, os.Stdout, loaded, res); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// BenchmarkVectorFile times the vectors of a -vector-file, e.g. failed
+// state tests converted with -statetest-vectors.
+func BenchmarkVectorFile(b *testing.B) {
+	if *vectorFile == "" {
+		b.Skip("use -vector-file to benchmark vectors of a file")
+	}
+	vectors, err := corpus.ReadVectors(*vectorFile)
+	if err != nil {
+		b.Fatalf("Failed to read vectors: %v", err)
+	}
+	for _, vector := range vectors {
+		b.Run(vector.Name, func(b *testing.B) {
+			runner, err := newBSCRunner(vector)
+			if err != nil {
+				b.Fatalf("Failed to set up vector: %v", err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				runner.run()
+			}
+		})
+	}
+}
+
+// runBSCStateTest executes a subtest with BSC's state test driver.
+func runBSCStateTest(subtest statetest.Subtest) (statetest.Status, string) {
+	var test tests.StateTest
+	if err := json.Unmarshal(subtest.Test.Raw, &test); err != nil {
+		return statetest.Fail, err.Error()
+	}
+	var config vm.Config
+	if *tracesFlag != "" {
+		tracer, err := createBSCTracer(subtest.Name())
+		if err != nil {
+			return statetest.Fail, err.Error()
+		}
+		config.Tracer = tracer.hooks()
+		defer tracer.close()
+	}
+	err := test.Run(
+		tests.StateSubtest{Fork: subtest.Fork, Index: subtest.Index},
+		config, false, rawdb.HashScheme,
+		func(error, *tests.StateTestState) {},
+	)
+	if err != nil {
+		return statetest.Fail, err.Error()
+	}
+	return statetest.Pass, ""
+}
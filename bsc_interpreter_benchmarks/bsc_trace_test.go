@@ -0,0 +1,191 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// EIP-3155 traces of the BSC interpreter, written with -traces for every
+// vector of TestContracts and subtest of TestStateTests. The trace is
+// collected through the tracing hooks of the EVM. go-ethereum's own JSON
+// logger cannot be used for vectors, which are not run as transactions,
+// and logs faulting instructions twice; bscTracer attaches the error of a
+// fault to the instruction instead.
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/sonicoperations/evmbench/asm"
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/trace"
+)
+
+var tracesFlag = flag.String("traces", "", "write EIP-3155 traces of the vectors and state tests run by TestContracts and TestStateTests to this directory")
+
+// bscTracer writes the steps reported by the tracing hooks. A step is held
+// back until the next one starts, as a fault is reported after the step.
+type bscTracer struct {
+	writer  *trace.Writer
+	env     *tracing.VMContext
+	pending *trace.Step
+	err     error
+}
+
+func newBSCTracer(writer *trace.Writer) *bscTracer {
+	return &bscTracer{writer: writer}
+}
+
+func (t *bscTracer) hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart: func(env *tracing.VMContext, _ *types.Transaction, _ common.Address) { t.env = env },
+		OnOpcode:  t.onOpcode,
+		OnFault:   t.onFault,
+		OnExit:    t.onExit,
+	}
+}
+
+func (t *bscTracer) onOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, _ []byte, depth int, err error) {
+	t.flush()
+	data := scope.StackData()
+	stack := make([]trace.Word, len(data))
+	for i := range data {
+		stack[i] = data[i].Bytes32()
+	}
+	t.pending = &trace.Step{
+		Pc:      pc,
+		Op:      op,
+		Gas:     trace.Quantity(gas),
+		GasCost: trace.Quantity(cost),
+		MemSize: len(scope.MemoryData()),
+		Stack:   stack,
+		Depth:   depth,
+		Refund:  t.env.StateDB.GetRefund(),
+	}
+	if err != nil {
+		t.pending.Error = err.Error()
+	}
+}
+
+func (t *bscTracer) onFault(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, depth int, err error) {
+	if t.pending == nil || t.pending.Pc != pc || t.pending.Depth != depth {
+		t.onOpcode(pc, op, gas, cost, scope, nil, depth, err)
+		return
+	}
+	t.pending.Error = err.Error()
+}
+
+// onExit writes the summary of a transaction's message call.
+func (t *bscTracer) onExit(depth int, output []byte, gasUsed uint64, err error, _ bool) {
+	t.flush()
+	if depth != 0 {
+		return
+	}
+	summary := trace.Summary{Output: hex.EncodeToString(output), GasUsed: trace.Quantity(gasUsed)}
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	t.record(t.writer.Summary(summary))
+}
+
+func (t *bscTracer) flush() {
+	if t.pending != nil {
+		t.record(t.writer.Step(*t.pending))
+		t.pending = nil
+	}
+}
+
+func (t *bscTracer) record(err error) {
+	t.err = errors.Join(t.err, err)
+}
+
+// close writes the pending step and closes the trace file.
+func (t *bscTracer) close() error {
+	t.flush()
+	return errors.Join(t.err, t.writer.Close())
+}
+
+// createBSCTracer creates the trace file of a vector or state test in the
+// -traces directory.
+func createBSCTracer(name string) (*bscTracer, error) {
+	writer, err := trace.Create(filepath.Join(*tracesFlag, trace.FileName(name)))
+	if err != nil {
+		return nil, err
+	}
+	return newBSCTracer(writer), nil
+}
+
+// traceBSCVector executes a vector once with the trace written by tracer.
+// The vector is not a transaction, so its start is announced to the
+// tracer and the summary is derived from the outcome.
+func traceBSCVector(vector corpus.Vector, tracer *bscTracer) (corpus.Outcome, error) {
+	runner, err := newBSCRunnerWithConfig(vector, vm.Config{Tracer: tracer.hooks()})
+	if err != nil {
+		return corpus.Outcome{}, fmt.Errorf("failed to set up vector: %w", err)
+	}
+	runner.evm.Config.Tracer.OnTxStart(runner.evm.GetVMContext(), nil, common.Address(corpus.DefaultCaller))
+	outcome := runner.runWithLogs()
+	tracer.flush()
+	tracer.record(tracer.writer.Summary(trace.OutcomeSummary(outcome)))
+	return outcome, tracer.close()
+}
+
+// traceProgram is traced by both engines' tests, which expect the same
+// steps: memory expansion, a refund from resetting a slot and a REVERT.
+const traceProgram = `
+	PUSH1 0x20 PUSH1 0x01 MSTORE
+	PUSH1 0x07 PUSH1 0x00 SSTORE
+	PUSH1 0x00 PUSH1 0x00 SSTORE
+	PUSH1 0x00 SLOAD
+	PUSH1 0x00 PUSH1 0x00 REVERT`
+
+func TestTrace_RecordsSteps(t *testing.T) {
+	var buffer bytes.Buffer
+	vector := corpus.Vector{Name: "trace", Code: asm.MustAssemble(traceProgram), Gas: 100_000}
+	outcome, err := traceBSCVector(vector, newBSCTracer(trace.NewWriter(&buffer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outcome.Status != corpus.Revert {
+		t.Errorf("unexpected outcome %+v", outcome)
+	}
+	got, err := trace.Read(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := func(values ...byte) []trace.Word {
+		res := []trace.Word{}
+		for _, value := range values {
+			res = append(res, trace.Word{31: value})
+		}
+		return res
+	}
+	step := func(pc uint64, op asm.Opcode, gas, cost uint64, memSize int, refund uint64, stack ...byte) trace.Step {
+		return trace.Step{Pc: pc, Op: byte(op), Gas: trace.Quantity(gas), GasCost: trace.Quantity(cost), MemSize: memSize, Stack: words(stack...), Depth: 1, Refund: refund}
+	}
+	expected := trace.Trace{Steps: []trace.Step{
+		step(0, asm.PUSH1, 100000, 3, 0, 0),
+		step(2, asm.PUSH1, 99997, 3, 0, 0, 0x20),
+		step(4, asm.MSTORE, 99994, 9, 0, 0, 0x20, 0x01),
+		step(5, asm.PUSH1, 99985, 3, 64, 0),
+		step(7, asm.PUSH1, 99982, 3, 64, 0, 0x07),
+		step(9, asm.SSTORE, 99979, 22100, 64, 0, 0x07, 0x00),
+		step(10, asm.PUSH1, 77879, 3, 64, 0),
+		step(12, asm.PUSH1, 77876, 3, 64, 0, 0x00),
+		step(14, asm.SSTORE, 77873, 100, 64, 19900, 0x00, 0x00),
+		step(15, asm.PUSH1, 77773, 3, 64, 19900),
+		step(17, asm.SLOAD, 77770, 100, 64, 19900, 0x00),
+		step(18, asm.PUSH1, 77670, 3, 64, 19900, 0x00),
+		step(20, asm.PUSH1, 77667, 3, 64, 19900, 0x00, 0x00),
+		step(22, asm.REVERT, 77664, 0, 64, 19900, 0x00, 0x00, 0x00),
+	}, Summary: &trace.Summary{GasUsed: 22336, Error: "execution reverted"}}
+	if d := trace.Diff(got, expected); d != nil {
+		t.Errorf("unexpected trace: %v", d)
+	}
+}
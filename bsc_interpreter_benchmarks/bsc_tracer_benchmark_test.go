@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Tracer overhead of the BSC interpreter. Repeats the contract corpus
+// without tracer and with go-ethereum's tracers attached through
+// vm.Config.Tracer: the native no-op and call tracers, the struct logger
+// of debug_traceTransaction and the EIP-3155 JSON logger writing to
+// io.Discard. Every level reports its slowdown against the run without
+// tracer.
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// bscTracerLevel is a tracing configuration of BenchmarkTracers. newHooks
+// creates a fresh tracer for every execution, as the debug API does for
+// every traced transaction; it is nil for the level without tracer.
+type bscTracerLevel struct {
+	name     string
+	newHooks func() (*tracing.Hooks, error)
+}
+
+var bscTracerLevels = []bscTracerLevel{
+	{"none", nil},
+	{"noop", bscNativeTracer("noopTracer")},
+	{"call", bscNativeTracer("callTracer")},
+	{"struct", func() (*tracing.Hooks, error) { return logger.NewStructLogger(&logger.Config{}).Hooks(), nil }},
+	{"json", func() (*tracing.Hooks, error) { return logger.NewJSONLogger(&logger.Config{}, io.Discard), nil }},
+}
+
+// bscNativeTracer returns a constructor of the tracer registered under name
+// by package native, with its default configuration.
+func bscNativeTracer(name string) func() (*tracing.Hooks, error) {
+	return func() (*tracing.Hooks, error) {
+		tracer, err := tracers.DefaultDirectory.New(name, &tracers.Context{}, json.RawMessage("{}"), bscChainConfig(corpusRevision()))
+		if err != nil {
+			return nil, err
+		}
+		return tracer.Hooks, nil
+	}
+}
+
+// bscTracedRunner executes a vector like bscRunner, announcing it to a
+// fresh tracer as the message call of a transaction on every run.
+type bscTracedRunner struct {
+	*bscRunner
+	tx       *types.Transaction
+	newHooks func() (*tracing.Hooks, error)
+}
+
+func newBSCTracedRunner(vector corpus.Vector, level bscTracerLevel) (*bscTracedRunner, error) {
+	runner, err := newBSCRunner(vector)
+	if err != nil {
+		return nil, err
+	}
+	recipient := common.Address(corpus.DefaultRecipient)
+	tx := types.NewTx(&types.LegacyTx{To: &recipient, Gas: vector.Gas, Data: vector.Input})
+	return &bscTracedRunner{bscRunner: runner, tx: tx, newHooks: level.newHooks}, nil
+}
+
+// run executes the vector once with a new tracer, if any, and reverts all
+// state changes afterwards. The result of the tracer is not retrieved.
+func (r *bscTracedRunner) run() (corpus.Outcome, error) {
+	if r.newHooks == nil {
+		return r.bscRunner.run(), nil
+	}
+	hooks, err := r.newHooks()
+	if err != nil {
+		return corpus.Outcome{}, err
+	}
+	r.evm.Config.Tracer = hooks
+	caller, recipient := common.Address(corpus.DefaultCaller), common.Address(corpus.DefaultRecipient)
+	if hooks.OnTxStart != nil {
+		hooks.OnTxStart(r.evm.GetVMContext(), r.tx, caller)
+	}
+	if hooks.OnEnter != nil {
+		hooks.OnEnter(0, byte(vm.CALL), caller, recipient, r.input, r.gas, big.NewInt(0))
+	}
+	output, err := r.exec()
+	outcome := bscOutcome(r.gas, r.contract.Gas, output, err)
+	if hooks.OnExit != nil {
+		hooks.OnExit(0, outcome.Output, outcome.GasUsed, err, outcome.Status == corpus.Revert)
+	}
+	if hooks.OnTxEnd != nil {
+		hooks.OnTxEnd(&types.Receipt{GasUsed: outcome.GasUsed}, nil)
+	}
+	return outcome, nil
+}
+
+// TestTracers checks that no tracing level changes the outcome of a vector.
+func TestTracers(t *testing.T) {
+	for _, contract := range loadContracts(t) {
+		for _, vector := range contract.Vectors() {
+			t.Run(vector.Name, func(t *testing.T) {
+				for _, level := range bscTracerLevels {
+					runner, err := newBSCTracedRunner(vector, level)
+					if err != nil {
+						t.Fatalf("Failed to set up vector: %v", err)
+					}
+					outcome, err := runner.run()
+					if err != nil {
+						t.Fatalf("Execution with tracer %s failed: %v", level.name, err)
+					}
+					outcome.Logs = bscLogs(runner.logs)
+					if err := vector.Expect.Check(outcome); err != nil {
+						t.Errorf("tracer %s: %v", level.name, err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkTracers times the contract corpus at every tracing level, named
+// <vector>/<level>, after a warm-up run analysing the code. Levels after
+// none report their slowdown, the time per execution relative to that of
+// none for the same vector.
+func BenchmarkTracers(b *testing.B) {
+	for _, contract := range loadContracts(b) {
+		for _, vector := range contract.Vectors() {
+			b.Run(vector.Name, func(b *testing.B) {
+				base := 0.0
+				for _, level := range bscTracerLevels {
+					b.Run(level.name, func(b *testing.B) {
+						runner, err := newBSCTracedRunner(vector, level)
+						if err != nil {
+							b.Fatalf("Failed to set up vector: %v", err)
+						}
+						if _, err := runner.run(); err != nil {
+							b.Fatalf("Execution failed: %v", err)
+						}
+						b.ResetTimer()
+						for i := 0; i < b.N; i++ {
+							if _, err := runner.run(); err != nil {
+								b.Fatalf("Execution failed: %v", err)
+							}
+						}
+						ns := float64(b.Elapsed().Nanoseconds()) / float64(b.N)
+						if level.newHooks == nil {
+							base = ns
+						} else if base > 0 {
+							b.ReportMetric(ns/base, "slowdown")
+						}
+					})
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Worker process for interleaved benchmarks with evmbench. Serves the
+// evmbench worker protocol on stdin and stdout, executing vectors shipped by
+// the coordinator on the BSC interpreter. Started by evmbench interleave as
+// <test binary> -test.run '^TestWorker$' -worker -revision <revision>.
+package main
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/worker"
+)
+
+var workerFlag = flag.Bool("worker", false, "serve the evmbench worker protocol on stdin and stdout")
+
+func TestWorker(t *testing.T) {
+	if !*workerFlag {
+		t.Skip("use -worker to serve the evmbench worker protocol")
+	}
+
+	// Keep stray output off the protocol stream.
+	protocol := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = protocol }()
+
+	err := worker.Serve(os.Stdin, protocol, "bsc", corpusRevision(), func(vector corpus.Vector) (worker.Runner, corpus.Outcome, error) {
+		runner, err := newBSCRunner(vector)
+		if err != nil {
+			return nil, corpus.Outcome{}, err
+		}
+		outcome := runner.runWithLogs()
+		run := func() error {
+			runner.run()
+			return nil
+		}
+		return run, outcome, nil
+	}, bscConformanceStep)
+	if err != nil {
+		t.Fatalf("Worker failed: %v", err)
+	}
+}
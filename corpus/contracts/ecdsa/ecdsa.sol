@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: BSL-1.1
+pragma solidity 0.8.30;
+
+/// ECDSA wrapper around the ecrecover precompile.
+contract ECDSAVerifier {
+    function recover(bytes32 hash, uint8 v, bytes32 r, bytes32 s) public pure returns (address) {
+        return ecrecover(hash, v, r, s);
+    }
+
+    function verify(bytes32 hash, uint8 v, bytes32 r, bytes32 s, address signer) external pure returns (bool) {
+        return signer != address(0) && recover(hash, v, r, s) == signer;
+    }
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: BSL-1.1
+pragma solidity 0.8.30;
+
+/// ERC20 token following OpenZeppelin's ERC20 without metadata and minting.
+/// The storage layout is that of the declarations:
+///   slot 0: mapping(address => uint256) balances
+///   slot 1: mapping(address => mapping(address => uint256)) allowances
+///   slot 2: uint256 totalSupply
+contract ERC20 {
+    mapping(address => uint256) private _balances;
+    mapping(address => mapping(address => uint256)) private _allowances;
+    uint256 private _totalSupply;
+
+    event Transfer(address indexed from, address indexed to, uint256 value);
+    event Approval(address indexed owner, address indexed spender, uint256 value);
+
+    error ERC20InsufficientBalance(address sender, uint256 balance, uint256 needed);
+    error ERC20InsufficientAllowance(address spender, uint256 allowance, uint256 needed);
+    error ERC20InvalidReceiver(address receiver);
+    error ERC20InvalidSpender(address spender);
+
+    function totalSupply() external view returns (uint256) {
+        return _totalSupply;
+    }
+
+    function balanceOf(address account) external view returns (uint256) {
+        return _balances[account];
+    }
+
+    function transfer(address to, uint256 amount) external returns (bool) {
+        _transfer(msg.sender, to, amount);
+        return true;
+    }
+
+    function allowance(address owner, address spender) external view returns (uint256) {
+        return _allowances[owner][spender];
+    }
+
+    function approve(address spender, uint256 amount) external returns (bool) {
+        if (spender == address(0)) {
+            revert ERC20InvalidSpender(address(0));
+        }
+        _allowances[msg.sender][spender] = amount;
+        emit Approval(msg.sender, spender, amount);
+        return true;
+    }
+
+    function transferFrom(address from, address to, uint256 amount) external returns (bool) {
+        uint256 current = _allowances[from][msg.sender];
+        if (current != type(uint256).max) {
+            if (current < amount) {
+                revert ERC20InsufficientAllowance(msg.sender, current, amount);
+            }
+            _allowances[from][msg.sender] = current - amount;
+        }
+        _transfer(from, to, amount);
+        return true;
+    }
+
+    function _transfer(address from, address to, uint256 amount) private {
+        if (to == address(0)) {
+            revert ERC20InvalidReceiver(address(0));
+        }
+        uint256 balance = _balances[from];
+        if (balance < amount) {
+            revert ERC20InsufficientBalance(from, balance, amount);
+        }
+        _balances[from] = balance - amount;
+        _balances[to] += amount;
+        emit Transfer(from, to, amount);
+    }
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: BSL-1.1
+pragma solidity 0.8.30;
+
+/// ERC721 core following OpenZeppelin's ERC721 without approvals: only the
+/// owner may transfer a token. The storage layout is that of the
+/// declarations:
+///   slot 0: mapping(uint256 => address) owners
+///   slot 1: mapping(address => uint256) balances
+contract ERC721 {
+    mapping(uint256 => address) private _owners;
+    mapping(address => uint256) private _balances;
+
+    event Transfer(address indexed from, address indexed to, uint256 indexed tokenId);
+
+    error ERC721NonexistentToken(uint256 tokenId);
+    error ERC721IncorrectOwner(address sender, uint256 tokenId, address owner);
+    error ERC721InsufficientApproval(address operator, uint256 tokenId);
+    error ERC721InvalidReceiver(address receiver);
+
+    function ownerOf(uint256 tokenId) external view returns (address) {
+        return _requireOwned(tokenId);
+    }
+
+    function balanceOf(address owner) external view returns (uint256) {
+        return _balances[owner];
+    }
+
+    function transferFrom(address from, address to, uint256 tokenId) external {
+        if (to == address(0)) {
+            revert ERC721InvalidReceiver(address(0));
+        }
+        address owner = _requireOwned(tokenId);
+        if (owner != from) {
+            revert ERC721IncorrectOwner(from, tokenId, owner);
+        }
+        if (msg.sender != owner) {
+            revert ERC721InsufficientApproval(msg.sender, tokenId);
+        }
+        // A balance counts tokens, which are far fewer than 2^256.
+        unchecked {
+            _balances[from] -= 1;
+            _balances[to] += 1;
+        }
+        _owners[tokenId] = to;
+        emit Transfer(from, to, tokenId);
+    }
+
+    function _requireOwned(uint256 tokenId) private view returns (address owner) {
+        owner = _owners[tokenId];
+        if (owner == address(0)) {
+            revert ERC721NonexistentToken(tokenId);
+        }
+    }
+}
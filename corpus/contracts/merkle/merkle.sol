@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: BSL-1.1
+pragma solidity 0.8.30;
+
+/// Merkle proof verifier following OpenZeppelin's MerkleProof.verify, which
+/// hashes sorted pairs.
+contract MerkleVerifier {
+    function verify(bytes32[] calldata proof, bytes32 root, bytes32 leaf) external pure returns (bool) {
+        bytes32 computed = leaf;
+        for (uint256 i = 0; i < proof.length; i++) {
+            computed = _hashPair(computed, proof[i]);
+        }
+        return computed == root;
+    }
+
+    function _hashPair(bytes32 a, bytes32 b) private pure returns (bytes32 value) {
+        if (b < a) {
+            (a, b) = (b, a);
+        }
+        assembly ("memory-safe") {
+            mstore(0x00, a)
+            mstore(0x20, b)
+            value := keccak256(0x00, 0x40)
+        }
+    }
+}
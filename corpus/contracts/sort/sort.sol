@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: BSL-1.1
+pragma solidity 0.8.30;
+
+/// In-memory insertion sort of a uint256[] returning the sorted array.
+contract Sort {
+    function sort(uint256[] memory values) external pure returns (uint256[] memory) {
+        for (uint256 i = 1; i < values.length; i++) {
+            uint256 value = values[i];
+            uint256 j = i;
+            while (j > 0 && values[j - 1] > value) {
+                values[j] = values[j - 1];
+                j--;
+            }
+            values[j] = value;
+        }
+        return values;
+    }
+}
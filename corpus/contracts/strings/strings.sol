@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: BSL-1.1
+pragma solidity 0.8.30;
+
+/// String manipulation: ASCII upper-casing and concatenation.
+contract Strings {
+    function toUpper(string memory s) external pure returns (string memory) {
+        bytes memory b = bytes(s);
+        for (uint256 i = 0; i < b.length; i++) {
+            bytes1 c = b[i];
+            if (c >= "a" && c <= "z") {
+                b[i] = bytes1(uint8(c) - 32);
+            }
+        }
+        return s;
+    }
+
+    function concat(string memory a, string memory b) external pure returns (string memory) {
+        return string.concat(a, b);
+    }
+}
@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: BSL-1.1
+pragma solidity 0.8.30;
+
+/// Pair math of Uniswap v2 (UniswapV2Library.getAmountOut and getAmountIn)
+/// with a 0.3% fee. quotePath chains getAmountOut over a list of
+/// (reserveIn, reserveOut) pairs and returns the final amount.
+contract UniswapV2Math {
+    function getAmountOut(uint256 amountIn, uint256 reserveIn, uint256 reserveOut) public pure returns (uint256 amountOut) {
+        require(amountIn > 0, "UniswapV2Library: INSUFFICIENT_INPUT_AMOUNT");
+        require(reserveIn > 0 && reserveOut > 0, "UniswapV2Library: INSUFFICIENT_LIQUIDITY");
+        uint256 amountInWithFee = amountIn * 997;
+        uint256 numerator = amountInWithFee * reserveOut;
+        uint256 denominator = reserveIn * 1000 + amountInWithFee;
+        amountOut = numerator / denominator;
+    }
+
+    function getAmountIn(uint256 amountOut, uint256 reserveIn, uint256 reserveOut) external pure returns (uint256 amountIn) {
+        require(amountOut > 0, "UniswapV2Library: INSUFFICIENT_OUTPUT_AMOUNT");
+        require(reserveIn > 0 && reserveOut > amountOut, "UniswapV2Library: INSUFFICIENT_LIQUIDITY");
+        uint256 numerator = reserveIn * amountOut * 1000;
+        uint256 denominator = (reserveOut - amountOut) * 997;
+        amountIn = numerator / denominator + 1;
+    }
+
+    function quotePath(uint256 amountIn, uint256[] calldata reserves) external pure returns (uint256 amountOut) {
+        require(reserves.length % 2 == 0, "UniswapV2Library: INVALID_PATH");
+        amountOut = amountIn;
+        for (uint256 i = 0; i < reserves.length; i += 2) {
+            amountOut = getAmountOut(amountOut, reserves[i], reserves[i + 1]);
+        }
+    }
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Package allocs attributes the heap allocations of repeated executions to
+// engine call sites. It samples every allocation through the runtime memory
+// profiler and assigns each allocation stack to the first category whose
+// rule matches one of its frames, walking from the allocation site outwards.
+// Allocations not covered by any rule are reported under the allocating
+// function, which makes gaps in the rule set visible.
+package allocs
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Rule assigns allocations to a category if a frame's function name, with
+// the package path stripped (e.g. "lfvm.(*Memory).expandMemory"), starts
+// with one of the given prefixes. Rules with SiteOnly set only match the
+// allocating function itself, not its callers.
+type Rule struct {
+	Category string
+	Prefixes []string
+	SiteOnly bool
+}
+
+// HarnessRules returns the rules shared by all engines, covering
+// allocations made directly by the benchmark harness in package pkg rather
+// than by the engine. They are meant to be appended after the engine rules.
+func HarnessRules(pkg string) []Rule {
+	return []Rule{
+		{Category: "harness: hex decoding", Prefixes: []string{"hex."}, SiteOnly: true},
+		{Category: "harness: test code", Prefixes: []string{pkg + "."}, SiteOnly: true},
+	}
+}
+
+// CallerPackage returns the package name of the calling function as used
+// in rule prefixes. Test binaries name their main package after the import
+// path, so harness rules are best derived from this.
+func CallerPackage() string {
+	pc, _, _, _ := runtime.Caller(1)
+	name := shortName(runtime.FuncForPC(pc).Name())
+	if i := strings.Index(name, "."); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// Entry is the allocation volume of one category, averaged per run.
+type Entry struct {
+	Category string
+	Bytes    float64
+	Objects  float64
+}
+
+// Report is the allocation breakdown of a single vector on one engine.
+type Report struct {
+	Engine  string
+	Vector  string
+	Runs    int
+	Entries []Entry // sorted by decreasing bytes
+}
+
+// Total returns the bytes and objects allocated per run.
+func (r Report) Total() (bytes, objects float64) {
+	for _, e := range r.Entries {
+		bytes += e.Bytes
+		objects += e.Objects
+	}
+	return bytes, objects
+}
+
+// Measure executes run the given number of times with every allocation
+// being sampled and attributes the allocations to categories. A warm-up
+// run precedes the measurement so that one-time setup like code caching is
+// excluded. Measure must not be called concurrently.
+func Measure(engine, vector string, runs int, rules []Rule, run func()) Report {
+	run()
+
+	previousRate := runtime.MemProfileRate
+	runtime.MemProfileRate = 1
+	defer func() { runtime.MemProfileRate = previousRate }()
+
+	before := snapshot()
+	for i := 0; i < runs; i++ {
+		run()
+	}
+	after := snapshot()
+
+	totals := map[string]*Entry{}
+	for stack, record := range after {
+		bytes := record.AllocBytes - before[stack].AllocBytes
+		objects := record.AllocObjects - before[stack].AllocObjects
+		if objects == 0 {
+			continue
+		}
+		category := categorize(record.Stack(), rules)
+		if category == "" {
+			continue
+		}
+		entry, found := totals[category]
+		if !found {
+			entry = &Entry{Category: category}
+			totals[category] = entry
+		}
+		entry.Bytes += float64(bytes) / float64(runs)
+		entry.Objects += float64(objects) / float64(runs)
+	}
+
+	report := Report{Engine: engine, Vector: vector, Runs: runs}
+	for _, entry := range totals {
+		// Drop noise from background activity that does not recur per run.
+		if entry.Objects < 0.5 {
+			continue
+		}
+		report.Entries = append(report.Entries, *entry)
+	}
+	sort.Slice(report.Entries, func(i, j int) bool {
+		a, b := report.Entries[i], report.Entries[j]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return a.Category < b.Category
+	})
+	return report
+}
+
+// snapshot returns the cumulative allocation profile keyed by stack. The
+// profile is only published at the end of a GC cycle, and may lag behind
+// by up to two cycles.
+func snapshot() map[[32]uintptr]runtime.MemProfileRecord {
+	runtime.GC()
+	runtime.GC()
+	var records []runtime.MemProfileRecord
+	n, _ := runtime.MemProfile(nil, true)
+	for {
+		records = make([]runtime.MemProfileRecord, n+64)
+		var ok bool
+		if n, ok = runtime.MemProfile(records, true); ok {
+			records = records[:n]
+			break
+		}
+	}
+	res := make(map[[32]uintptr]runtime.MemProfileRecord, len(records))
+	for _, record := range records {
+		res[record.Stack0] = record
+	}
+	return res
+}
+
+// categorize returns the category of the first frame matched by a rule, or
+// "other: <function>" naming the allocating function. Allocations of the
+// profiler itself yield an empty category.
+func categorize(stack []uintptr, rules []Rule) string {
+	frames := runtime.CallersFrames(stack)
+	allocator := ""
+	for {
+		frame, more := frames.Next()
+		name := shortName(frame.Function)
+		if strings.HasPrefix(name, "allocs.snapshot") {
+			return ""
+		}
+		internal := strings.HasPrefix(frame.Function, "runtime.") || strings.HasPrefix(frame.Function, "internal/")
+		if allocator == "" && !internal {
+			allocator = name
+		}
+		for _, rule := range rules {
+			if rule.SiteOnly && name != allocator {
+				continue
+			}
+			for _, prefix := range rule.Prefixes {
+				if strings.HasPrefix(name, prefix) {
+					return rule.Category
+				}
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	if allocator == "" {
+		allocator = "runtime"
+	}
+	return "other: " + allocator
+}
+
+// shortName strips the package path from a fully qualified function name.
+func shortName(function string) string {
+	if i := strings.LastIndex(function, "/"); i >= 0 {
+		return function[i+1:]
+	}
+	return function
+}
+
+// WriteMarkdown prints each report as a Markdown table.
+func WriteMarkdown(w io.Writer, reports ...Report) error {
+	for _, r := range reports {
+		bytes, objects := r.Total()
+		fmt.Fprintf(w, "### %s: %s\n\n", r.Engine, r.Vector)
+		fmt.Fprintf(w, "%.0f B/op, %.1f allocs/op over %d runs\n\n", bytes, objects, r.Runs)
+		fmt.Fprintf(w, "| Category | B/op | allocs/op | Share |\n")
+		fmt.Fprintf(w, "|----------|-----:|----------:|------:|\n")
+		for _, e := range r.Entries {
+			share := 0.0
+			if bytes > 0 {
+				share = 100 * e.Bytes / bytes
+			}
+			fmt.Fprintf(w, "| %s | %.0f | %.1f | %.1f%% |\n", e.Category, e.Bytes, e.Objects, share)
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
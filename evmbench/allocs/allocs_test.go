@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package allocs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var sink []byte
+
+//go:noinline
+func allocateBuffer() []byte {
+	return make([]byte, 1024)
+}
+
+func TestMeasure_AttributesAllocationsToRules(t *testing.T) {
+	rules := []Rule{{Category: "buffer", Prefixes: []string{"allocs.allocateBuffer"}}}
+	report := Measure("engine", "vector", 100, rules, func() { sink = allocateBuffer() })
+
+	if len(report.Entries) != 1 {
+		t.Fatalf("unexpected entries: %+v", report.Entries)
+	}
+	entry := report.Entries[0]
+	if entry.Category != "buffer" || entry.Bytes != 1024 || entry.Objects != 1 {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestMeasure_ReportsUnmatchedAllocationsByAllocatingFunction(t *testing.T) {
+	report := Measure("engine", "vector", 100, nil, func() { sink = allocateBuffer() })
+
+	if len(report.Entries) != 1 {
+		t.Fatalf("unexpected entries: %+v", report.Entries)
+	}
+	if want, got := "other: allocs.allocateBuffer", report.Entries[0].Category; want != got {
+		t.Errorf("unexpected category, wanted %q, got %q", want, got)
+	}
+}
+
+func TestWriteMarkdown_ListsCategories(t *testing.T) {
+	report := Report{Engine: "lfvm", Vector: "erc20/transfer", Runs: 10, Entries: []Entry{
+		{Category: "stack", Bytes: 96, Objects: 1},
+		{Category: "memory growth", Bytes: 32, Objects: 1},
+	}}
+	var out bytes.Buffer
+	if err := WriteMarkdown(&out, report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"### lfvm: erc20/transfer", "128 B/op", "| stack | 96 | 1.0 | 75.0% |"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestCallerPackage_ReturnsPackageOfCaller(t *testing.T) {
+	if want, got := "allocs", CallerPackage(); want != got {
+		t.Errorf("unexpected package, wanted %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Package asm is a small EVM assembler used to build the benchmark corpus.
+//
+// The source format is a whitespace separated list of mnemonics. Comments
+// start with ';' or '//' and run to the end of the line. A token ending in
+// ':' defines a label at the current position; labels do not emit a
+// JUMPDEST, which has to be written explicitly. Push instructions take one
+// operand:
+//
+//	PUSH 0x2a     ; smallest PUSHn that fits the value
+//	PUSH4 0x2a    ; explicit width, zero padded
+//	PUSH @loop    ; PUSH2 with the offset of label "loop"
+//
+// Operands are hex (0x prefixed) or decimal and may be up to 32 bytes wide.
+package asm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Assemble translates the given source into EVM bytecode.
+func Assemble(src string) ([]byte, error) {
+	items, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	// First pass: resolve label positions. Label references always use
+	// PUSH2, so instruction sizes do not depend on label values.
+	labels := map[string]int{}
+	pos := 0
+	for _, it := range items {
+		if it.label != "" {
+			if _, found := labels[it.label]; found {
+				return nil, fmt.Errorf("line %d: duplicate label %q", it.line, it.label)
+			}
+			labels[it.label] = pos
+			continue
+		}
+		pos += 1 + it.op.PushSize()
+	}
+	if pos > 0xffff && len(labels) > 0 {
+		return nil, fmt.Errorf("code size %d exceeds the range of label references", pos)
+	}
+
+	// Second pass: emit code.
+	code := make([]byte, 0, pos)
+	for _, it := range items {
+		if it.label != "" {
+			continue
+		}
+		code = append(code, byte(it.op))
+		if !it.op.IsPush() {
+			continue
+		}
+		imm := it.imm
+		if it.ref != "" {
+			target, found := labels[it.ref]
+			if !found {
+				return nil, fmt.Errorf("line %d: undefined label %q", it.line, it.ref)
+			}
+			imm = []byte{byte(target >> 8), byte(target)}
+		}
+		code = append(code, imm...)
+	}
+	return code, nil
+}
+
+// MustAssemble is like Assemble but panics on error. It is intended for
+// package level test vectors.
+func MustAssemble(src string) []byte {
+	code, err := Assemble(src)
+	if err != nil {
+		panic(err)
+	}
+	return code
+}
+
+type item struct {
+	line  int
+	label string // set for label definitions
+	op    Opcode
+	imm   []byte // immediate of push instructions, exactly op.PushSize() bytes
+	ref   string // label referenced by a push instruction
+}
+
+func parse(src string) ([]item, error) {
+	var items []item
+	for n, line := range strings.Split(src, "\n") {
+		lineNo := n + 1
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = line[:i]
+		}
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		tokens := strings.Fields(line)
+		for i := 0; i < len(tokens); i++ {
+			tok := tokens[i]
+			if strings.HasSuffix(tok, ":") {
+				items = append(items, item{line: lineNo, label: strings.TrimSuffix(tok, ":")})
+				continue
+			}
+
+			mnemonic := strings.ToUpper(tok)
+			if mnemonic == "PUSH" || (strings.HasPrefix(mnemonic, "PUSH") && mnemonic != "PUSH0") {
+				if i+1 >= len(tokens) {
+					return nil, fmt.Errorf("line %d: %s requires an operand", lineNo, tok)
+				}
+				i++
+				it, err := parsePush(mnemonic, tokens[i])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				it.line = lineNo
+				items = append(items, it)
+				continue
+			}
+
+			op, found := opcodesByName[mnemonic]
+			if !found {
+				return nil, fmt.Errorf("line %d: unknown instruction %q", lineNo, tok)
+			}
+			items = append(items, item{line: lineNo, op: op})
+		}
+	}
+	return items, nil
+}
+
+func parsePush(mnemonic, operand string) (item, error) {
+	if strings.HasPrefix(operand, "@") {
+		if mnemonic != "PUSH" && mnemonic != "PUSH2" {
+			return item{}, fmt.Errorf("label reference %s requires PUSH or PUSH2", operand)
+		}
+		return item{op: PUSH2, ref: operand[1:]}, nil
+	}
+
+	value, err := parseValue(operand)
+	if err != nil {
+		return item{}, err
+	}
+
+	size := len(value)
+	if size == 0 {
+		size = 1
+	}
+	if mnemonic != "PUSH" {
+		op, found := opcodesByName[mnemonic]
+		if !found || !op.IsPush() {
+			return item{}, fmt.Errorf("unknown instruction %q", mnemonic)
+		}
+		if len(value) > op.PushSize() {
+			return item{}, fmt.Errorf("operand %s does not fit into %s", operand, mnemonic)
+		}
+		size = op.PushSize()
+	}
+
+	imm := make([]byte, size)
+	copy(imm[size-len(value):], value)
+	return item{op: PUSH1 + Opcode(size-1), imm: imm}, nil
+}
+
+// parseValue returns the minimal big-endian encoding of the operand.
+func parseValue(operand string) ([]byte, error) {
+	var value []byte
+	if s, found := strings.CutPrefix(strings.ToLower(operand), "0x"); found {
+		if len(s)%2 == 1 {
+			s = "0" + s
+		}
+		raw, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex operand %q", operand)
+		}
+		value = raw
+	} else {
+		v, ok := new(big.Int).SetString(operand, 10)
+		if !ok || v.Sign() < 0 {
+			return nil, fmt.Errorf("invalid operand %q", operand)
+		}
+		value = v.Bytes()
+	}
+	for len(value) > 0 && value[0] == 0 {
+		value = value[1:]
+	}
+	if len(value) > 32 {
+		return nil, fmt.Errorf("operand %s exceeds 32 bytes", operand)
+	}
+	return value, nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package asm
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestAssemble(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"simple arithmetic": {"PUSH 1 PUSH 2 ADD POP STOP", "60016002015000"},
+		"auto width":        {"PUSH 0x1234 PUSH 0", "6112346000"},
+		"explicit width":    {"PUSH4 0x2a", "630000002a"},
+		"push0":             {"PUSH0 POP", "5f50"},
+		"comments":          {"PUSH 1 ; one\nPUSH 2 // two\nADD", "6001600201"},
+		"forward label":     {"PUSH @end JUMP end: JUMPDEST", "610004565b"},
+		"backward label":    {"loop: JUMPDEST PUSH @loop JUMP", "5b61000056"},
+		"lower case":        {"push 1 dup1 sha3", "60018020"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := Assemble(test.src)
+			if err != nil {
+				t.Fatalf("failed to assemble: %v", err)
+			}
+			if got := hex.EncodeToString(code); got != test.want {
+				t.Errorf("unexpected code, wanted %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestAssemble_ReportsErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown instruction": "PUSH 1 FOO",
+		"missing operand":     "PUSH",
+		"undefined label":     "PUSH @nowhere JUMP",
+		"duplicate label":     "a: a: STOP",
+		"operand too wide":    "PUSH1 0x1234",
+		"invalid operand":     "PUSH 0xzz",
+	}
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Assemble(src); err == nil {
+				t.Errorf("expected an error for %q", src)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package asm
+
+import "fmt"
+
+// Opcode is a single EVM instruction byte.
+type Opcode byte
+
+// The opcodes referenced by generators in this module. The full mnemonic
+// table used by the assembler is built from opcodeNames below.
+const (
+	STOP           Opcode = 0x00
+	ADD            Opcode = 0x01
+	MUL            Opcode = 0x02
+	SUB            Opcode = 0x03
+	DIV            Opcode = 0x04
+	LT             Opcode = 0x10
+	GT             Opcode = 0x11
+	EQ             Opcode = 0x14
+	ISZERO         Opcode = 0x15
+	AND            Opcode = 0x16
+	KECCAK256      Opcode = 0x20
+	CALLDATALOAD   Opcode = 0x35
+	CALLDATASIZE   Opcode = 0x36
+	CALLDATACOPY   Opcode = 0x37
+	CODECOPY       Opcode = 0x39
+	RETURNDATACOPY Opcode = 0x3e
+	POP            Opcode = 0x50
+	MLOAD          Opcode = 0x51
+	MSTORE         Opcode = 0x52
+	MSTORE8        Opcode = 0x53
+	SLOAD          Opcode = 0x54
+	SSTORE         Opcode = 0x55
+	JUMP           Opcode = 0x56
+	JUMPI          Opcode = 0x57
+	GAS            Opcode = 0x5a
+	JUMPDEST       Opcode = 0x5b
+	TLOAD          Opcode = 0x5c
+	TSTORE         Opcode = 0x5d
+	MCOPY          Opcode = 0x5e
+	PUSH0          Opcode = 0x5f
+	PUSH1          Opcode = 0x60
+	PUSH2          Opcode = 0x61
+	PUSH32         Opcode = 0x7f
+	DUP1           Opcode = 0x80
+	SWAP1          Opcode = 0x90
+	LOG0           Opcode = 0xa0
+	CALL           Opcode = 0xf1
+	RETURN         Opcode = 0xf3
+	STATICCALL     Opcode = 0xfa
+	REVERT         Opcode = 0xfd
+	INVALID        Opcode = 0xfe
+)
+
+var opcodeNames = map[Opcode]string{
+	0x00: "STOP", 0x01: "ADD", 0x02: "MUL", 0x03: "SUB", 0x04: "DIV",
+	0x05: "SDIV", 0x06: "MOD", 0x07: "SMOD", 0x08: "ADDMOD", 0x09: "MULMOD",
+	0x0a: "EXP", 0x0b: "SIGNEXTEND",
+	0x10: "LT", 0x11: "GT", 0x12: "SLT", 0x13: "SGT", 0x14: "EQ",
+	0x15: "ISZERO", 0x16: "AND", 0x17: "OR", 0x18: "XOR", 0x19: "NOT",
+	0x1a: "BYTE", 0x1b: "SHL", 0x1c: "SHR", 0x1d: "SAR",
+	0x20: "KECCAK256",
+	0x30: "ADDRESS", 0x31: "BALANCE", 0x32: "ORIGIN", 0x33: "CALLER",
+	0x34: "CALLVALUE", 0x35: "CALLDATALOAD", 0x36: "CALLDATASIZE",
+	0x37: "CALLDATACOPY", 0x38: "CODESIZE", 0x39: "CODECOPY", 0x3a: "GASPRICE",
+	0x3b: "EXTCODESIZE", 0x3c: "EXTCODECOPY", 0x3d: "RETURNDATASIZE",
+	0x3e: "RETURNDATACOPY", 0x3f: "EXTCODEHASH",
+	0x40: "BLOCKHASH", 0x41: "COINBASE", 0x42: "TIMESTAMP", 0x43: "NUMBER",
+	0x44: "PREVRANDAO", 0x45: "GASLIMIT", 0x46: "CHAINID", 0x47: "SELFBALANCE",
+	0x48: "BASEFEE", 0x49: "BLOBHASH", 0x4a: "BLOBBASEFEE",
+	0x50: "POP", 0x51: "MLOAD", 0x52: "MSTORE", 0x53: "MSTORE8", 0x54: "SLOAD",
+	0x55: "SSTORE", 0x56: "JUMP", 0x57: "JUMPI", 0x58: "PC", 0x59: "MSIZE",
+	0x5a: "GAS", 0x5b: "JUMPDEST", 0x5c: "TLOAD", 0x5d: "TSTORE", 0x5e: "MCOPY",
+	0x5f: "PUSH0",
+	0xa0: "LOG0", 0xa1: "LOG1", 0xa2: "LOG2", 0xa3: "LOG3", 0xa4: "LOG4",
+	0xf0: "CREATE", 0xf1: "CALL", 0xf2: "CALLCODE", 0xf3: "RETURN",
+	0xf4: "DELEGATECALL", 0xf5: "CREATE2", 0xfa: "STATICCALL", 0xfd: "REVERT",
+	0xfe: "INVALID", 0xff: "SELFDESTRUCT",
+}
+
+var opcodesByName = map[string]Opcode{}
+
+func init() {
+	for i := 1; i <= 32; i++ {
+		opcodeNames[PUSH1+Opcode(i-1)] = fmt.Sprintf("PUSH%d", i)
+	}
+	for i := 1; i <= 16; i++ {
+		opcodeNames[DUP1+Opcode(i-1)] = fmt.Sprintf("DUP%d", i)
+		opcodeNames[SWAP1+Opcode(i-1)] = fmt.Sprintf("SWAP%d", i)
+	}
+	for op, name := range opcodeNames {
+		opcodesByName[name] = op
+	}
+	// Common aliases used by older tooling.
+	opcodesByName["SHA3"] = KECCAK256
+	opcodesByName["DIFFICULTY"] = 0x44
+}
+
+// String returns the mnemonic of the opcode, or its hex value if undefined.
+func (op Opcode) String() string {
+	if name, ok := opcodeNames[op]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%02x", byte(op))
+}
+
+// IsPush reports whether op is one of PUSH1..PUSH32.
+func (op Opcode) IsPush() bool {
+	return op >= PUSH1 && op <= PUSH32
+}
+
+// PushSize returns the number of immediate bytes following op.
+func (op Opcode) PushSize() int {
+	if !op.IsPush() {
+		return 0
+	}
+	return int(op-PUSH1) + 1
+}
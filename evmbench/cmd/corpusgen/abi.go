@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"golang.org/x/crypto/sha3"
+)
+
+// function is a contract function given by its canonical signature, e.g.
+// "transfer(address,uint256)".
+type function string
+
+func (f function) Signature() string {
+	return string(f)
+}
+
+func (f function) Selector() []byte {
+	return keccak([]byte(f))[:4]
+}
+
+// abiSignatures returns the canonical signatures of the functions of a
+// Solidity ABI JSON document.
+func abiSignatures(abi []byte) (map[string]bool, error) {
+	var entries []struct {
+		Type   string `json:"type"`
+		Name   string `json:"name"`
+		Inputs []struct {
+			Type string `json:"type"`
+		} `json:"inputs"`
+	}
+	if err := json.Unmarshal(abi, &entries); err != nil {
+		return nil, err
+	}
+	res := map[string]bool{}
+	for _, entry := range entries {
+		if entry.Type != "function" {
+			continue
+		}
+		types := make([]string, len(entry.Inputs))
+		for i, in := range entry.Inputs {
+			types[i] = in.Type
+		}
+		res[entry.Name+"("+strings.Join(types, ",")+")"] = true
+	}
+	return res, nil
+}
+
+// encode ABI encodes the given values as a tuple. Supported values are
+// uint64 and *big.Int (uint256), bool, corpus.Address, corpus.Hash
+// (bytes32), string, []*big.Int (uint256[]) and []corpus.Hash (bytes32[]).
+func encode(values ...any) []byte {
+	head := make([]byte, 0, 32*len(values))
+	var tail []byte
+	for _, value := range values {
+		switch v := value.(type) {
+		case []*big.Int:
+			head = append(head, word(uint64(32*len(values)+len(tail)))...)
+			tail = append(tail, word(uint64(len(v)))...)
+			for _, x := range v {
+				tail = append(tail, word(x)...)
+			}
+		case []corpus.Hash:
+			head = append(head, word(uint64(32*len(values)+len(tail)))...)
+			tail = append(tail, word(uint64(len(v)))...)
+			for _, x := range v {
+				tail = append(tail, x[:]...)
+			}
+		case string:
+			head = append(head, word(uint64(32*len(values)+len(tail)))...)
+			tail = append(tail, word(uint64(len(v)))...)
+			tail = append(tail, v...)
+			tail = append(tail, make([]byte, (32-len(v)%32)%32)...)
+		default:
+			head = append(head, word(v)...)
+		}
+	}
+	return append(head, tail...)
+}
+
+// word encodes a static value as a single 32 byte word.
+func word(value any) []byte {
+	res := make([]byte, 32)
+	switch v := value.(type) {
+	case uint64:
+		new(big.Int).SetUint64(v).FillBytes(res)
+	case int:
+		new(big.Int).SetInt64(int64(v)).FillBytes(res)
+	case *big.Int:
+		v.FillBytes(res)
+	case bool:
+		if v {
+			res[31] = 1
+		}
+	case corpus.Address:
+		copy(res[12:], v[:])
+	case corpus.Hash:
+		copy(res, v[:])
+	default:
+		panic(fmt.Sprintf("unsupported ABI value %T", value))
+	}
+	return res
+}
+
+func keccak(data ...[]byte) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		hasher.Write(d)
+	}
+	return hasher.Sum(nil)
+}
+
+func keccakHash(data ...[]byte) corpus.Hash {
+	return corpus.Hash(keccak(data...))
+}
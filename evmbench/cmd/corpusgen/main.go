@@ -0,0 +1,183 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Command corpusgen compiles the workload contracts of the benchmark corpus
+// with solc and writes their runtime and creation bytecode, ABI and
+// expected call outcomes next to the Solidity sources. Run it through go
+// generate in the corpus package with solc of solcVersion on the PATH, or
+// point -solc at it.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// solcVersion is the compiler version the committed bytecode was compiled
+// with. Other versions are rejected, as they change the bytecode.
+const solcVersion = "0.8.30+commit.73712a01"
+
+// solcSettings are the compiler settings of all contracts: the optimizer
+// with its default runs and code for Istanbul, the first revision of the
+// corpus, so that the code runs in every revision.
+var solcSettings = map[string]any{
+	"optimizer":  map[string]any{"enabled": true, "runs": 200},
+	"evmVersion": "istanbul",
+	"outputSelection": map[string]any{
+		"*": map[string]any{"*": []string{"abi", "evm.bytecode.object", "evm.deployedBytecode.object"}},
+	},
+}
+
+func main() {
+	dir := flag.String("dir", "corpus", "corpus directory to write the fixtures to")
+	solc := flag.String("solc", "solc", "solc or solcjs of version "+solcVersion)
+	flag.Parse()
+
+	if err := checkSolc(*solc); err != nil {
+		log.Fatal(err)
+	}
+	for _, spec := range contracts() {
+		if err := generate(*dir, *solc, spec); err != nil {
+			log.Fatalf("%s: %v", spec.Name, err)
+		}
+	}
+}
+
+// contractSpec describes a workload contract: the Solidity contract of
+// <name>.sol, the functions called and the calls with their expected
+// outcomes.
+type contractSpec struct {
+	Name      string
+	Contract  string
+	Functions []function
+	Calls     []corpus.Call
+}
+
+// checkSolc fails unless the compiler reports solcVersion.
+func checkSolc(solc string) error {
+	out, err := exec.Command(solc, "--version").Output()
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %w", solc, err)
+	}
+	if !strings.Contains(string(out), solcVersion) {
+		return fmt.Errorf("%s is not solc %s:\n%s", solc, solcVersion, out)
+	}
+	return nil
+}
+
+// solcOutput is the part of solc's standard JSON output read.
+type solcOutput struct {
+	Errors []struct {
+		Severity         string `json:"severity"`
+		FormattedMessage string `json:"formattedMessage"`
+	} `json:"errors"`
+	Contracts map[string]map[string]struct {
+		ABI json.RawMessage `json:"abi"`
+		EVM struct {
+			Bytecode         struct{ Object string } `json:"bytecode"`
+			DeployedBytecode struct{ Object string } `json:"deployedBytecode"`
+		} `json:"evm"`
+	} `json:"contracts"`
+}
+
+// compile compiles the Solidity source file of the spec and returns the
+// creation and runtime bytecode and the ABI of its contract.
+func compile(solc, dir string, spec contractSpec) (creation, runtime corpus.Bytes, abi json.RawMessage, err error) {
+	file := spec.Name + ".sol"
+	src, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	input, err := json.Marshal(map[string]any{
+		"language": "Solidity",
+		"sources":  map[string]any{file: map[string]string{"content": string(src)}},
+		"settings": solcSettings,
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cmd := exec.Command(solc, "--standard-json")
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var output solcOutput
+	if err := json.Unmarshal(out, &output); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid solc output: %w", err)
+	}
+	var errs []error
+	for _, e := range output.Errors {
+		if e.Severity == "error" {
+			errs = append(errs, errors.New(e.FormattedMessage))
+		} else {
+			log.Printf("%s: %s", file, e.FormattedMessage)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, nil, errors.Join(errs...)
+	}
+	contract, found := output.Contracts[file][spec.Contract]
+	if !found {
+		return nil, nil, nil, fmt.Errorf("no contract %s in %s", spec.Contract, file)
+	}
+	if err := creation.UnmarshalText([]byte("0x" + contract.EVM.Bytecode.Object)); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := runtime.UnmarshalText([]byte("0x" + contract.EVM.DeployedBytecode.Object)); err != nil {
+		return nil, nil, nil, err
+	}
+	return creation, runtime, contract.ABI, nil
+}
+
+func generate(root, solc string, spec contractSpec) error {
+	dir := filepath.Join(root, "contracts", spec.Name)
+	creation, runtime, abi, err := compile(solc, dir, spec)
+	if err != nil {
+		return err
+	}
+
+	// Every function called must be in the ABI of the contract.
+	signatures, err := abiSignatures(abi)
+	if err != nil {
+		return err
+	}
+	for _, f := range spec.Functions {
+		if !signatures[f.Signature()] {
+			return fmt.Errorf("%s not in the ABI of %s", f.Signature(), spec.Contract)
+		}
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, abi, "", "  "); err != nil {
+		return err
+	}
+	calls, err := json.MarshalIndent(spec.Calls, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		"runtime.hex":  []byte(runtime.String() + "\n"),
+		"creation.hex": []byte(creation.String() + "\n"),
+		"abi.json":     append(indented.Bytes(), '\n'),
+		"calls.json":   append(calls, '\n'),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"encoding/hex"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+const defaultGas = 1_000_000
+
+// Accounts used as call arguments besides the default caller.
+var (
+	caller = corpus.DefaultCaller
+	bob    = corpus.Address{19: 0xb0}
+	carol  = corpus.Address{19: 0xca}
+)
+
+func contracts() []contractSpec {
+	return []contractSpec{
+		erc20Spec(),
+		erc721Spec(),
+		uniswapSpec(),
+		merkleSpec(),
+		ecdsaSpec(),
+		sortSpec(),
+		stringsSpec(),
+	}
+}
+
+// call builds a call of f with the given arguments and expected outcome.
+func call(name string, f function, gas uint64, storage map[corpus.Hash]corpus.Hash, expect corpus.Outcome, args ...any) corpus.Call {
+	return corpus.Call{
+		Name:     name,
+		Function: f.Signature(),
+		Input:    append(f.Selector(), encode(args...)...),
+		Gas:      gas,
+		Storage:  storage,
+		Expect:   expect,
+	}
+}
+
+func returns(values ...any) corpus.Outcome {
+	var output []byte
+	if len(values) > 0 {
+		output = encode(values...)
+	}
+	return corpus.Outcome{Status: corpus.Success, Output: output}
+}
+
+// reverts returns the outcome of a call reverting with the error of the
+// given signature and arguments, as a Solidity custom error or, for
+// Error(string), a require with a message.
+func reverts(signature string, args ...any) corpus.Outcome {
+	output := append(function(signature).Selector(), encode(args...)...)
+	return corpus.Outcome{Status: corpus.Revert, Output: output}
+}
+
+func u(v uint64) *big.Int {
+	return new(big.Int).SetUint64(v)
+}
+
+// mappingSlot returns the storage slot of key in a Solidity mapping
+// declared at slot.
+func mappingSlot(key any, slot corpus.Hash) corpus.Hash {
+	return keccakHash(word(key), slot[:])
+}
+
+func slotOf(slot uint64) corpus.Hash {
+	return corpus.Hash(word(slot))
+}
+
+func value(v uint64) corpus.Hash {
+	return corpus.Hash(word(v))
+}
+
+func erc20Spec() contractSpec {
+	var (
+		totalSupply  = function("totalSupply()")
+		balanceOf    = function("balanceOf(address)")
+		transfer     = function("transfer(address,uint256)")
+		allowance    = function("allowance(address,address)")
+		approve      = function("approve(address,uint256)")
+		transferFrom = function("transferFrom(address,address,uint256)")
+	)
+	const (
+		insufficientBalance   = "ERC20InsufficientBalance(address,uint256,uint256)"
+		insufficientAllowance = "ERC20InsufficientAllowance(address,uint256,uint256)"
+	)
+	balances, allowances := slotOf(0), slotOf(1)
+	storage := map[corpus.Hash]corpus.Hash{
+		mappingSlot(caller, balances):                     value(1_000_000),
+		mappingSlot(bob, balances):                        value(300),
+		mappingSlot(caller, mappingSlot(bob, allowances)): value(500),
+		slotOf(2): value(1_000_300),
+	}
+	return contractSpec{
+		Name:      "erc20",
+		Contract:  "ERC20",
+		Functions: []function{totalSupply, balanceOf, transfer, allowance, approve, transferFrom},
+		Calls: []corpus.Call{
+			call("totalSupply", totalSupply, defaultGas, storage, returns(u(1_000_300))),
+			call("balanceOf", balanceOf, defaultGas, storage, returns(u(1_000_000)), caller),
+			call("balanceOf-empty", balanceOf, defaultGas, storage, returns(u(0)), carol),
+			call("transfer", transfer, defaultGas, storage, returns(true), bob, u(100)),
+			call("transfer-insufficient-balance", transfer, defaultGas, storage, reverts(insufficientBalance, caller, u(1_000_000), u(2_000_000)), bob, u(2_000_000)),
+			call("allowance", allowance, defaultGas, storage, returns(u(500)), bob, caller),
+			call("approve", approve, defaultGas, storage, returns(true), bob, u(42)),
+			call("transferFrom", transferFrom, defaultGas, storage, returns(true), bob, carol, u(200)),
+			call("transferFrom-insufficient-allowance", transferFrom, defaultGas, storage, reverts(insufficientAllowance, caller, u(500), u(600)), bob, carol, u(600)),
+			call("transferFrom-insufficient-balance", transferFrom, defaultGas, storage, reverts(insufficientBalance, bob, u(300), u(400)), bob, carol, u(400)),
+		},
+	}
+}
+
+func erc721Spec() contractSpec {
+	var (
+		ownerOf      = function("ownerOf(uint256)")
+		balanceOf    = function("balanceOf(address)")
+		transferFrom = function("transferFrom(address,address,uint256)")
+	)
+	owners, balances := slotOf(0), slotOf(1)
+	storage := map[corpus.Hash]corpus.Hash{
+		mappingSlot(caller, balances): value(3),
+		mappingSlot(bob, balances):    value(1),
+	}
+	for id := uint64(1); id <= 3; id++ {
+		storage[mappingSlot(id, owners)] = corpus.Hash(word(caller))
+	}
+	storage[mappingSlot(uint64(7), owners)] = corpus.Hash(word(bob))
+	return contractSpec{
+		Name:      "erc721",
+		Contract:  "ERC721",
+		Functions: []function{ownerOf, balanceOf, transferFrom},
+		Calls: []corpus.Call{
+			call("ownerOf", ownerOf, defaultGas, storage, returns(caller), u(1)),
+			call("ownerOf-nonexistent", ownerOf, defaultGas, storage, reverts("ERC721NonexistentToken(uint256)", u(99)), u(99)),
+			call("balanceOf", balanceOf, defaultGas, storage, returns(u(3)), caller),
+			call("transferFrom", transferFrom, defaultGas, storage, returns(), caller, bob, u(2)),
+			call("transferFrom-not-owner", transferFrom, defaultGas, storage, reverts("ERC721IncorrectOwner(address,uint256,address)", caller, u(7), bob), caller, bob, u(7)),
+			call("transferFrom-to-zero", transferFrom, defaultGas, storage, reverts("ERC721InvalidReceiver(address)", corpus.Address{}), caller, corpus.Address{}, u(2)),
+		},
+	}
+}
+
+// getAmountOut mirrors UniswapV2Library.getAmountOut.
+func getAmountOut(amountIn, reserveIn, reserveOut *big.Int) *big.Int {
+	withFee := new(big.Int).Mul(amountIn, u(997))
+	numerator := new(big.Int).Mul(withFee, reserveOut)
+	denominator := new(big.Int).Add(new(big.Int).Mul(reserveIn, u(1000)), withFee)
+	return numerator.Div(numerator, denominator)
+}
+
+// getAmountIn mirrors UniswapV2Library.getAmountIn.
+func getAmountIn(amountOut, reserveIn, reserveOut *big.Int) *big.Int {
+	numerator := new(big.Int).Mul(reserveIn, amountOut)
+	numerator.Mul(numerator, u(1000))
+	denominator := new(big.Int).Sub(reserveOut, amountOut)
+	denominator.Mul(denominator, u(997))
+	res := numerator.Div(numerator, denominator)
+	return res.Add(res, u(1))
+}
+
+func uniswapSpec() contractSpec {
+	var (
+		amountOut = function("getAmountOut(uint256,uint256,uint256)")
+		amountIn  = function("getAmountIn(uint256,uint256,uint256)")
+		quotePath = function("quotePath(uint256,uint256[])")
+	)
+	ether := new(big.Int).Exp(u(10), u(18), nil)
+	eth := func(v uint64) *big.Int { return new(big.Int).Mul(u(v), ether) }
+
+	// path returns the reserves of a multi-hop route and its final output.
+	path := func(hops int, amount *big.Int) ([]*big.Int, *big.Int) {
+		var reserves []*big.Int
+		seed := uint64(0x5eed)
+		for i := 0; i < hops; i++ {
+			seed = lcg(seed)
+			reserveIn := eth(1_000 + seed%1_000_000)
+			seed = lcg(seed)
+			reserveOut := eth(1_000 + seed%1_000_000)
+			reserves = append(reserves, reserveIn, reserveOut)
+			amount = getAmountOut(amount, reserveIn, reserveOut)
+		}
+		return reserves, amount
+	}
+	path3, out3 := path(3, eth(10))
+	path16, out16 := path(16, eth(10))
+
+	return contractSpec{
+		Name:      "uniswap",
+		Contract:  "UniswapV2Math",
+		Functions: []function{amountOut, amountIn, quotePath},
+		Calls: []corpus.Call{
+			call("getAmountOut", amountOut, defaultGas, nil, returns(getAmountOut(eth(1), eth(1_000), eth(2_000_000))), eth(1), eth(1_000), eth(2_000_000)),
+			call("getAmountOut-zero-input", amountOut, defaultGas, nil, reverts("Error(string)", "UniswapV2Library: INSUFFICIENT_INPUT_AMOUNT"), u(0), eth(1_000), eth(2_000_000)),
+			call("getAmountIn", amountIn, defaultGas, nil, returns(getAmountIn(eth(1), eth(2_000_000), eth(1_000))), eth(1), eth(2_000_000), eth(1_000)),
+			call("getAmountIn-insufficient-liquidity", amountIn, defaultGas, nil, reverts("Error(string)", "UniswapV2Library: INSUFFICIENT_LIQUIDITY"), eth(1_000), eth(2_000_000), eth(1_000)),
+			call("quotePath-3", quotePath, defaultGas, nil, returns(out3), eth(10), path3),
+			call("quotePath-16", quotePath, defaultGas, nil, returns(out16), eth(10), path16),
+		},
+	}
+}
+
+// merkleProof builds a tree over numLeaves leaves with OpenZeppelin's
+// sorted pair hashing and returns the root, the leaf at index and its proof.
+func merkleProof(numLeaves, index int) (root, leaf corpus.Hash, proof []corpus.Hash) {
+	level := make([]corpus.Hash, numLeaves)
+	for i := range level {
+		level[i] = keccakHash(word(i))
+	}
+	leaf = level[index]
+	for len(level) > 1 {
+		proof = append(proof, level[index^1])
+		next := make([]corpus.Hash, len(level)/2)
+		for i := range next {
+			a, b := level[2*i], level[2*i+1]
+			if string(b[:]) < string(a[:]) {
+				a, b = b, a
+			}
+			next[i] = keccakHash(a[:], b[:])
+		}
+		level, index = next, index/2
+	}
+	return level[0], leaf, proof
+}
+
+func merkleSpec() contractSpec {
+	verify := function("verify(bytes32[],bytes32,bytes32)")
+	root4, leaf4, proof4 := merkleProof(1<<4, 5)
+	root16, leaf16, proof16 := merkleProof(1<<16, 40_000)
+	return contractSpec{
+		Name:      "merkle",
+		Contract:  "MerkleVerifier",
+		Functions: []function{verify},
+		Calls: []corpus.Call{
+			call("verify-depth-4", verify, defaultGas, nil, returns(true), proof4, root4, leaf4),
+			call("verify-depth-16", verify, defaultGas, nil, returns(true), proof16, root16, leaf16),
+			call("verify-invalid-leaf", verify, defaultGas, nil, returns(false), proof16, root16, leaf4),
+		},
+	}
+}
+
+func hash(s string) corpus.Hash {
+	var res corpus.Hash
+	if err := res.UnmarshalText([]byte(s)); err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func address(s string) corpus.Address {
+	var res corpus.Address
+	if err := res.UnmarshalText([]byte(s)); err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func ecdsaSpec() contractSpec {
+	var (
+		recover = function("recover(bytes32,uint8,bytes32,bytes32)")
+		verify  = function("verify(bytes32,uint8,bytes32,bytes32,address)")
+	)
+	// The ValidKey vector of the ecrecover precompile tests.
+	var (
+		digest = hash("0x18c547e4f7b0f325ad1e56f57e26c745b09a3e503d86e00e5255ff7f715d3d1c")
+		r      = hash("0x73b1693892219d736caba55bdb67216e485557ea6b6af75f37096c9aa6a5a75f")
+		s      = hash("0xeeb940b1d03b21e36b0e47e79769f095fe2ab855bd91e3a38756b7d75a9c4549")
+		signer = address("0xa94f5374fce5edbc8e2a8697c15331677e6ebf0b")
+	)
+	return contractSpec{
+		Name:      "ecdsa",
+		Contract:  "ECDSAVerifier",
+		Functions: []function{recover, verify},
+		Calls: []corpus.Call{
+			call("recover", recover, defaultGas, nil, returns(signer), digest, u(28), r, s),
+			call("recover-invalid-signature", recover, defaultGas, nil, returns(corpus.Address{}), digest, u(29), r, s),
+			call("verify", verify, defaultGas, nil, returns(true), digest, u(28), r, s, signer),
+			call("verify-wrong-signer", verify, defaultGas, nil, returns(false), digest, u(28), r, s, bob),
+		},
+	}
+}
+
+// lcg is the deterministic generator used for pseudo random inputs.
+func lcg(x uint64) uint64 {
+	return x*6364136223846793005 + 1442695040888963407
+}
+
+func sortSpec() contractSpec {
+	sortF := function("sort(uint256[])")
+	values := func(n int, order string) (input, sorted []*big.Int) {
+		seed := uint64(n)
+		for i := 0; i < n; i++ {
+			seed = lcg(seed)
+			input = append(input, u(seed>>16))
+		}
+		switch order {
+		case "sorted":
+			sort.Slice(input, func(i, j int) bool { return input[i].Cmp(input[j]) < 0 })
+		case "reversed":
+			sort.Slice(input, func(i, j int) bool { return input[i].Cmp(input[j]) > 0 })
+		}
+		sorted = append(sorted, input...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+		return input, sorted
+	}
+	var calls []corpus.Call
+	for _, c := range []struct {
+		name  string
+		n     int
+		order string
+		gas   uint64
+	}{
+		{"sort-empty", 0, "random", defaultGas},
+		{"sort-16", 16, "random", defaultGas},
+		{"sort-256", 256, "random", 10_000_000},
+		{"sort-64-sorted", 64, "sorted", defaultGas},
+		{"sort-64-reversed", 64, "reversed", defaultGas},
+	} {
+		input, sorted := values(c.n, c.order)
+		calls = append(calls, call(c.name, sortF, c.gas, nil, returns(sorted), input))
+	}
+	return contractSpec{
+		Name:      "sort",
+		Contract:  "Sort",
+		Functions: []function{sortF},
+		Calls:     calls,
+	}
+}
+
+func stringsSpec() contractSpec {
+	var (
+		toUpper = function("toUpper(string)")
+		concat  = function("concat(string,string)")
+	)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 23)[:1024]
+	binary := hex.EncodeToString(keccak([]byte("corpus")))
+	return contractSpec{
+		Name:      "strings",
+		Contract:  "Strings",
+		Functions: []function{toUpper, concat},
+		Calls: []corpus.Call{
+			call("toUpper", toUpper, defaultGas, nil, returns("HELLO, WORLD!"), "Hello, World!"),
+			call("toUpper-1KB", toUpper, defaultGas, nil, returns(strings.ToUpper(text)), text),
+			call("toUpper-empty", toUpper, defaultGas, nil, returns(""), ""),
+			call("concat", concat, defaultGas, nil, returns("Hello, World!"), "Hello, ", "World!"),
+			call("concat-1KB", concat, defaultGas, nil, returns(text+binary), text, binary),
+		},
+	}
+}
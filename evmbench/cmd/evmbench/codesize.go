@@ -0,0 +1,116 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+)
+
+// maxCachedCodeSize is the largest code LFVM keeps converted, the contract
+// size limit of EIP-170. Larger initcode is converted on every call.
+const maxCachedCodeSize = 24576
+
+func codeSizeCommand(args []string) error {
+	flags := flag.NewFlagSet("codesize", flag.ExitOnError)
+	settings := addFamilyFlags(flags, "lfvm,bsc")
+	output := flags.String("o", "", "also write the measured samples to this result file")
+	format := flags.String("format", "markdown", fmt.Sprintf("output format, one of %v", results.Formats))
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: evmbench codesize [flags] [result files]\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	families, err := selectFamilies(corpus.CodeSizeFamilies(), settings.families)
+	if err != nil {
+		return err
+	}
+	res, err := loadOrBenchmark(flags.Args(), *output, func() (*results.Results, error) {
+		return settings.benchmark(families, []string{"BenchmarkCodeAnalysis", "BenchmarkFamilies"}, []string{"convert", "convert-super", "bitmap"})
+	})
+	if err != nil {
+		return err
+	}
+	revision, err := corpus.ParseRevision(settings.revision)
+	if err != nil {
+		return err
+	}
+	return codeSizeTable(res, families, revision).Write(os.Stdout, *format)
+}
+
+// codeSizeTable relates LFVM's code conversion to BSC's JUMPDEST analysis
+// per family member. Both are cached per code hash, so LFVM pays its extra
+// conversion cost once and recovers it with every call faster than BSC's;
+// the break-even is the number of calls after which it has paid off.
+func codeSizeTable(res *results.Results, families []corpus.Family, revision corpus.Revision) results.Table {
+	table := results.Table{Header: []string{"Code", "Size", "lfvm convert ns", "lfvm convert-super ns", "bsc bitmap ns", "convert/bitmap", "lfvm call ns", "bsc call ns", "break-even calls"}}
+	median := func(engine, vector string) float64 {
+		record, found := res.Lookup(results.Key{Engine: engine, Revision: revision, Vector: vector})
+		if !found {
+			return math.NaN()
+		}
+		return results.Summarize(record.Values("ns/op")).Median
+	}
+	for _, family := range families {
+		for _, member := range family.Members {
+			convert := median("lfvm", member.Name+"/convert")
+			bitmap := median("bsc", member.Name+"/bitmap")
+			lfvmCall, bscCall := median("lfvm", member.Name), median("bsc", member.Name)
+			table.Rows = append(table.Rows, []string{
+				member.Name,
+				fmt.Sprint(len(member.Code)),
+				formatNs(convert),
+				formatNs(median("lfvm", member.Name+"/convert-super")),
+				formatNs(bitmap),
+				formatRatio(convert, bitmap),
+				formatNs(lfvmCall),
+				formatNs(bscCall),
+				breakEven(len(member.Code), convert-bitmap, bscCall-lfvmCall),
+			})
+		}
+	}
+	return table
+}
+
+// breakEven returns the number of calls after which extra conversion time
+// is recovered by the given saving per call.
+func breakEven(size int, extra, saving float64) string {
+	switch {
+	case math.IsNaN(extra) || math.IsNaN(saving):
+		return "-"
+	case size > maxCachedCodeSize:
+		return "not cached"
+	case extra <= 0:
+		return "0"
+	case saving <= 0:
+		return "never"
+	}
+	return fmt.Sprint(math.Ceil(extra / saving))
+}
+
+func formatNs(ns float64) string {
+	return formatCount(ns)
+}
+
+// formatCount formats a quantity without a fractional part worth showing,
+// such as bytes or runs per second.
+func formatCount(v float64) string {
+	if math.IsNaN(v) {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f", v)
+}
+
+func formatRatio(a, b float64) string {
+	if math.IsNaN(a) || math.IsNaN(b) || b == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1fx", a/b)
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sonicoperations/evmbench/results"
+)
+
+func compareCommand(args []string) error {
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	metric := flags.String("metric", "ns/op", "metric to compare, e.g. ns/op, B/op, allocs/op, IPC or ns/run for single iterations")
+	alpha := flags.Float64("alpha", 0.05, "significance level of the Mann-Whitney U test")
+	format := flags.String("format", "markdown", fmt.Sprintf("output format, one of %v", results.Formats))
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: evmbench compare [flags] old.json new.json\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	old, err := results.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	new, err := results.ReadFile(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	deltas := results.Compare(old, new, *metric)
+	if len(deltas) == 0 {
+		return fmt.Errorf("the result files have no vector, engine and revision in common")
+	}
+	return results.Comparison(deltas, *metric, *alpha).Write(os.Stdout, *format)
+}
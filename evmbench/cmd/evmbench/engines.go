@@ -0,0 +1,228 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/worker"
+)
+
+// engine is a benchmark module executing corpus vectors. Every module
+// provides BenchmarkContracts and TestContracts, BenchmarkFailures and
+// TestFailures, and BenchmarkDeployments and TestDeployments with a
+// sub-test per vector, BenchmarkFamilies and TestFamilies with one per
+// family member, and supports the -revision and -outcomes test flags.
+type engine struct {
+	name   string
+	dir    string // module directory relative to the repository root
+	module string // module providing the engine
+}
+
+var engines = []engine{
+	{"lfvm", "tosca_benchmarks", "github.com/0xsoniclabs/tosca"},
+	{"bsc", "bsc_interpreter_benchmarks", "github.com/ethereum/go-ethereum"},
+}
+
+// engineNames returns the names of the given engines, or of all if none
+// are given.
+func engineNames(selected ...engine) []string {
+	if len(selected) == 0 {
+		selected = engines
+	}
+	var res []string
+	for _, e := range selected {
+		res = append(res, e.name)
+	}
+	return res
+}
+
+// parseEngines resolves a comma separated list of engine names.
+func parseEngines(list string) ([]engine, error) {
+	var res []engine
+	for _, name := range strings.Split(list, ",") {
+		found := false
+		for _, e := range engines {
+			if e.name == strings.TrimSpace(name) {
+				res = append(res, e)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown engine %q, available are %v", name, engineNames())
+		}
+	}
+	return res, nil
+}
+
+// parseRevisions resolves a comma separated list of revision names.
+func parseRevisions(list string) ([]corpus.Revision, error) {
+	var res []corpus.Revision
+	for _, name := range strings.Split(list, ",") {
+		revision, err := corpus.ParseRevision(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, revision)
+	}
+	return res, nil
+}
+
+// The tests and benchmarks of the engine modules executing the vectors of
+// selectVectors: the workload contracts, the failure vectors and the
+// deployments.
+var (
+	vectorTests      = []string{"TestContracts", "TestFailures", "TestDeployments"}
+	vectorBenchmarks = []string{"BenchmarkContracts", "BenchmarkFailures", "BenchmarkDeployments"}
+)
+
+// benchmarkVectors loads the vectors each of vectorBenchmarks runs from
+// the corpus at root.
+var benchmarkVectors = map[string]func(root string) ([]corpus.Vector, error){
+	"BenchmarkContracts": func(root string) ([]corpus.Vector, error) {
+		contracts, err := corpus.LoadContracts(root)
+		if err != nil {
+			return nil, err
+		}
+		var res []corpus.Vector
+		for _, contract := range contracts {
+			res = append(res, contract.Vectors()...)
+		}
+		return res, nil
+	},
+	"BenchmarkFailures":    func(string) ([]corpus.Vector, error) { return corpus.FailureVectors(), nil },
+	"BenchmarkDeployments": corpus.LoadDeployments,
+}
+
+// selectVectors returns the corpus vectors run by benchmarks, a subset of
+// vectorBenchmarks, in their order, whose names match pattern.
+func selectVectors(pattern string, benchmarks []string) ([]corpus.Vector, error) {
+	filter, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	root, err := corpus.Root()
+	if err != nil {
+		return nil, err
+	}
+	var res []corpus.Vector
+	for _, benchmark := range benchmarks {
+		vectors, err := benchmarkVectors[benchmark](root)
+		if err != nil {
+			return nil, err
+		}
+		for _, vector := range vectors {
+			if filter.MatchString(vector.Name) {
+				res = append(res, vector)
+			}
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no vector matches %q", pattern)
+	}
+	return res, nil
+}
+
+// subTestPattern returns a go test -run/-bench pattern selecting the
+// sub-tests of the given vectors. Vector names <contract>/<call> span two
+// levels, so the pattern may select a few more vectors than requested.
+func subTestPattern(test string, vectors []corpus.Vector) string {
+	var contracts, calls []string
+	seen := map[string]bool{}
+	for _, vector := range vectors {
+		contract, call, _ := strings.Cut(vector.Name, "/")
+		if !seen["c:"+contract] {
+			seen["c:"+contract] = true
+			contracts = append(contracts, regexp.QuoteMeta(contract))
+		}
+		if !seen["v:"+call] {
+			seen["v:"+call] = true
+			calls = append(calls, regexp.QuoteMeta(call))
+		}
+	}
+	return fmt.Sprintf("^%s$/^(%s)$/^(%s)$", test, strings.Join(contracts, "|"), strings.Join(calls, "|"))
+}
+
+// path returns the directory of the engine's module.
+func (e engine) path() (string, error) {
+	root, err := corpus.Root()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(root), e.dir), nil
+}
+
+// version returns the version of the engine's module as resolved in the
+// benchmark module, including replacements, e.g.
+// "github.com/bnb-chain/bsc v1.5.10".
+func (e engine) version() (string, error) {
+	return e.goCommand("list", "-m", "-f", "{{with .Replace}}{{.Path}} {{.Version}}{{else}}{{.Path}} {{.Version}}{{end}}", e.module)
+}
+
+// goVersion returns the version of the Go toolchain building the engine's
+// module, which may differ from the one running evmbench.
+func (e engine) goVersion() (string, error) {
+	return e.goCommand("env", "GOVERSION")
+}
+
+func (e engine) goCommand(args ...string) (string, error) {
+	dir, err := e.path()
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// buildWorker compiles the tests of the engine's module into dir and
+// returns the path of the binary, which serves the evmbench worker protocol
+// if run with -test.run '^TestWorker$' -worker.
+func (e engine) buildWorker(dir string) (string, error) {
+	binary := filepath.Join(dir, e.name+".test")
+	_, err := e.goCommand("test", "-c", "-o", binary, ".")
+	return binary, err
+}
+
+// startWorker starts a worker process from a binary built by buildWorker,
+// executing vectors in revision with the given GOMAXPROCS.
+func startWorker(binary string, revision corpus.Revision, cpu int) (*worker.Client, error) {
+	cmd := exec.Command(binary, "-test.run", "^TestWorker$", "-worker", "-revision", revision.String())
+	cmd.Env = append(os.Environ(), "GOMAXPROCS="+strconv.Itoa(cpu))
+	cmd.Stderr = os.Stderr
+	return worker.Start(cmd)
+}
+
+// goTest runs go test in the module of an engine with the given flags,
+// followed by the test binary flags. The output is returned and, if log
+// is not nil, copied to it.
+func (e engine) goTest(flags, testFlags []string, log io.Writer) ([]byte, error) {
+	dir, err := e.path()
+	if err != nil {
+		return nil, err
+	}
+	args := append(append([]string{"test"}, flags...), ".")
+	args = append(args, testFlags...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if log != nil {
+		cmd.Stdout = io.MultiWriter(&out, log)
+	}
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	return out.Bytes(), err
+}
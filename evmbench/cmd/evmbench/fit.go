@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+)
+
+func fitCommand(args []string) error {
+	flags := flag.NewFlagSet("fit", flag.ExitOnError)
+	settings := addFamilyFlags(flags, strings.Join(engineNames(), ","))
+	output := flags.String("o", "", "also write the measured samples to this result file")
+	format := flags.String("format", "markdown", fmt.Sprintf("output format, one of %v", results.Formats))
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: evmbench fit [flags] [result files]\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	families, err := selectFamilies(corpus.Families(), settings.families)
+	if err != nil {
+		return err
+	}
+	res, err := loadOrBenchmark(flags.Args(), *output, func() (*results.Results, error) {
+		return settings.benchmark(families, []string{"BenchmarkFamilies"}, nil)
+	})
+	if err != nil {
+		return err
+	}
+	return results.FamilyFits(res, families).Write(os.Stdout, *format)
+}
+
+// familySettings are the flags of the commands benchmarking families.
+type familySettings struct {
+	families, engines, revision, benchtime string
+	count, cpu                             int
+	verbose                                bool
+}
+
+func addFamilyFlags(flags *flag.FlagSet, engines string) *familySettings {
+	s := &familySettings{}
+	flags.StringVar(&s.engines, "engines", engines, "comma separated engines to benchmark")
+	flags.StringVar(&s.families, "families", ".", "regular expression selecting families by name")
+	flags.StringVar(&s.revision, "revision", corpus.DefaultRevision.String(), "revision the families are benchmarked in")
+	flags.IntVar(&s.count, "count", 5, "samples per family member and engine")
+	flags.StringVar(&s.benchtime, "benchtime", "1s", "duration or iterations (Nx) per sample")
+	flags.IntVar(&s.cpu, "cpu", runtime.NumCPU(), "GOMAXPROCS of the benchmarks")
+	flags.BoolVar(&s.verbose, "v", false, "print the output of go test")
+	return s
+}
+
+// selectFamilies returns the families whose names match pattern.
+func selectFamilies(families []corpus.Family, pattern string) ([]corpus.Family, error) {
+	filter, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var res []corpus.Family
+	for _, family := range families {
+		if filter.MatchString(family.Name) {
+			res = append(res, family)
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no family matches %q", pattern)
+	}
+	return res, nil
+}
+
+// loadOrBenchmark reads and merges the given result files, or runs the
+// benchmarks if there are none. Measured samples are written to output if
+// it is not empty.
+func loadOrBenchmark(paths []string, output string, benchmark func() (*results.Results, error)) (*results.Results, error) {
+	if len(paths) > 0 {
+		res := &results.Results{}
+		for _, path := range paths {
+			file, err := results.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			res.Records = append(res.Records, file.Records...)
+		}
+		return res, nil
+	}
+	res, err := benchmark()
+	if err != nil {
+		return nil, err
+	}
+	if output != "" {
+		if err := results.WriteFile(output, res); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "wrote %d records to %s\n", len(res.Records), output)
+	}
+	return res, nil
+}
+
+// benchmark times the members of the given families on the selected
+// engines through the sub-benchmarks of the given benchmarks, which are
+// named by the member vectors. Sub-benchmarks of a vector named by one of
+// steps, e.g. <vector>/convert, are recorded under their full name.
+func (s *familySettings) benchmark(families []corpus.Family, benchmarks, steps []string) (*results.Results, error) {
+	selected, err := parseEngines(s.engines)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := corpus.ParseRevision(s.revision)
+	if err != nil {
+		return nil, err
+	}
+	var vectors []corpus.Vector
+	hashes := map[string]string{}
+	for _, family := range families {
+		if revision < family.Since {
+			return nil, fmt.Errorf("%s requires %s", family.Name, family.Since)
+		}
+		for _, vector := range family.Vectors() {
+			vectors = append(vectors, vector)
+			hashes[vector.Name] = vector.Hash()
+			for _, step := range steps {
+				hashes[vector.Name+"/"+step] = vector.Hash()
+			}
+		}
+	}
+
+	goFlags := []string{
+		"-run", "^$",
+		"-bench", subTestPattern("("+strings.Join(benchmarks, "|")+")", vectors),
+		"-benchmem",
+		"-count", strconv.Itoa(s.count),
+		"-benchtime", s.benchtime,
+		"-cpu", strconv.Itoa(s.cpu),
+	}
+	var log io.Writer
+	if s.verbose {
+		log = os.Stderr
+	}
+
+	res := &results.Results{}
+	for _, engine := range selected {
+		base, err := engineRecord(engine, s.cpu)
+		if err != nil {
+			return nil, err
+		}
+		base.Revision = revision
+		fmt.Fprintf(os.Stderr, "benchmarking %d family members on %s in %s\n", len(vectors), engine.name, revision)
+		if err := benchmarkEngine(res, engine, base, benchmarks, goFlags, []string{"-revision", revision.String()}, hashes, log); err != nil {
+			return nil, err
+		}
+	}
+	res.Sort()
+	return res, nil
+}
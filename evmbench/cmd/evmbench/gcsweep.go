@@ -0,0 +1,335 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+)
+
+// mixedVector names the records of BenchmarkMixedWorkload, which runs the
+// whole soak mix round-robin rather than a single vector.
+const mixedVector = "mixed"
+
+// gcSetting is a point of the sweep grid. An empty GOMEMLIMIT leaves the
+// limit unset.
+type gcSetting struct {
+	gogc, gomemlimit string
+}
+
+func (s gcSetting) isDefault() bool {
+	return s.gogc == "100" && s.gomemlimit == ""
+}
+
+// limit returns the GOMEMLIMIT as passed to the runtime, off if unset.
+func (s gcSetting) limit() string {
+	if s.gomemlimit == "" {
+		return "off"
+	}
+	return s.gomemlimit
+}
+
+func (s gcSetting) String() string {
+	return fmt.Sprintf("GOGC=%s GOMEMLIMIT=%s", s.gogc, s.limit())
+}
+
+// gcSweepCommand runs BenchmarkMixedWorkload and the vector benchmarks of
+// run of every engine under a grid of GOGC and GOMEMLIMIT values. The test
+// binary of an engine is built once and started per setting with the values
+// in its environment; settings alternate between the engines, so drifts of
+// the machine affect all alike.
+func gcSweepCommand(args []string) error {
+	flags := flag.NewFlagSet("gcsweep", flag.ExitOnError)
+	engineList := flags.String("engines", strings.Join(engineNames(), ","), "comma separated engines to benchmark")
+	gogcList := flags.String("gogc", "50,100,200,400,off", "comma separated GOGC values")
+	limitList := flags.String("gomemlimit", "off,64MiB,256MiB", "comma separated GOMEMLIMIT values, off for none")
+	vectorPattern := flags.String("vectors", ".", "regular expression selecting the vectors of the vector benchmarks, empty for the mixed workload only")
+	revisionName := flags.String("revision", corpus.DefaultRevision.String(), "revision the vectors are executed in")
+	count := flags.Int("count", 5, "samples per setting and engine")
+	benchtime := flags.String("benchtime", "1s", "duration or iterations (Nx) per sample of the mixed workload")
+	vectorBenchtime := flags.String("vector-benchtime", "100ms", "duration or iterations (Nx) per sample of a vector")
+	cpu := flags.Int("cpu", runtime.NumCPU(), "GOMAXPROCS of the benchmarks")
+	output := flags.String("o", "", "also write the measured samples to this result file")
+	format := flags.String("format", "markdown", fmt.Sprintf("output format, one of %v", results.Formats))
+	verbose := flags.Bool("v", false, "print the output of the benchmarks")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: evmbench gcsweep [flags] [result files]\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	revision, err := corpus.ParseRevision(*revisionName)
+	if err != nil {
+		return err
+	}
+	res, err := loadOrBenchmark(flags.Args(), *output, func() (*results.Results, error) {
+		selected, err := parseEngines(*engineList)
+		if err != nil {
+			return nil, err
+		}
+		settings, err := parseGCSettings(*gogcList, *limitList)
+		if err != nil {
+			return nil, err
+		}
+		var vectors []corpus.Vector
+		if *vectorPattern != "" {
+			if vectors, err = selectVectors(*vectorPattern, vectorBenchmarks); err != nil {
+				return nil, err
+			}
+		}
+		hashes := map[string]string{}
+		for _, vector := range vectors {
+			hashes[vector.Name] = vector.Hash()
+		}
+		dir, err := os.MkdirTemp("", "evmbench-gcsweep")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+
+		binaries := make([]string, len(selected))
+		bases := make([]results.Record, len(selected))
+		for i, engine := range selected {
+			fmt.Fprintf(os.Stderr, "building %s benchmarks\n", engine.name)
+			if binaries[i], err = engine.buildWorker(dir); err != nil {
+				return nil, fmt.Errorf("%s: failed to build benchmarks: %w", engine.name, err)
+			}
+			if bases[i], err = engineRecord(engine, *cpu); err != nil {
+				return nil, err
+			}
+			bases[i].Revision = revision
+		}
+
+		var log io.Writer
+		if *verbose {
+			log = os.Stderr
+		}
+		res := &results.Results{}
+		for _, setting := range settings {
+			for i, engine := range selected {
+				fmt.Fprintf(os.Stderr, "benchmarking %s with %s\n", engine.name, setting)
+				base := bases[i]
+				base.Env.GOGC, base.Env.GOMEMLIMIT = setting.gogc, setting.gomemlimit
+				run := func(bench, benchtime string) ([]byte, error) {
+					out, err := runGCSetting(binaries[i], engine, setting, log,
+						"-test.run", "^$",
+						"-test.bench", bench,
+						"-test.benchmem",
+						"-test.count", strconv.Itoa(*count),
+						"-test.benchtime", benchtime,
+						"-test.cpu", strconv.Itoa(*cpu),
+						"-revision", revision.String(),
+					)
+					if err != nil {
+						return nil, fmt.Errorf("%s with %s: %w\n%s", engine.name, setting, err, out)
+					}
+					return out, nil
+				}
+
+				out, err := run("^BenchmarkMixedWorkload$", *benchtime)
+				if err != nil {
+					return nil, err
+				}
+				benchmarks, err := results.ParseBenchmarks(bytes.NewReader(out), *cpu)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", engine.name, err)
+				}
+				record := base
+				record.Vector = mixedVector
+				for _, b := range benchmarks {
+					if b.Name == "BenchmarkMixedWorkload" {
+						record.Samples = append(record.Samples, b.Sample)
+					}
+				}
+				if len(record.Samples) == 0 {
+					return nil, fmt.Errorf("%s with %s: no samples of BenchmarkMixedWorkload", engine.name, setting)
+				}
+				res.Records = append(res.Records, record)
+
+				if len(vectors) == 0 {
+					continue
+				}
+				if out, err = run(subTestPattern("("+strings.Join(vectorBenchmarks, "|")+")", vectors), *vectorBenchtime); err != nil {
+					return nil, err
+				}
+				for _, benchmark := range vectorBenchmarks {
+					if err := addSamples(res, out, benchmark, base, hashes, *cpu); err != nil {
+						return nil, fmt.Errorf("%s: %w", engine.name, err)
+					}
+				}
+			}
+		}
+		return res, nil
+	})
+	if err != nil {
+		return err
+	}
+	return gcSweepTable(res, revision).Write(os.Stdout, *format)
+}
+
+// runGCSetting runs the test binary of engine with args under the GC
+// setting and returns its output, which is copied to log if not nil.
+func runGCSetting(binary string, engine engine, setting gcSetting, log io.Writer, args ...string) ([]byte, error) {
+	dir, err := engine.path()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(binary, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOGC="+setting.gogc, "GOMEMLIMIT="+setting.limit())
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if log != nil {
+		cmd.Stdout = io.MultiWriter(&out, log)
+	}
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	return out.Bytes(), err
+}
+
+// parseGCSettings returns the grid of all combinations of the GOGC and
+// GOMEMLIMIT values, GOGC varying fastest. Values are passed on to the Go
+// runtime as given, except for a GOMEMLIMIT of off, which is recorded as
+// no limit.
+func parseGCSettings(gogcList, limitList string) ([]gcSetting, error) {
+	split := func(list string) []string {
+		var res []string
+		for _, value := range strings.Split(list, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				res = append(res, value)
+			}
+		}
+		return res
+	}
+	gogcs, limits := split(gogcList), split(limitList)
+	if len(gogcs) == 0 || len(limits) == 0 {
+		return nil, fmt.Errorf("the grid needs at least one GOGC and one GOMEMLIMIT value")
+	}
+	var res []gcSetting
+	for _, limit := range limits {
+		if limit == "off" {
+			limit = ""
+		}
+		for _, gogc := range gogcs {
+			if _, err := strconv.Atoi(gogc); err != nil && gogc != "off" {
+				return nil, fmt.Errorf("invalid GOGC %q, wanted a percentage or off", gogc)
+			}
+			res = append(res, gcSetting{gogc: gogc, gomemlimit: limit})
+		}
+	}
+	return res, nil
+}
+
+// gcSweepTable lists per engine and setting the medians of the mixed
+// workload's throughput in runs per second, its ratio to the default of
+// GOGC=100 without limit, the latency percentiles, the bytes allocated per
+// run and the GC cycles per second, and the speedup of the vector
+// benchmarks over the default, the geometric mean over the vectors. The
+// setting with the highest throughput of an engine and that with the
+// lowest p99 latency are marked as its recommendations.
+func gcSweepTable(res *results.Results, revision corpus.Revision) results.Table {
+	table := results.Table{Header: []string{"Engine", "GOGC", "GOMEMLIMIT", "Runs/s", "vs default", "p50 ns", "p99 ns", "Max ns", "B/op", "GC/s", "Vectors vs default", "Note"}}
+	median := func(record results.Record, unit string) float64 {
+		values := record.Values(unit)
+		if len(values) == 0 {
+			return math.NaN()
+		}
+		return results.Summarize(values).Median
+	}
+	vectorSpeedup := func(engine string, setting gcSetting) float64 {
+		defaults := map[string]float64{}
+		for _, record := range res.Records {
+			if record.Engine == engine && record.Revision == revision && record.Vector != mixedVector &&
+				(gcSetting{record.Env.GOGC, record.Env.GOMEMLIMIT}).isDefault() {
+				defaults[record.Vector] = median(record, "ns/op")
+			}
+		}
+		logSum, count := 0.0, 0
+		for _, record := range res.Records {
+			if record.Engine != engine || record.Revision != revision || record.Vector == mixedVector ||
+				(gcSetting{record.Env.GOGC, record.Env.GOMEMLIMIT}) != setting {
+				continue
+			}
+			ratio := defaults[record.Vector] / median(record, "ns/op")
+			if math.IsNaN(ratio) || math.IsInf(ratio, 0) || ratio == 0 {
+				continue
+			}
+			logSum += math.Log(ratio)
+			count++
+		}
+		if count == 0 {
+			return math.NaN()
+		}
+		return math.Exp(logSum / float64(count))
+	}
+	for _, engine := range res.Engines() {
+		var records []results.Record
+		fastest, calmest := -1, -1
+		baseline := math.NaN()
+		for _, record := range res.Records {
+			if record.Engine != engine || record.Revision != revision || record.Vector != mixedVector {
+				continue
+			}
+			i := len(records)
+			records = append(records, record)
+			runs, p99 := 1e9/median(record, "ns/op"), median(record, "p99-ns")
+			if fastest < 0 || runs > 1e9/median(records[fastest], "ns/op") {
+				fastest = i
+			}
+			if calmest < 0 || p99 < median(records[calmest], "p99-ns") {
+				calmest = i
+			}
+			if (gcSetting{record.Env.GOGC, record.Env.GOMEMLIMIT}).isDefault() {
+				baseline = runs
+			}
+		}
+		for i, record := range records {
+			runs := 1e9 / median(record, "ns/op")
+			setting := gcSetting{record.Env.GOGC, record.Env.GOMEMLIMIT}
+			var notes []string
+			if setting.isDefault() {
+				notes = append(notes, "default")
+			}
+			if i == fastest {
+				notes = append(notes, "highest throughput")
+			}
+			if i == calmest {
+				notes = append(notes, "lowest p99")
+			}
+			table.Rows = append(table.Rows, []string{
+				engine, setting.gogc, setting.limit(),
+				formatCount(runs),
+				formatSpeedup(runs, baseline),
+				formatNs(median(record, "p50-ns")),
+				formatNs(median(record, "p99-ns")),
+				formatNs(median(record, "max-ns")),
+				formatCount(median(record, "B/op")),
+				fmt.Sprintf("%.1f", median(record, "gc/s")),
+				formatSpeedup(vectorSpeedup(engine, setting), 1),
+				strings.Join(notes, ", "),
+			})
+		}
+	}
+	return table
+}
+
+// formatSpeedup formats a ratio close to one with the precision GC tuning
+// needs, where a few percent matter.
+func formatSpeedup(a, b float64) string {
+	if math.IsNaN(a) || math.IsNaN(b) || b == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2fx", a/b)
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+	"github.com/sonicoperations/evmbench/worker"
+)
+
+// interleaveCommand measures all engines within one session. Every engine
+// runs in a worker process built from its module's tests; vectors are
+// measured in short batches alternating between the workers, so drifts of
+// the machine's performance affect all engines alike.
+func interleaveCommand(args []string) error {
+	flags := flag.NewFlagSet("interleave", flag.ExitOnError)
+	engineList := flags.String("engines", strings.Join(engineNames(), ","), "comma separated engines to benchmark")
+	vectorPattern := flags.String("vectors", ".", "regular expression selecting vectors by name")
+	revisionList := flags.String("revisions", corpus.DefaultRevision.String(), "comma separated revisions to benchmark")
+	count := flags.Int("count", 20, "batches per vector, engine and revision")
+	batch := flags.Duration("batch", 20*time.Millisecond, "target duration of a batch")
+	cpu := flags.Int("cpu", runtime.NumCPU(), "GOMAXPROCS of the workers")
+	output := flags.String("o", "results.ndjson", "result file to write, a JSON array if it ends in .json")
+	flags.Parse(args)
+
+	selected, err := parseEngines(*engineList)
+	if err != nil {
+		return err
+	}
+	revisions, err := parseRevisions(*revisionList)
+	if err != nil {
+		return err
+	}
+	vectors, err := selectVectors(*vectorPattern, vectorBenchmarks)
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "evmbench-workers")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	binaries := make([]string, len(selected))
+	versions := make([]string, len(selected))
+	envs := make([]results.Env, len(selected))
+	for i, engine := range selected {
+		fmt.Fprintf(os.Stderr, "building %s worker\n", engine.name)
+		if binaries[i], err = engine.buildWorker(dir); err != nil {
+			return fmt.Errorf("%s: failed to build worker: %w", engine.name, err)
+		}
+		if versions[i], err = engine.version(); err != nil {
+			return fmt.Errorf("%s: failed to resolve engine version: %w", engine.name, err)
+		}
+		envs[i] = results.HostEnv()
+		envs[i].GOMAXPROCS = *cpu
+		if envs[i].GoVersion, err = engine.goVersion(); err != nil {
+			return fmt.Errorf("%s: failed to resolve Go version: %w", engine.name, err)
+		}
+	}
+
+	res := &results.Results{}
+	for _, revision := range revisions {
+		clients := make([]*worker.Client, 0, len(selected))
+		closeAll := func() {
+			for _, client := range clients {
+				client.Close()
+			}
+		}
+		for i, binary := range binaries {
+			client, err := startWorker(binary, revision, *cpu)
+			if err != nil {
+				closeAll()
+				return fmt.Errorf("%s: failed to start worker: %w", selected[i].name, err)
+			}
+			clients = append(clients, client)
+		}
+
+		fmt.Fprintf(os.Stderr, "benchmarking %d vectors on %s in %s\n", len(vectors), strings.Join(engineNames(selected...), ", "), revision)
+		for _, vector := range vectors {
+			records, err := interleave(clients, vector, *count, *batch)
+			if err != nil {
+				closeAll()
+				return err
+			}
+			for i := range records {
+				records[i].Key = results.Key{Engine: selected[i].name, Revision: revision, Vector: vector.Name}
+				records[i].EngineVersion = versions[i]
+				records[i].VectorHash = vector.Hash()
+				records[i].Env = envs[i]
+			}
+			res.Records = append(res.Records, records...)
+		}
+
+		for i, client := range clients {
+			if err := client.Close(); err != nil {
+				return fmt.Errorf("%s: worker failed: %w", selected[i].name, err)
+			}
+		}
+	}
+	res.Sort()
+	if err := results.WriteFile(*output, res); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d records to %s\n", len(res.Records), *output)
+	return nil
+}
+
+// interleave loads a vector into all workers and collects count batches of
+// each, rotating the order of the workers from batch to batch. Outcomes
+// violating the vector's expectation are reported but not fatal.
+func interleave(clients []*worker.Client, vector corpus.Vector, count int, batch time.Duration) ([]results.Record, error) {
+	iterations := make([]int, len(clients))
+	for i, client := range clients {
+		outcome, err := client.Load(vector)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", vector.Name, err)
+		}
+		if vector.Expect != nil {
+			if err := vector.Expect.Check(outcome); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %s on %s: %v\n", vector.Name, client.Engine, err)
+			}
+		}
+		if iterations[i], err = calibrate(client, batch); err != nil {
+			return nil, fmt.Errorf("%s: %w", vector.Name, err)
+		}
+	}
+
+	records := make([]results.Record, len(clients))
+	for sample := 0; sample < count; sample++ {
+		for j := range clients {
+			i := (sample + j) % len(clients)
+			s, err := clients[i].Batch(iterations[i])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", vector.Name, err)
+			}
+			records[i].Samples = append(records[i].Samples, s)
+		}
+	}
+	return records, nil
+}
+
+// calibrate returns the number of iterations taking about the batch
+// duration on a worker. The calibration runs also warm up the worker.
+func calibrate(client *worker.Client, batch time.Duration) (int, error) {
+	target := float64(batch.Nanoseconds())
+	for n := 1; ; {
+		sample, err := client.Batch(n)
+		if err != nil {
+			return 0, err
+		}
+		perRun := max(sample.NsPerOp, 1)
+		predicted := int(target / perRun)
+		if perRun*float64(n) >= target/2 {
+			return max(predicted, 1), nil
+		}
+		n = min(max(predicted, n+1), 100*n)
+	}
+}
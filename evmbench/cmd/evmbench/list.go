@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+)
+
+func listCommand(args []string) error {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	vectorPattern := flags.String("vectors", ".", "regular expression selecting vectors by name")
+	format := flags.String("format", "markdown", fmt.Sprintf("output format, one of %v", results.Formats))
+	flags.Parse(args)
+
+	vectors, err := selectVectors(*vectorPattern, vectorBenchmarks)
+	if err != nil {
+		return err
+	}
+	table := results.Table{Header: []string{"Vector", "Code size", "Input size", "Gas", "Expected status"}}
+	for _, vector := range vectors {
+		status := "-"
+		if vector.Expect != nil {
+			status = string(vector.Expect.Status)
+		}
+		table.Rows = append(table.Rows, []string{
+			vector.Name,
+			strconv.Itoa(len(vector.Code)),
+			strconv.Itoa(len(vector.Input)),
+			strconv.FormatUint(vector.Gas, 10),
+			status,
+		})
+	}
+	if err := table.Write(os.Stdout, *format); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "\nengines: %v\nrevisions: %v\n", engineNames(), corpus.Revisions)
+	return nil
+}
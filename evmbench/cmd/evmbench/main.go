@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Command evmbench runs the corpus benchmarks of all engine modules and
+// evaluates their results:
+//
+//	evmbench run        benchmark vectors on engines and revisions
+//	evmbench interleave benchmark engines in alternating batches in one session
+//	evmbench compare    statistical diff of two result files
+//	evmbench report     Markdown, HTML or CSV tables of a result file
+//	evmbench list       corpus contents
+//	evmbench verify     differential correctness check of all engines
+//	evmbench statetest  Ethereum GeneralStateTests pass/fail per engine and fork
+//	evmbench trace      EIP-3155 traces of vectors or state tests, diffed across engines
+//	evmbench fit        linear fits of synthetic vector families, e.g. time per dispatch
+//	evmbench codesize   LFVM code conversion against BSC's JUMPDEST analysis by code size
+//	evmbench pricing    time per gas of opcode classes, flagging under- and overpriced ones
+//	evmbench memory     time, gas and heap growth of memory expansion up to the gas limit
+//	evmbench tracers    slowdown of the contract corpus per tracing level
+//	evmbench soak       runtime metrics of a long mixed workload, failing on unbounded growth
+//	evmbench gcsweep    throughput and tail latency of a mixed workload per GOGC and GOMEMLIMIT
+//	evmbench search     search for the programs executing slowest per gas on an engine
+//
+// The engines live in separate Go modules since they depend on
+// incompatible versions of go-ethereum. evmbench drives them through
+// go test, or through worker processes built from their tests for
+// interleave, and needs to be run from within the repository, or with
+// EVMBENCH_CORPUS pointing to its corpus directory.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"run", "benchmark vectors on engines and revisions", runCommand},
+	{"interleave", "benchmark engines in alternating batches within one session", interleaveCommand},
+	{"compare", "statistical diff of two result files", compareCommand},
+	{"report", "Markdown, HTML or CSV tables of a result file", reportCommand},
+	{"list", "list the corpus contents", listCommand},
+	{"verify", "check that all engines produce the expected and the same outcomes", verifyCommand},
+	{"statetest", "run Ethereum GeneralStateTests on all engines", stateTestCommand},
+	{"trace", "write EIP-3155 traces of all engines and diff them", traceCommand},
+	{"fit", "fit the time of synthetic vector families against their parameter", fitCommand},
+	{"codesize", "relate LFVM's code conversion to BSC's JUMPDEST analysis by code size", codeSizeCommand},
+	{"pricing", "time per gas of opcode classes on all engines, flagging outliers", pricingCommand},
+	{"memory", "time, gas and heap growth of memory expansion on all engines", memoryCommand},
+	{"tracers", "slowdown of the contract corpus per tracing level on all engines", tracersCommand},
+	{"soak", "sample runtime metrics of a long mixed workload and check for unbounded growth", soakCommand},
+	{"gcsweep", "throughput and tail latency of a mixed workload per GOGC and GOMEMLIMIT", gcSweepCommand},
+	{"search", "search programs executing slowest per gas and save them as vectors", searchCommand},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: evmbench <command> [flags] [args]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun evmbench <command> -h for the flags of a command.\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "evmbench %s: %v\n", c.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	usage()
+	os.Exit(2)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+)
+
+func memoryCommand(args []string) error {
+	flags := flag.NewFlagSet("memory", flag.ExitOnError)
+	settings := addFamilyFlags(flags, strings.Join(engineNames(), ","))
+	output := flags.String("o", "", "also write the measured samples to this result file")
+	format := flags.String("format", "markdown", fmt.Sprintf("output format, one of %v", results.Formats))
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: evmbench memory [flags] [result files]\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	revision, err := corpus.ParseRevision(settings.revision)
+	if err != nil {
+		return err
+	}
+	// Families of later revisions are left out rather than failing.
+	var available []corpus.Family
+	for _, family := range corpus.MemoryFamilies() {
+		if family.Since <= revision {
+			available = append(available, family)
+		}
+	}
+	families, err := selectFamilies(available, settings.families)
+	if err != nil {
+		return err
+	}
+	res, err := loadOrBenchmark(flags.Args(), *output, func() (*results.Results, error) {
+		return settings.benchmark(families, []string{"BenchmarkFamilies"}, nil)
+	})
+	if err != nil {
+		return err
+	}
+	return memoryTable(res, families, revision).Write(os.Stdout, *format)
+}
+
+// memoryTable lists per member of the memory families the gas charged, of
+// which the expansion is the quadratic part, and per engine the time, the
+// time per gas and the bytes allocated on the Go heap, in total and per
+// byte of memory. Engines allocating about one byte per byte grow a fresh
+// memory every call; fewer bytes mean memory is pooled.
+func memoryTable(res *results.Results, families []corpus.Family, revision corpus.Revision) results.Table {
+	engines := res.Engines()
+	table := results.Table{Header: []string{"Vector", "Bytes", "Gas", "Expansion gas"}}
+	for _, engine := range engines {
+		table.Header = append(table.Header, engine+" ns", engine+" ns/gas", engine+" B/op", engine+" B/byte")
+	}
+	median := func(engine, vector, unit string) float64 {
+		record, found := res.Lookup(results.Key{Engine: engine, Revision: revision, Vector: vector})
+		if !found {
+			return math.NaN()
+		}
+		return results.Summarize(record.Values(unit)).Median
+	}
+	for _, family := range families {
+		for _, member := range family.Members {
+			gas := float64(member.Expect.GasUsed)
+			row := []string{
+				member.Name,
+				fmt.Sprint(member.X),
+				fmt.Sprint(member.Expect.GasUsed),
+				fmt.Sprint(corpus.MemoryGas(member.X)),
+			}
+			for _, engine := range engines {
+				ns, bytes := median(engine, member.Name, "ns/op"), median(engine, member.Name, "B/op")
+				row = append(row, formatNs(ns), formatPerUnit(ns, gas), formatCount(bytes), formatPerUnit(bytes, float64(member.X)))
+			}
+			table.Rows = append(table.Rows, row)
+		}
+	}
+	return table
+}
+
+func formatPerUnit(value, units float64) string {
+	if math.IsNaN(value) || units == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.3f", value/units)
+}
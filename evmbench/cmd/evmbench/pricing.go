@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+)
+
+func pricingCommand(args []string) error {
+	flags := flag.NewFlagSet("pricing", flag.ExitOnError)
+	settings := addFamilyFlags(flags, strings.Join(engineNames(), ","))
+	threshold := flags.Float64("threshold", 3, "flag families whose time per gas is this many times the median of all, or its inverse")
+	output := flags.String("o", "", "also write the measured samples to this result file")
+	format := flags.String("format", "markdown", fmt.Sprintf("output format, one of %v", results.Formats))
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: evmbench pricing [flags] [result files]\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	revision, err := corpus.ParseRevision(settings.revision)
+	if err != nil {
+		return err
+	}
+	// Families of later revisions are left out rather than failing.
+	var available []corpus.Family
+	for _, family := range corpus.PricingFamilies() {
+		if family.Since <= revision {
+			available = append(available, family)
+		}
+	}
+	families, err := selectFamilies(available, settings.families)
+	if err != nil {
+		return err
+	}
+	res, err := loadOrBenchmark(flags.Args(), *output, func() (*results.Results, error) {
+		return settings.benchmark(families, []string{"BenchmarkFamilies"}, nil)
+	})
+	if err != nil {
+		return err
+	}
+	return results.GasPricing(res, families, revision, *threshold).Write(os.Stdout, *format)
+}
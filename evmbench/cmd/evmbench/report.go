@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sonicoperations/evmbench/results"
+)
+
+func reportCommand(args []string) error {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	format := flags.String("format", "markdown", fmt.Sprintf("output format, one of %v; csv omits the environment table", results.Formats))
+	output := flags.String("o", "", "file to write the report to instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: evmbench report [flags] results.json\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	res, err := results.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := results.Overview(res).Write(out, *format); err != nil {
+		return err
+	}
+	if *format == "csv" {
+		return nil // a second table would not be valid CSV
+	}
+	fmt.Fprintln(out)
+	return results.Environments(res).Write(out, *format)
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+)
+
+func runCommand(args []string) error {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	engineList := flags.String("engines", strings.Join(engineNames(), ","), "comma separated engines to benchmark")
+	vectorPattern := flags.String("vectors", ".", "regular expression selecting vectors by name")
+	revisionList := flags.String("revisions", corpus.DefaultRevision.String(), "comma separated revisions to benchmark")
+	count := flags.Int("count", 5, "samples per vector, engine and revision")
+	benchtime := flags.String("benchtime", "1s", "duration or iterations (Nx) per sample")
+	cpu := flags.Int("cpu", runtime.NumCPU(), "GOMAXPROCS of the benchmarks")
+	perf := flags.Bool("perf", false, "collect hardware performance counters")
+	runs := flags.Int("runs", 1000, "iterations per vector, engine and revision timed one by one in a worker process, 0 for none")
+	output := flags.String("o", "results.ndjson", "result file to write, a JSON array if it ends in .json")
+	verbose := flags.Bool("v", false, "print the output of go test")
+	flags.Parse(args)
+
+	selected, err := parseEngines(*engineList)
+	if err != nil {
+		return err
+	}
+	revisions, err := parseRevisions(*revisionList)
+	if err != nil {
+		return err
+	}
+	vectors, err := selectVectors(*vectorPattern, vectorBenchmarks)
+	if err != nil {
+		return err
+	}
+	hashes := map[string]string{}
+	for _, vector := range vectors {
+		hashes[vector.Name] = vector.Hash()
+	}
+
+	goFlags := []string{
+		"-run", "^$",
+		"-bench", subTestPattern("("+strings.Join(vectorBenchmarks, "|")+")", vectors),
+		"-benchmem",
+		"-count", strconv.Itoa(*count),
+		"-benchtime", *benchtime,
+		"-cpu", strconv.Itoa(*cpu),
+	}
+	var log io.Writer
+	if *verbose {
+		log = os.Stderr
+	}
+
+	dir, err := os.MkdirTemp("", "evmbench-workers")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	res := &results.Results{}
+	for _, engine := range selected {
+		base, err := engineRecord(engine, *cpu)
+		if err != nil {
+			return err
+		}
+		var binary string
+		if *runs > 0 {
+			if binary, err = engine.buildWorker(dir); err != nil {
+				return fmt.Errorf("%s: failed to build worker: %w", engine.name, err)
+			}
+		}
+		for _, revision := range revisions {
+			fmt.Fprintf(os.Stderr, "benchmarking %d vectors on %s in %s\n", len(vectors), engine.name, revision)
+			testFlags := []string{"-revision", revision.String()}
+			if *perf {
+				testFlags = append(testFlags, "-perf")
+			}
+			base.Revision = revision
+			if err := benchmarkEngine(res, engine, base, vectorBenchmarks, goFlags, testFlags, hashes, log); err != nil {
+				return err
+			}
+			if *runs > 0 {
+				fmt.Fprintf(os.Stderr, "timing %d single runs per vector on %s in %s\n", *runs, engine.name, revision)
+				if err := addRuns(res, binary, engine, revision, vectors, *runs, *cpu); err != nil {
+					return fmt.Errorf("%s: %w", engine.name, err)
+				}
+			}
+		}
+	}
+	res.Sort()
+	if err := results.WriteFile(*output, res); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d records to %s\n", len(res.Records), *output)
+	return nil
+}
+
+// engineRecord returns the record the samples of engine are created from,
+// with the engine version and the host environment at GOMAXPROCS cpu.
+func engineRecord(engine engine, cpu int) (results.Record, error) {
+	version, err := engine.version()
+	if err != nil {
+		return results.Record{}, fmt.Errorf("%s: failed to resolve engine version: %w", engine.name, err)
+	}
+	env := results.HostEnv()
+	env.GOMAXPROCS = cpu
+	if env.GoVersion, err = engine.goVersion(); err != nil {
+		return results.Record{}, fmt.Errorf("%s: failed to resolve Go version: %w", engine.name, err)
+	}
+	return results.Record{Key: results.Key{Engine: engine.name}, EngineVersion: version, Env: env}, nil
+}
+
+// benchmarkEngine runs go test with goFlags and testFlags in the module of
+// engine and adds the samples of the sub-benchmarks of benchmarks, named by
+// the vectors in hashes, to res, created from base.
+func benchmarkEngine(res *results.Results, engine engine, base results.Record, benchmarks, goFlags, testFlags []string, hashes map[string]string, log io.Writer) error {
+	out, err := engine.goTest(goFlags, testFlags, log)
+	if err != nil {
+		return fmt.Errorf("%s: %w\n%s", engine.name, err, out)
+	}
+	for _, benchmark := range benchmarks {
+		if err := addSamples(res, out, benchmark, base, hashes, base.Env.GOMAXPROCS); err != nil {
+			return fmt.Errorf("%s: %w", engine.name, err)
+		}
+	}
+	return nil
+}
+
+// addSamples parses the output of go test -bench and adds the samples of
+// the sub-benchmarks of benchmark, named by the vectors in hashes, to res.
+// The records are created from base with the name and hash of the vector.
+func addSamples(res *results.Results, out []byte, benchmark string, base results.Record, hashes map[string]string, cpu int) error {
+	benchmarks, err := results.ParseBenchmarks(bytes.NewReader(out), cpu)
+	if err != nil {
+		return err
+	}
+	records := map[string]int{}
+	for _, b := range benchmarks {
+		vector := strings.TrimPrefix(b.Name, benchmark+"/")
+		hash, wanted := hashes[vector]
+		if !wanted {
+			continue
+		}
+		i, found := records[vector]
+		if !found {
+			i = len(res.Records)
+			records[vector] = i
+			record := base
+			record.Vector, record.VectorHash = vector, hash
+			res.Records = append(res.Records, record)
+		}
+		res.Records[i].Samples = append(res.Records[i].Samples, b.Sample)
+	}
+	return nil
+}
+
+// addRuns times iterations of the vectors one by one in a worker process of
+// the engine and stores their durations in the records of the vectors in
+// res. Vectors without a record are skipped.
+func addRuns(res *results.Results, binary string, engine engine, revision corpus.Revision, vectors []corpus.Vector, iterations, cpu int) error {
+	records := map[string]int{}
+	for i, record := range res.Records {
+		if record.Engine == engine.name && record.Revision == revision {
+			records[record.Vector] = i
+		}
+	}
+	client, err := startWorker(binary, revision, cpu)
+	if err != nil {
+		return fmt.Errorf("failed to start worker: %w", err)
+	}
+	for _, vector := range vectors {
+		i, found := records[vector.Name]
+		if !found {
+			continue
+		}
+		if _, err := client.Load(vector); err != nil {
+			return errors.Join(fmt.Errorf("%s: %w", vector.Name, err), client.Close())
+		}
+		// A batch of a tenth of the runs warms up caches and the allocator.
+		if _, err := client.Batch(max(1, iterations/10)); err != nil {
+			return errors.Join(fmt.Errorf("%s: %w", vector.Name, err), client.Close())
+		}
+		runs, err := client.Runs(iterations)
+		if err != nil {
+			return errors.Join(fmt.Errorf("%s: %w", vector.Name, err), client.Close())
+		}
+		res.Records[i].Runs = runs
+	}
+	return client.Close()
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+	"github.com/sonicoperations/evmbench/search"
+	"github.com/sonicoperations/evmbench/worker"
+)
+
+// searchCommand looks for the programs executing slowest per gas on one
+// engine, timing every candidate in a worker process of the engine, and
+// saves the worst offenders as vectors. They are timed on the other
+// engines for comparison.
+func searchCommand(args []string) error {
+	flags := flag.NewFlagSet("search", flag.ExitOnError)
+	engineName := flags.String("engine", "bsc", "engine to search on")
+	revisionName := flags.String("revision", corpus.DefaultRevision.String(), "revision the programs are executed in")
+	config := search.Config{}
+	flags.Uint64Var(&config.Gas, "gas", 1_000_000, "gas budget of every program")
+	flags.IntVar(&config.Length, "length", 8, "snippets of the initial program")
+	flags.IntVar(&config.MaxLength, "max-length", 64, "snippets a program may grow to")
+	flags.IntVar(&config.Generations, "generations", 100, "generations of the search")
+	flags.IntVar(&config.Children, "children", 8, "mutants per generation")
+	flags.IntVar(&config.Keep, "keep", 10, "worst offenders saved")
+	flags.Int64Var(&config.Seed, "seed", time.Now().UnixNano(), "seed of the program generator")
+	samples := flags.Int("samples", 3, "batches timed per program, the median is used")
+	batch := flags.Duration("batch", 10*time.Millisecond, "target duration of a batch")
+	blockGas := flags.Uint64("block-gas", 100_000_000, "gas of the block the time per gas is extrapolated to")
+	cpu := flags.Int("cpu", runtime.NumCPU(), "GOMAXPROCS of the workers")
+	output := flags.String("o", "adversarial.json", "vector file the worst offenders are written to")
+	format := flags.String("format", "markdown", fmt.Sprintf("output format, one of %v", results.Formats))
+	flags.Parse(args)
+
+	searched, err := parseEngines(*engineName)
+	if err != nil {
+		return err
+	}
+	if len(searched) != 1 {
+		return fmt.Errorf("search on one engine, not %q", *engineName)
+	}
+	if config.Revision, err = corpus.ParseRevision(*revisionName); err != nil {
+		return err
+	}
+	if config.Length < 1 || config.MaxLength < config.Length {
+		return fmt.Errorf("invalid program lengths %d and %d", config.Length, config.MaxLength)
+	}
+	selected := searched
+	for _, engine := range engines {
+		if engine.name != searched[0].name {
+			selected = append(selected, engine)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "evmbench-workers")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	clients := make([]*worker.Client, 0, len(selected))
+	defer func() {
+		for _, client := range clients {
+			client.Close()
+		}
+	}()
+	for _, engine := range selected {
+		fmt.Fprintf(os.Stderr, "building %s worker\n", engine.name)
+		binary, err := engine.buildWorker(dir)
+		if err != nil {
+			return fmt.Errorf("%s: failed to build worker: %w", engine.name, err)
+		}
+		client, err := startWorker(binary, config.Revision, *cpu)
+		if err != nil {
+			return fmt.Errorf("%s: failed to start worker: %w", engine.name, err)
+		}
+		clients = append(clients, client)
+	}
+
+	fmt.Fprintf(os.Stderr, "searching %d generations of %d children on %s in %s, seed %d\n",
+		config.Generations, config.Children, searched[0].name, config.Revision, config.Seed)
+	offenders, err := search.Search(config, func(vector corpus.Vector) (search.Measurement, error) {
+		return measureNsPerGas(clients[0], vector, *samples, *batch)
+	}, func(generation int, incumbent search.Offender) {
+		fmt.Fprintf(os.Stderr, "generation %d: %.3f ns/gas, %s\n", generation, incumbent.NsPerGas, incumbent.Program)
+	})
+	if err != nil {
+		return err
+	}
+
+	table := results.Table{Header: []string{"Vector", "Snippets"}}
+	for _, engine := range selected {
+		table.Header = append(table.Header, engine.name+" ns/gas", engine.name+" Mgas/s", engine.name+" block ms")
+	}
+	vectors := make([]corpus.Vector, len(offenders))
+	for i, offender := range offenders {
+		vectors[i] = offender.Vector
+		vectors[i].Name = fmt.Sprintf("adversarial/%s/%d", searched[0].name, i+1)
+		row := []string{vectors[i].Name, offender.Program.String()}
+		for j, client := range clients {
+			measurement := offender.Measurement
+			if j > 0 {
+				if measurement, err = measureNsPerGas(client, vectors[i], *samples, *batch); err != nil {
+					return err
+				}
+			}
+			row = append(row,
+				fmt.Sprintf("%.3f", measurement.NsPerGas),
+				fmt.Sprintf("%.0f", 1e3/measurement.NsPerGas),
+				fmt.Sprintf("%.0f", measurement.NsPerGas*float64(*blockGas)/1e6))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	if err := corpus.WriteVectors(*output, vectors); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d vectors to %s\n", len(vectors), *output)
+	return table.Write(os.Stdout, *format)
+}
+
+// measureNsPerGas loads a vector into a worker and returns the median time
+// of samples batches over the gas the vector used.
+func measureNsPerGas(client *worker.Client, vector corpus.Vector, samples int, batch time.Duration) (search.Measurement, error) {
+	outcome, err := client.Load(vector)
+	if err != nil {
+		return search.Measurement{}, err
+	}
+	if outcome.GasUsed == 0 {
+		return search.Measurement{Outcome: outcome}, nil
+	}
+	iterations, err := calibrate(client, batch)
+	if err != nil {
+		return search.Measurement{}, err
+	}
+	ns := make([]float64, samples)
+	for i := range ns {
+		sample, err := client.Batch(iterations)
+		if err != nil {
+			return search.Measurement{}, err
+		}
+		ns[i] = sample.NsPerOp
+	}
+	return search.Measurement{
+		NsPerGas: results.Summarize(ns).Median / float64(outcome.GasUsed),
+		Outcome:  outcome,
+	}, nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+	"github.com/sonicoperations/evmbench/soak"
+)
+
+func soakCommand(args []string) error {
+	flags := flag.NewFlagSet("soak", flag.ExitOnError)
+	engineList := flags.String("engines", strings.Join(engineNames(), ","), "comma separated engines to soak, one after the other")
+	revision := flags.String("revision", corpus.DefaultRevision.String(), "revision the vectors are executed in")
+	duration := flags.Duration("duration", 10*time.Minute, "duration of the soak per engine")
+	interval := flags.Duration("interval", 5*time.Second, "interval between runtime samples")
+	fresh := flags.Int("fresh", 10, "every n-th execution calls code with a new code hash, 0 for never")
+	output := flags.String("o", "soak", "directory the time series <engine>.ndjson are written to")
+	format := flags.String("format", "markdown", fmt.Sprintf("output format, one of %v", results.Formats))
+	verbose := flags.Bool("v", false, "print the output of go test")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: evmbench soak [flags] [time series files]\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	var paths []string
+	var failed []error
+	if flags.NArg() > 0 {
+		paths = flags.Args()
+	} else {
+		selected, err := parseEngines(*engineList)
+		if err != nil {
+			return err
+		}
+		if _, err := corpus.ParseRevision(*revision); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(*output, 0o755); err != nil {
+			return err
+		}
+		var log io.Writer
+		if *verbose {
+			log = os.Stderr
+		}
+		for _, engine := range selected {
+			path, err := filepath.Abs(filepath.Join(*output, engine.name+".ndjson"))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "soaking %s for %v\n", engine.name, *duration)
+			out, err := engine.goTest([]string{"-run", "^TestSoak$", "-count", "1", "-timeout", "0", "-v"}, []string{
+				"-revision", *revision,
+				"-soak", duration.String(),
+				"-soak-interval", interval.String(),
+				"-soak-fresh", strconv.Itoa(*fresh),
+				"-soak-out", path,
+			}, log)
+			// A failing verdict leaves the series behind for the table.
+			if err != nil {
+				failed = append(failed, fmt.Errorf("%s: %w\n%s", engine.name, err, out))
+			}
+			if _, err := os.Stat(path); err == nil {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	var series [][]soak.Sample
+	for _, path := range paths {
+		samples, err := soak.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		series = append(series, samples)
+	}
+	if err := soak.Summary(series, soak.DefaultLimits).Write(os.Stdout, *format); err != nil {
+		return err
+	}
+	return errors.Join(failed...)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+	"github.com/sonicoperations/evmbench/statetest"
+)
+
+func stateTestCommand(args []string) error {
+	flags := flag.NewFlagSet("statetest", flag.ExitOnError)
+	engineList := flags.String("engines", strings.Join(engineNames(), ","), "comma separated engines to run the state tests on")
+	fixtures := flags.String("fixtures", "", "GeneralStateTests fixture directory or file; the corpus sample if empty")
+	filter := flags.String("filter", "", "regular expression selecting subtests by <test>/<fork>/d<i>g<j>v<k>")
+	out := flags.String("o", "", "write the results of all engines as NDJSON to this file")
+	vectorsOut := flags.String("vectors", "", "write the failed subtests converted to corpus vectors to this JSON file")
+	format := flags.String("format", "markdown", fmt.Sprintf("output format, one of %v", results.Formats))
+	flags.Parse(args)
+
+	selected, err := parseEngines(*engineList)
+	if err != nil {
+		return err
+	}
+	path := *fixtures
+	if path == "" {
+		root, err := corpus.Root()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(root, "statetests")
+	}
+	// go test runs in the engine modules' directories.
+	if path, err = filepath.Abs(path); err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "evmbench-statetest")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// Failures of the corpus sample make go test fail; the results are
+	// written regardless and evaluated below.
+	var res []statetest.Result
+	goFlags := []string{"-count", "1", "-run", "^TestStateTests$"}
+	for _, engine := range selected {
+		resultPath := filepath.Join(dir, engine.name+".ndjson")
+		testFlags := []string{"-statetests", path, "-statetest-filter", *filter, "-statetest-results", resultPath}
+		out, testErr := engine.goTest(goFlags, testFlags, nil)
+		file, err := os.Open(resultPath)
+		if err != nil {
+			return fmt.Errorf("%s: no results written: %w\n%s", engine.name, errors.Join(testErr, err), out)
+		}
+		engineResults, err := statetest.ReadResults(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", engine.name, err)
+		}
+		res = append(res, engineResults...)
+	}
+
+	if *out != "" {
+		if err := statetest.WriteResults(*out, res); err != nil {
+			return err
+		}
+	}
+	failures := 0
+	for _, result := range res {
+		if result.Status == statetest.Fail {
+			fmt.Fprintf(os.Stderr, "%s %s: %s\n", result.Engine, result.Subtest, result.Message)
+			failures++
+		}
+	}
+	if *vectorsOut != "" {
+		tests, err := statetest.Load(path)
+		if err != nil {
+			return err
+		}
+		vectors, errs := statetest.FailedVectors(tests, res)
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "not converted: %v\n", err)
+		}
+		if err := corpus.WriteVectors(*vectorsOut, vectors); err != nil {
+			return err
+		}
+	}
+	if err := statetest.Summary(res).Write(os.Stdout, *format); err != nil {
+		return err
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d failed subtests", failures)
+	}
+	return nil
+}
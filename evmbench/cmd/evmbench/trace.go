@@ -0,0 +1,176 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+	"github.com/sonicoperations/evmbench/trace"
+)
+
+func traceCommand(args []string) error {
+	flags := flag.NewFlagSet("trace", flag.ExitOnError)
+	engineList := flags.String("engines", strings.Join(engineNames(), ","), "comma separated engines to trace")
+	vectorPattern := flags.String("vectors", "", "regular expression selecting the vectors to trace by name")
+	revisionName := flags.String("revision", corpus.DefaultRevision.String(), "revision the vectors are traced in")
+	fixtures := flags.String("statetests", "", "trace the GeneralStateTests of this fixture directory or file instead of vectors")
+	filter := flags.String("filter", "", "regular expression selecting state subtests by <test>/<fork>/d<i>g<j>v<k>")
+	out := flags.String("o", "traces", "directory the traces are written to, one subdirectory per engine")
+	against := flags.String("against", "", "directory of reference traces, e.g. of another client, to diff all engines against")
+	format := flags.String("format", "markdown", fmt.Sprintf("output format, one of %v", results.Formats))
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: evmbench trace [flags]\n       evmbench trace a.jsonl b.jsonl\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	// Two trace files are diffed right away.
+	if flags.NArg() == 2 {
+		return diffTraceFiles(flags.Arg(0), flags.Arg(1))
+	} else if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	selected, err := parseEngines(*engineList)
+	if err != nil {
+		return err
+	}
+	var goFlags, testFlags []string
+	switch {
+	case *fixtures != "":
+		path, err := filepath.Abs(*fixtures)
+		if err != nil {
+			return err
+		}
+		goFlags = []string{"-count", "1", "-run", "^TestStateTests$"}
+		testFlags = []string{"-statetests", path, "-statetest-filter", *filter}
+	case *vectorPattern != "":
+		vectors, err := selectVectors(*vectorPattern, vectorBenchmarks)
+		if err != nil {
+			return err
+		}
+		revision, err := corpus.ParseRevision(*revisionName)
+		if err != nil {
+			return err
+		}
+		goFlags = []string{"-count", "1", "-run", subTestPattern("("+strings.Join(vectorTests, "|")+")", vectors)}
+		testFlags = []string{"-revision", revision.String()}
+	default:
+		return errors.New("select vectors with -vectors or state tests with -statetests")
+	}
+
+	// Failing expectations make go test fail; the traces are written
+	// regardless.
+	dirs := map[string]string{}
+	for _, engine := range selected {
+		dir, err := filepath.Abs(filepath.Join(*out, engine.name))
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		dirs[engine.name] = dir
+		fmt.Fprintf(os.Stderr, "tracing on %s into %s\n", engine.name, dir)
+		out, testErr := engine.goTest(goFlags, append(testFlags, "-traces", dir), nil)
+		if entries, err := os.ReadDir(dir); err != nil || len(entries) == 0 {
+			return fmt.Errorf("%s: no traces written: %w\n%s", engine.name, errors.Join(testErr, err), out)
+		}
+	}
+
+	// Every engine is diffed against the reference traces if given, and
+	// against the first engine otherwise.
+	reference, referenceName := *against, "reference"
+	compared := selected
+	if reference == "" {
+		reference, referenceName = dirs[selected[0].name], selected[0].name
+		compared = selected[1:]
+	}
+	names, err := traceNames(reference)
+	if err != nil {
+		return err
+	}
+	table := results.Table{Header: []string{"Trace", "steps (" + referenceName + ")"}}
+	for _, engine := range compared {
+		table.Header = append(table.Header, engine.name+" vs "+referenceName)
+	}
+	differences := 0
+	for _, name := range names {
+		expected, err := trace.ReadFile(filepath.Join(reference, name))
+		if err != nil {
+			return err
+		}
+		row := []string{strings.TrimSuffix(name, ".jsonl"), fmt.Sprint(len(expected.Steps))}
+		for _, engine := range compared {
+			got, err := trace.ReadFile(filepath.Join(dirs[engine.name], name))
+			switch {
+			case errors.Is(err, os.ErrNotExist):
+				row = append(row, "missing")
+				differences++
+			case err != nil:
+				return err
+			default:
+				if d := trace.Diff(expected, got); d != nil {
+					row = append(row, d.String())
+					differences++
+				} else {
+					row = append(row, "identical")
+				}
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	if err := table.Write(os.Stdout, *format); err != nil {
+		return err
+	}
+	if differences > 0 {
+		return fmt.Errorf("%d traces differ", differences)
+	}
+	return nil
+}
+
+// diffTraceFiles prints the first difference of two trace files.
+func diffTraceFiles(a, b string) error {
+	first, err := trace.ReadFile(a)
+	if err != nil {
+		return err
+	}
+	second, err := trace.ReadFile(b)
+	if err != nil {
+		return err
+	}
+	if d := trace.Diff(first, second); d != nil {
+		return fmt.Errorf("traces differ at %v", d)
+	}
+	fmt.Printf("traces agree on %d steps\n", len(first.Steps))
+	return nil
+}
+
+// traceNames returns the sorted names of the trace files in dir.
+func traceNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, entry := range entries {
+		if name := entry.Name(); strings.HasSuffix(name, ".jsonl") {
+			res = append(res, name)
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
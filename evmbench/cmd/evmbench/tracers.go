@@ -0,0 +1,145 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+)
+
+// tracerLevels are the sub-benchmarks of BenchmarkTracers of any engine,
+// <vector>/<level>. Every engine runs none, without tracer, and its own
+// subset of the others: noop and call on both, struct on BSC, stats and
+// logging on LFVM and json, an EIP-3155 trace, on both.
+var tracerLevels = []string{"none", "noop", "call", "struct", "stats", "logging", "json"}
+
+func tracersCommand(args []string) error {
+	flags := flag.NewFlagSet("tracers", flag.ExitOnError)
+	engineList := flags.String("engines", strings.Join(engineNames(), ","), "comma separated engines to benchmark")
+	vectorPattern := flags.String("vectors", ".", "regular expression selecting contract vectors by name")
+	revisionName := flags.String("revision", corpus.DefaultRevision.String(), "revision the vectors are benchmarked in")
+	count := flags.Int("count", 5, "samples per vector, tracing level and engine")
+	benchtime := flags.String("benchtime", "200ms", "duration or iterations (Nx) per sample")
+	cpu := flags.Int("cpu", runtime.NumCPU(), "GOMAXPROCS of the benchmarks")
+	output := flags.String("o", "", "also write the measured samples to this result file")
+	format := flags.String("format", "markdown", fmt.Sprintf("output format, one of %v", results.Formats))
+	verbose := flags.Bool("v", false, "print the output of go test")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: evmbench tracers [flags] [result files]\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	revision, err := corpus.ParseRevision(*revisionName)
+	if err != nil {
+		return err
+	}
+	res, err := loadOrBenchmark(flags.Args(), *output, func() (*results.Results, error) {
+		selected, err := parseEngines(*engineList)
+		if err != nil {
+			return nil, err
+		}
+		vectors, err := selectVectors(*vectorPattern, []string{"BenchmarkContracts"})
+		if err != nil {
+			return nil, err
+		}
+		hashes := map[string]string{}
+		for _, vector := range vectors {
+			for _, level := range tracerLevels {
+				hashes[vector.Name+"/"+level] = vector.Hash()
+			}
+		}
+		goFlags := []string{
+			"-run", "^$",
+			"-bench", subTestPattern("BenchmarkTracers", vectors),
+			"-benchmem",
+			"-count", strconv.Itoa(*count),
+			"-benchtime", *benchtime,
+			"-cpu", strconv.Itoa(*cpu),
+		}
+		var log io.Writer
+		if *verbose {
+			log = os.Stderr
+		}
+		res := &results.Results{}
+		for _, engine := range selected {
+			base, err := engineRecord(engine, *cpu)
+			if err != nil {
+				return nil, err
+			}
+			base.Revision = revision
+			fmt.Fprintf(os.Stderr, "benchmarking %d vectors with tracers on %s in %s\n", len(vectors), engine.name, revision)
+			if err := benchmarkEngine(res, engine, base, []string{"BenchmarkTracers"}, goFlags, []string{"-revision", revision.String()}, hashes, log); err != nil {
+				return nil, err
+			}
+		}
+		res.Sort()
+		return res, nil
+	})
+	if err != nil {
+		return err
+	}
+	return tracerTable(res, revision).Write(os.Stdout, *format)
+}
+
+// tracerTable lists the slowdown of every tracing level per engine, the
+// median time of a vector at the level over that without tracer. The
+// geometric mean over all vectors summarizes a level; the vector slowed
+// down most is named with its slowdown.
+func tracerTable(res *results.Results, revision corpus.Revision) results.Table {
+	engines := res.Engines()
+	table := results.Table{Header: []string{"Level"}}
+	for _, engine := range engines {
+		table.Header = append(table.Header, engine+" slowdown", engine+" worst")
+	}
+	median := func(engine, vector string) float64 {
+		record, found := res.Lookup(results.Key{Engine: engine, Revision: revision, Vector: vector})
+		if !found {
+			return math.NaN()
+		}
+		return results.Summarize(record.Values("ns/op")).Median
+	}
+	for _, level := range tracerLevels[1:] {
+		row := []string{level}
+		measured := false
+		for _, engine := range engines {
+			logSum, count := 0.0, 0
+			worst, worstRatio := "", 0.0
+			for _, record := range res.Records {
+				vector, found := strings.CutSuffix(record.Vector, "/"+level)
+				if record.Engine != engine || record.Revision != revision || !found {
+					continue
+				}
+				ratio := median(engine, record.Vector) / median(engine, vector+"/none")
+				if math.IsNaN(ratio) || math.IsInf(ratio, 0) || ratio == 0 {
+					continue
+				}
+				logSum += math.Log(ratio)
+				count++
+				if ratio > worstRatio {
+					worst, worstRatio = vector, ratio
+				}
+			}
+			if count == 0 {
+				row = append(row, "-", "-")
+				continue
+			}
+			measured = true
+			row = append(row, fmt.Sprintf("%.2fx", math.Exp(logSum/float64(count))), fmt.Sprintf("%s %.2fx", worst, worstRatio))
+		}
+		if measured {
+			table.Rows = append(table.Rows, row)
+		}
+	}
+	return table
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+)
+
+func verifyCommand(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	engineList := flags.String("engines", strings.Join(engineNames(), ","), "comma separated engines to verify")
+	vectorPattern := flags.String("vectors", ".", "regular expression selecting vectors by name")
+	revisionList := flags.String("revisions", corpus.DefaultRevision.String(), "comma separated revisions to verify")
+	format := flags.String("format", "markdown", fmt.Sprintf("output format, one of %v", results.Formats))
+	flags.Parse(args)
+
+	selected, err := parseEngines(*engineList)
+	if err != nil {
+		return err
+	}
+	revisions, err := parseRevisions(*revisionList)
+	if err != nil {
+		return err
+	}
+	vectors, err := selectVectors(*vectorPattern, vectorBenchmarks)
+	if err != nil {
+		return err
+	}
+	// The tests skip the deployments before corpus.DeploymentsSince.
+	root, err := corpus.Root()
+	if err != nil {
+		return err
+	}
+	deployments, err := corpus.LoadDeployments(root)
+	if err != nil {
+		return err
+	}
+	gated := map[string]bool{}
+	for _, vector := range deployments {
+		gated[vector.Name] = true
+	}
+	dir, err := os.MkdirTemp("", "evmbench-verify")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// Failing expectations make go test fail; the outcomes are written
+	// regardless and evaluated below. The failures of go test are returned
+	// after the table, so that a test binary crashing after writing some
+	// outcomes fails verify even if those agree.
+	observed := map[results.Key]corpus.Outcome{}
+	var testErrs []error
+	goFlags := []string{"-count", "1", "-run", subTestPattern("("+strings.Join(vectorTests, "|")+")", vectors)}
+	for _, engine := range selected {
+		for _, revision := range revisions {
+			path := filepath.Join(dir, fmt.Sprintf("%s-%s.ndjson", engine.name, revision))
+			out, testErr := engine.goTest(goFlags, []string{"-revision", revision.String(), "-outcomes", path}, nil)
+			file, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("%s: no outcomes written: %w\n%s", engine.name, errors.Join(testErr, err), out)
+			}
+			observations, err := results.ReadObservations(file)
+			file.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %w", engine.name, err)
+			}
+			for _, observation := range observations {
+				observed[observation.Key] = observation.Outcome
+			}
+			if testErr != nil {
+				testErrs = append(testErrs, fmt.Errorf("%s in %s: go test: %w", engine.name, revision, testErr))
+			}
+		}
+	}
+
+	table := results.Table{Header: []string{"Vector", "Revision"}}
+	for _, engine := range selected {
+		table.Header = append(table.Header, engine.name)
+	}
+	table.Header = append(table.Header, "engines agree")
+	failures := 0
+	for _, vector := range vectors {
+		for _, revision := range revisions {
+			row := []string{vector.Name, revision.String()}
+			if gated[vector.Name] && revision < corpus.DeploymentsSince {
+				for range selected {
+					row = append(row, "skipped")
+				}
+				table.Rows = append(table.Rows, append(row, "-"))
+				continue
+			}
+			var first *corpus.Outcome
+			agree := true
+			for _, engine := range selected {
+				outcome, found := observed[results.Key{Engine: engine.name, Revision: revision, Vector: vector.Name}]
+				switch {
+				case !found:
+					row = append(row, "missing")
+					agree = false
+					failures++
+					continue
+				case vector.Expect == nil:
+					row = append(row, fmt.Sprintf("%s, %d gas", outcome.Status, outcome.GasUsed))
+				case vector.Expect.Check(outcome) != nil:
+					row = append(row, "FAIL: "+strings.ReplaceAll(vector.Expect.Check(outcome).Error(), "\n", "; "))
+					failures++
+				default:
+					row = append(row, fmt.Sprintf("ok, %d gas", outcome.GasUsed))
+				}
+				if first == nil {
+					first = &outcome
+				} else if first.Check(outcome) != nil || first.GasUsed != outcome.GasUsed || !corpus.EqualLogs(first.Logs, outcome.Logs) {
+					agree = false
+				}
+			}
+			if agree {
+				row = append(row, "yes")
+			} else {
+				row = append(row, "NO")
+				failures++
+			}
+			table.Rows = append(table.Rows, row)
+		}
+	}
+	if err := table.Write(os.Stdout, *format); err != nil {
+		return err
+	}
+	if failures > 0 {
+		testErrs = append([]error{fmt.Errorf("%d mismatches", failures)}, testErrs...)
+	}
+	return errors.Join(testErrs...)
+}
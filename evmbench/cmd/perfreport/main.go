@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Command perfreport prints the hardware performance counters recorded by
+// the engine benchmarks with -perf-out side by side as Markdown:
+//
+//	perfreport lfvm.ndjson bsc.ndjson
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/sonicoperations/evmbench/perf"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: perfreport <records.ndjson>...\n")
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var records []perf.Record
+	for _, path := range flag.Args() {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		read, err := perf.ReadRecords(file)
+		file.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+		records = append(records, read...)
+	}
+	if err := perf.WriteComparison(os.Stdout, records); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Package conformance describes single interpreter states in engine neutral
+// terms, so that the states generated from Tosca's conformance test
+// specification (go/ct) can be executed on engines that cannot link Tosca.
+// A State is the input of a step request to an engine worker and, after
+// executing a number of instructions, its result.
+//
+// A State covers what an interpreter observes while executing instructions
+// of a single call frame: the frame itself, the storage and transient
+// storage of the executing account, the balances, code and warm set of
+// accounts, logs, the block and transaction environment and self-destructs.
+// Nested calls and contract creations are resolved from a journal in the
+// specification and are not part of a State.
+package conformance
+
+import "github.com/sonicoperations/evmbench/corpus"
+
+// Status is the execution status of a State.
+type Status string
+
+const (
+	Running  Status = "running"
+	Stopped  Status = "stopped" // STOP, RETURN or SELFDESTRUCT
+	Reverted Status = "reverted"
+	Failed   Status = "failed"
+)
+
+// Account is an account other than the executing one as seen by balance
+// and code inspecting instructions.
+type Account struct {
+	Balance corpus.Hash  `json:"balance"`
+	Code    corpus.Bytes `json:"code,omitempty"`
+}
+
+// Slot is a storage slot of the executing account.
+type Slot struct {
+	Current  corpus.Hash `json:"current"`
+	Original corpus.Hash `json:"original"` // value at the start of the transaction
+	Warm     bool        `json:"warm,omitempty"`
+}
+
+// Log is a log emitted by the executing account.
+type Log struct {
+	Topics []corpus.Hash `json:"topics,omitempty"`
+	Data   corpus.Bytes  `json:"data,omitempty"`
+}
+
+// SelfDestruct records a SELFDESTRUCT of an account.
+type SelfDestruct struct {
+	Account     corpus.Address `json:"account"`
+	Beneficiary corpus.Address `json:"beneficiary"`
+}
+
+// Block is the block and transaction environment.
+type Block struct {
+	ChainID     corpus.Hash    `json:"chainId"`
+	Number      uint64         `json:"number"`
+	Timestamp   uint64         `json:"timestamp"`
+	Coinbase    corpus.Address `json:"coinbase"`
+	GasLimit    uint64         `json:"gasLimit"`
+	GasPrice    corpus.Hash    `json:"gasPrice"`
+	PrevRandao  corpus.Hash    `json:"prevRandao"` // the difficulty before Paris
+	BaseFee     corpus.Hash    `json:"baseFee"`
+	BlobBaseFee corpus.Hash    `json:"blobBaseFee"`
+	// RecentHashes holds the hashes of the 256 preceding blocks, the most
+	// recent first.
+	RecentHashes []corpus.Hash  `json:"recentHashes,omitempty"`
+	Origin       corpus.Address `json:"origin"`
+	BlobHashes   []corpus.Hash  `json:"blobHashes,omitempty"`
+}
+
+// State is the state of an interpreter between two instructions.
+type State struct {
+	Status   Status          `json:"status"`
+	Revision corpus.Revision `json:"revision"`
+	ReadOnly bool            `json:"readOnly,omitempty"`
+	Pc       uint64          `json:"pc"`
+	Gas      int64           `json:"gas"`
+	Refund   int64           `json:"refund"` // may be negative within a call frame
+	Code     corpus.Bytes    `json:"code"`
+	Stack    []corpus.Hash   `json:"stack,omitempty"` // bottom first
+	Memory   corpus.Bytes    `json:"memory,omitempty"`
+
+	Address  corpus.Address `json:"address"` // executing account
+	Caller   corpus.Address `json:"caller"`
+	Value    corpus.Hash    `json:"value"`
+	CallData corpus.Bytes   `json:"callData,omitempty"`
+	// LastCallReturnData is the output of the last nested call, as read by
+	// RETURNDATASIZE and RETURNDATACOPY; ReturnData is the output of a
+	// stopped or reverted state.
+	LastCallReturnData corpus.Bytes `json:"lastCallReturnData,omitempty"`
+	ReturnData         corpus.Bytes `json:"returnData,omitempty"`
+
+	Storage      map[corpus.Hash]Slot        `json:"storage,omitempty"`
+	Transient    map[corpus.Hash]corpus.Hash `json:"transient,omitempty"`
+	Accounts     map[corpus.Address]Account  `json:"accounts,omitempty"`
+	WarmAccounts map[corpus.Address]bool     `json:"warmAccounts,omitempty"`
+	Logs         []Log                       `json:"logs,omitempty"`
+
+	HasSelfDestructed bool           `json:"hasSelfDestructed,omitempty"`
+	SelfDestructs     []SelfDestruct `json:"selfDestructs,omitempty"`
+
+	Block Block `json:"block"`
+}
+
+// BlockHash returns the hash of a block as seen by BLOCKHASH: that of one
+// of the 256 blocks preceding the current one, or zero.
+func (s *State) BlockHash(number uint64) corpus.Hash {
+	current := s.Block.Number
+	if number >= current || current-number > uint64(len(s.Block.RecentHashes)) {
+		return corpus.Hash{}
+	}
+	return s.Block.RecentHashes[current-number-1]
+}
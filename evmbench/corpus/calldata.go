@@ -0,0 +1,166 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package corpus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/sonicoperations/evmbench/asm"
+	"golang.org/x/crypto/sha3"
+)
+
+// calldataSizes are the sizes of the input or output of the calldata
+// families.
+var calldataSizes = []struct {
+	name string
+	size int
+}{{"0", 0}, {"1k", 1 << 10}, {"4k", 4 << 10}, {"32k", 32 << 10}, {"128k", 128 << 10}}
+
+// sumSignature is the function decoded by the calldata-abi-array family.
+const sumSignature = "sum(uint256[])"
+
+// CalldataFamilies returns the families passing 0 to 128 KiB of input into
+// an engine or returning as much output: doing nothing with the input,
+// reading it word by word with CALLDATALOAD, copying it into memory,
+// decoding it as a dynamic uint256 array, echoing it and returning zeros.
+// Their time per byte is the overhead of the input and output of a call.
+func CalldataFamilies() []Family {
+	return []Family{
+		calldataFamily("calldata-stop", "input ignored by STOP", pattern, func(input []byte) (string, Outcome) {
+			return "STOP", Outcome{Status: Success}
+		}),
+		calldataFamily("calldata-load", "input XORed word by word with CALLDATALOAD", pattern, func(input []byte) (string, Outcome) {
+			words := (len(input) + 31) / 32
+			padded := make([]byte, 32*words)
+			copy(padded, input)
+			xor := make([]byte, 32)
+			for i, b := range padded {
+				xor[i%32] ^= b
+			}
+			return `
+				PUSH1 0 PUSH1 0
+				loop: JUMPDEST
+				DUP1 CALLDATASIZE GT ISZERO PUSH @done JUMPI
+				DUP1 CALLDATALOAD DUP3 XOR SWAP2 POP
+				PUSH1 32 ADD PUSH @loop JUMP
+				done: JUMPDEST POP PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+			`, Outcome{Status: Success, Output: xor, GasUsed: 49 + 59*uint64(words)}
+		}),
+		calldataFamily("calldata-copy", "input copied into memory with CALLDATACOPY", pattern, func(input []byte) (string, Outcome) {
+			first := make([]byte, 32)
+			copy(first, input)
+			return `
+				CALLDATASIZE PUSH1 0 PUSH1 0 CALLDATACOPY
+				PUSH1 0 MLOAD PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+			`, Outcome{Status: Success, Output: first, GasUsed: 29 + 3*uint64(len(input)/32) + MemoryGas(max(len(input), 32))}
+		}),
+		calldataFamily("calldata-abi-array", "input decoded as the uint256 array of "+sumSignature, sumInput, func(input []byte) (string, Outcome) {
+			elements := (len(input) - 4 - 64) / 32
+			sum := big.NewInt(int64(elements * (elements + 1) / 2))
+			return fmt.Sprintf(`
+				PUSH1 4 CALLDATASIZE LT PUSH @fail JUMPI
+				PUSH1 0 CALLDATALOAD PUSH1 0xe0 SHR PUSH4 %s EQ ISZERO PUSH @fail JUMPI
+				PUSH1 4 CALLDATALOAD PUSH1 4 ADD
+				DUP1 CALLDATALOAD PUSH1 5 SHL
+				SWAP1 PUSH1 32 ADD SWAP1 DUP2 ADD
+				DUP1 CALLDATASIZE LT PUSH @fail JUMPI
+				PUSH1 0 SWAP2
+				loop: JUMPDEST
+				DUP2 DUP2 LT ISZERO PUSH @done JUMPI
+				DUP1 CALLDATALOAD DUP4 ADD SWAP3 POP
+				PUSH1 32 ADD PUSH @loop JUMP
+				done: JUMPDEST POP POP PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+				fail: JUMPDEST PUSH1 0 PUSH1 0 REVERT
+			`, Bytes(input[:4])), Outcome{Status: Success, Output: sum.FillBytes(make([]byte, 32)), GasUsed: 170 + 60*uint64(elements)}
+		}),
+		calldataFamily("calldata-echo", "input copied into memory and returned", pattern, func(input []byte) (string, Outcome) {
+			return `
+				CALLDATASIZE PUSH1 0 PUSH1 0 CALLDATACOPY
+				CALLDATASIZE PUSH1 0 RETURN
+			`, Outcome{Status: Success, Output: input, GasUsed: 16 + 3*uint64(len(input)/32) + MemoryGas(len(input))}
+		}),
+		returnFamily(),
+	}
+}
+
+// calldataFamily builds vectors of the calldata sizes with the input
+// returned by input for the size, executing the code returned by program
+// for the input with the outcome it returns.
+func calldataFamily(name, description string, input func(size int) []byte, program func(input []byte) (string, Outcome)) Family {
+	family := Family{
+		Name:        name,
+		Description: fmt.Sprintf("%s, 0 to 128 KiB", description),
+		Unit:        "byte",
+		Since:       Istanbul,
+	}
+	for _, size := range calldataSizes {
+		input := input(size.size)
+		source, outcome := program(input)
+		family.Members = append(family.Members, Member{
+			Vector: Vector{
+				Name:   name + "/" + size.name,
+				Code:   asm.MustAssemble(source),
+				Input:  input,
+				Gas:    1_000_000,
+				Expect: &outcome,
+			},
+			X: len(input),
+		})
+	}
+	return family
+}
+
+// pattern returns size bytes of a repeating pattern without zeros.
+func pattern(size int) []byte {
+	res := make([]byte, size)
+	for i := range res {
+		res[i] = byte(i%255 + 1)
+	}
+	return res
+}
+
+// sumInput returns the ABI encoded input of a call of sumSignature with as
+// many elements 1, 2, 3, ... as fit into size bytes, at least none.
+func sumInput(size int) []byte {
+	n := max(size-4-64, 0) / 32
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write([]byte(sumSignature))
+	input := hasher.Sum(nil)[:4]
+	input = append(input, make([]byte, 64+32*n)...)
+	binary.BigEndian.PutUint64(input[4+24:], 32) // offset of the array
+	binary.BigEndian.PutUint64(input[4+56:], uint64(n))
+	for i := range n {
+		binary.BigEndian.PutUint64(input[4+64+32*i+24:], uint64(i+1))
+	}
+	return input
+}
+
+// returnFamily builds vectors returning 0 to 128 KiB of zeros from freshly
+// expanded memory.
+func returnFamily() Family {
+	family := Family{
+		Name:        "return",
+		Description: "RETURN of 0 to 128 KiB of zeros",
+		Unit:        "byte",
+		Since:       Istanbul,
+	}
+	for _, size := range calldataSizes {
+		family.Members = append(family.Members, Member{
+			Vector: Vector{
+				Name: "return/" + size.name,
+				Code: asm.MustAssemble(fmt.Sprintf("PUSH4 %d PUSH1 0 RETURN", size.size)),
+				Gas:  1_000_000,
+				Expect: &Outcome{
+					Status:  Success,
+					Output:  make([]byte, size.size),
+					GasUsed: 6 + MemoryGas(size.size),
+				},
+			},
+			X: size.size,
+		})
+	}
+	return family
+}
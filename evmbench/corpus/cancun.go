@@ -0,0 +1,181 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package corpus
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/asm"
+)
+
+// CancunFamilies returns the families covering the opcodes of Cancun next
+// to their predecessors: reentrancy locks in transient storage against
+// locks in storage, MCOPY against word by word MLOAD/MSTORE loops, and
+// BLOBHASH and BLOBBASEFEE.
+func CancunFamilies() []Family {
+	return []Family{
+		lockFamily("lock-tstore", "TLOAD/TSTORE", Cancun, 328),
+		lockFamily("lock-sstore", "SLOAD/SSTORE", Berlin, 20228),
+		copyFamily("copy-mcopy", "MCOPY", Cancun, func(size int) string {
+			return fmt.Sprintf("PUSH2 %d PUSH1 0 PUSH2 %d MCOPY", size, size)
+		}),
+		copyFamily("copy-mload-mstore", "MLOAD/MSTORE loop", Istanbul, func(size int) string {
+			return fmt.Sprintf(`
+				PUSH2 %d
+				loop: JUMPDEST
+				DUP1 ISZERO PUSH @done JUMPI
+				PUSH1 32 SWAP1 SUB
+				DUP1 MLOAD DUP2 PUSH2 %d ADD MSTORE
+				PUSH @loop JUMP
+				done: JUMPDEST POP
+			`, size, size)
+		}),
+		blobFamily(),
+	}
+}
+
+// lockRepetitions are the numbers of guarded sections of the lock families.
+var lockRepetitions = []int{4, 16, 64, 256}
+
+// lockFamily builds vectors acquiring and releasing a reentrancy lock, kept
+// in slot 0 of the storage accessed by the load and store opcodes of kind,
+// once per guarded section. Finally they call themselves while holding the
+// lock; the nested call finds it taken and reverts, and the vectors return
+// the failed call's status 0.
+func lockFamily(name, kind string, since Revision, gas uint64) Family {
+	load, store, _ := strings.Cut(kind, "/")
+	acquire := fmt.Sprintf("PUSH1 0 %s PUSH @locked JUMPI PUSH1 1 PUSH1 0 %s", load, store)
+	release := fmt.Sprintf("PUSH1 0 PUSH1 0 %s", store)
+	family := Family{
+		Name:        name,
+		Description: fmt.Sprintf("reentrancy lock in %s acquired and released 4 to 256 times, then reentered", kind),
+		Unit:        "section",
+		Gas:         gas,
+		Since:       since,
+	}
+	for _, repetitions := range lockRepetitions {
+		source := []string{"CALLDATASIZE PUSH @reentered JUMPI"}
+		for range repetitions {
+			source = append(source, acquire, release)
+		}
+		source = append(source,
+			acquire,
+			"PUSH1 0 PUSH1 0 PUSH1 1 PUSH1 0 PUSH1 0 ADDRESS GAS CALL", // reenter with one byte of input
+			release,
+			"PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN",
+			"reentered: JUMPDEST PUSH1 0 "+load+" PUSH @locked JUMPI STOP",
+			"locked: JUMPDEST PUSH1 0 PUSH1 0 REVERT",
+		)
+		family.Members = append(family.Members, Member{
+			Vector: Vector{
+				Name:   fmt.Sprintf("%s/%d", name, repetitions),
+				Code:   asm.MustAssemble(strings.Join(source, "\n")),
+				Gas:    10_000_000,
+				Expect: &Outcome{Status: Success, Output: make([]byte, 32)},
+			},
+			X: repetitions,
+		})
+	}
+	return family
+}
+
+// copySizes are the numbers of bytes copied by the copy families.
+var copySizes = []struct {
+	name string
+	size int
+}{{"32", 32}, {"256", 256}, {"1k", 1 << 10}, {"4k", 4 << 10}, {"16k", 16 << 10}}
+
+// copyFamily builds vectors copying the first size bytes of memory behind
+// them with the code returned by copyCode. Memory is expanded to both
+// regions in advance, so the copy does not pay for expansion. The source is
+// marked at its first and last word and the vectors return these words of
+// the copy.
+func copyFamily(name, description string, since Revision, copyCode func(size int) string) Family {
+	first, last := operandA, "0x"+strings.Repeat("3c", 32)
+	family := Family{
+		Name:        name,
+		Description: fmt.Sprintf("%s of 32 bytes to 16 KiB", description),
+		Unit:        "byte",
+		Since:       since,
+	}
+	for _, member := range copySizes {
+		size := member.size
+		source := fmt.Sprintf(`
+			PUSH1 0 PUSH2 %d MSTORE8
+			PUSH32 %s PUSH1 0 MSTORE
+			PUSH32 %s PUSH2 %d MSTORE
+			%s
+			PUSH2 %d MLOAD PUSH1 0 MSTORE
+			PUSH2 %d MLOAD PUSH1 32 MSTORE
+			PUSH1 64 PUSH1 0 RETURN
+		`, 2*size-1, first, last, size-32, copyCode(size), size, 2*size-32)
+		output := append(wordOf(first), wordOf(last)...)
+		if size == 32 {
+			output = append(wordOf(last), wordOf(last)...)
+		}
+		family.Members = append(family.Members, Member{
+			Vector: Vector{
+				Name:   name + "/" + member.name,
+				Code:   asm.MustAssemble(source),
+				Gas:    1_000_000,
+				Expect: &Outcome{Status: Success, Output: output},
+			},
+			X: size,
+		})
+	}
+	return family
+}
+
+// blobFamily builds vectors reading the blob hashes, including one beyond
+// the blobs of the transaction, and the blob base fee 16 to 256 times. They
+// return the three hashes and the fee.
+func blobFamily() Family {
+	family := Family{
+		Name:        "blobhash",
+		Description: "BLOBHASH and BLOBBASEFEE repeated 16 to 256 times",
+		Unit:        "repetition",
+		Gas:         3 + 3 + 2 + 2 + 2,
+		Since:       Cancun,
+	}
+	var output []byte
+	for i := range len(BlobHashes) + 1 {
+		var hash Hash
+		if i < len(BlobHashes) {
+			hash = BlobHashes[i]
+		}
+		output = append(output, hash[:]...)
+	}
+	output = append(output, wordOf(fmt.Sprint(BlobBaseFee))...)
+	for _, repetitions := range pricingRepetitions {
+		source := []string{}
+		for i := range repetitions {
+			source = append(source, fmt.Sprintf("PUSH1 %d BLOBHASH POP BLOBBASEFEE POP", i%(len(BlobHashes)+1)))
+		}
+		for i := range len(BlobHashes) + 1 {
+			source = append(source, fmt.Sprintf("PUSH1 %d BLOBHASH PUSH1 %d MSTORE", i, 32*i))
+		}
+		source = append(source, fmt.Sprintf("BLOBBASEFEE PUSH1 %d MSTORE PUSH1 %d PUSH1 0 RETURN", 32*(len(BlobHashes)+1), len(output)))
+		family.Members = append(family.Members, Member{
+			Vector: Vector{
+				Name:   fmt.Sprintf("blobhash/%d", repetitions),
+				Code:   asm.MustAssemble(strings.Join(source, "\n")),
+				Gas:    1_000_000,
+				Expect: &Outcome{Status: Success, Output: output},
+			},
+			X: repetitions,
+		})
+	}
+	return family
+}
+
+// wordOf returns a decimal or 0x prefixed hex value as a 32 byte word.
+func wordOf(value string) []byte {
+	n, ok := new(big.Int).SetString(value, 0)
+	if !ok {
+		panic(fmt.Sprintf("invalid value %q", value))
+	}
+	return n.FillBytes(make([]byte, 32))
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package corpus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Contract is a compiled workload contract. Each contract lives in its own
+// directory below corpus/contracts containing
+//
+//	<name>.sol    the Solidity source
+//	runtime.hex   the runtime bytecode compiled by solc
+//	creation.hex  the creation bytecode compiled by solc
+//	abi.json      the ABI output by solc
+//	calls.json    the function calls with their expected outcomes
+type Contract struct {
+	Name     string
+	Runtime  Bytes
+	Creation Bytes
+	ABI      json.RawMessage
+	Calls    []Call
+}
+
+// Call is a single function call on a workload contract.
+type Call struct {
+	Name     string        `json:"name"`
+	Function string        `json:"function"` // canonical signature, e.g. "transfer(address,uint256)"
+	Input    Bytes         `json:"input"`
+	Gas      uint64        `json:"gas"`
+	Storage  map[Hash]Hash `json:"storage,omitempty"`
+	Expect   Outcome       `json:"expect"`
+}
+
+// Vector returns the call as a corpus vector named <contract>/<call>.
+func (c *Contract) Vector(call Call) Vector {
+	expect := call.Expect
+	return Vector{
+		Name:    c.Name + "/" + call.Name,
+		Code:    c.Runtime,
+		Input:   call.Input,
+		Gas:     call.Gas,
+		Storage: call.Storage,
+		Expect:  &expect,
+	}
+}
+
+// Vectors returns all calls of the contract as vectors.
+func (c *Contract) Vectors() []Vector {
+	res := make([]Vector, 0, len(c.Calls))
+	for _, call := range c.Calls {
+		res = append(res, c.Vector(call))
+	}
+	return res
+}
+
+// Call looks up a call by name.
+func (c *Contract) Call(name string) (Call, bool) {
+	for _, call := range c.Calls {
+		if call.Name == name {
+			return call, true
+		}
+	}
+	return Call{}, false
+}
+
+// LoadContracts loads all workload contracts of the corpus rooted at root,
+// sorted by name.
+func LoadContracts(root string) ([]Contract, error) {
+	entries, err := os.ReadDir(filepath.Join(root, "contracts"))
+	if err != nil {
+		return nil, err
+	}
+	var res []Contract
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		contract, err := LoadContract(root, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, contract)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// LoadContract loads a single workload contract of the corpus rooted at root.
+func LoadContract(root, name string) (Contract, error) {
+	dir := filepath.Join(root, "contracts", name)
+	runtime, err := os.ReadFile(filepath.Join(dir, "runtime.hex"))
+	if err != nil {
+		return Contract{}, err
+	}
+	creation, err := os.ReadFile(filepath.Join(dir, "creation.hex"))
+	if err != nil {
+		return Contract{}, err
+	}
+	contract := Contract{Name: name}
+	if err := contract.Runtime.UnmarshalText([]byte(strings.TrimSpace(string(runtime)))); err != nil {
+		return Contract{}, fmt.Errorf("%s: runtime.hex: %w", name, err)
+	}
+	if err := contract.Creation.UnmarshalText([]byte(strings.TrimSpace(string(creation)))); err != nil {
+		return Contract{}, fmt.Errorf("%s: creation.hex: %w", name, err)
+	}
+	if contract.ABI, err = os.ReadFile(filepath.Join(dir, "abi.json")); err != nil {
+		return Contract{}, err
+	}
+	calls, err := os.ReadFile(filepath.Join(dir, "calls.json"))
+	if err != nil {
+		return Contract{}, err
+	}
+	if err := json.Unmarshal(calls, &contract.Calls); err != nil {
+		return Contract{}, fmt.Errorf("%s: calls.json: %w", name, err)
+	}
+	return contract, nil
+}
+
+// LoadContractFromRoot is a convenience wrapper locating the corpus with
+// Root before loading the named contract.
+func LoadContractFromRoot(name string) (Contract, error) {
+	root, err := Root()
+	if err != nil {
+		return Contract{}, err
+	}
+	return LoadContract(root, name)
+}
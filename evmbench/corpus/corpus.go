@@ -0,0 +1,244 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Package corpus defines the engine neutral description of benchmark
+// workloads and loads the fixtures checked in under the repository's
+// corpus directory. Engine specific modules turn these descriptions into
+// BSC or Tosca runs.
+package corpus
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+//go:generate go run ../cmd/corpusgen -dir ../../corpus
+
+// Bytes is a byte slice encoded as 0x-prefixed hex in JSON.
+type Bytes []byte
+
+func (b Bytes) String() string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+func (b Bytes) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+func (b *Bytes) UnmarshalText(text []byte) error {
+	s := strings.TrimPrefix(string(text), "0x")
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid hex data: %w", err)
+	}
+	*b = raw
+	return nil
+}
+
+// Address is a 20 byte account address.
+type Address [20]byte
+
+func (a Address) String() string {
+	return "0x" + hex.EncodeToString(a[:])
+}
+
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Address) UnmarshalText(text []byte) error {
+	return unmarshalFixed(a[:], text)
+}
+
+// Hash is a 32 byte word used for storage keys, values and hashes.
+type Hash [32]byte
+
+func (h Hash) String() string {
+	return "0x" + hex.EncodeToString(h[:])
+}
+
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *Hash) UnmarshalText(text []byte) error {
+	return unmarshalFixed(h[:], text)
+}
+
+// unmarshalFixed decodes hex text into trg, left padding short values.
+func unmarshalFixed(trg []byte, text []byte) error {
+	s := strings.TrimPrefix(string(text), "0x")
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid hex data: %w", err)
+	}
+	if len(raw) > len(trg) {
+		return fmt.Errorf("value %s exceeds %d bytes", text, len(trg))
+	}
+	clear(trg)
+	copy(trg[len(trg)-len(raw):], raw)
+	return nil
+}
+
+// The accounts every vector is executed with. They match the caller and
+// contract addresses the interpreter benchmarks have always used.
+var (
+	DefaultCaller    = Address{0x10, 19: 0x01} // 0x1000000000000000000000000000000000000001
+	DefaultRecipient = Address{0x01}           // 0x0100000000000000000000000000000000000000
+)
+
+// The block environment every vector is executed in. Block hashes are
+// zero on both engines.
+const (
+	ChainID       = 97 // BSC testnet
+	BlockNumber   = 1
+	Timestamp     = 1681338455
+	BlockGasLimit = 10_000_000_000
+	BlobBaseFee   = 1 // minimum of EIP-4844, from Cancun
+)
+
+// BlobHashes are the versioned hashes of the blobs of the transaction every
+// vector is executed in from Cancun, read by BLOBHASH.
+var BlobHashes = []Hash{
+	{0x01, 30: 0xb1, 31: 0x01},
+	{0x01, 30: 0xb1, 31: 0x02},
+}
+
+// Status summarizes how an execution ended.
+type Status string
+
+const (
+	Success Status = "success" // STOP, RETURN or end of code
+	Revert  Status = "revert"  // REVERT, remaining gas is returned
+	Failure Status = "failure" // exceptional halt, all gas is consumed
+)
+
+// Outcome is the observable result of executing a vector.
+type Outcome struct {
+	Status  Status `json:"status"`
+	Output  Bytes  `json:"output,omitempty"`
+	GasUsed uint64 `json:"gasUsed,omitempty"`
+	Logs    []Log  `json:"logs,omitempty"` // of successful executions only
+}
+
+// Log is a log emitted by a vector, by DefaultRecipient or a contract it
+// created or called. The emitting address is not compared.
+type Log struct {
+	Topics []Hash `json:"topics,omitempty"`
+	Data   Bytes  `json:"data,omitempty"`
+}
+
+// Equal reports whether two logs have the same topics and data.
+func (l Log) Equal(other Log) bool {
+	return slices.Equal(l.Topics, other.Topics) && bytes.Equal(l.Data, other.Data)
+}
+
+// EqualLogs reports whether two lists of logs are the same.
+func EqualLogs(a, b []Log) bool {
+	return slices.EqualFunc(a, b, Log.Equal)
+}
+
+// Check compares an actual outcome against the expected one. The gas usage
+// and the logs are only compared if the expectation defines them.
+func (o Outcome) Check(got Outcome) error {
+	var errs []error
+	if o.Status != got.Status {
+		errs = append(errs, fmt.Errorf("status: want %s, got %s", o.Status, got.Status))
+	}
+	if !bytes.Equal(o.Output, got.Output) {
+		errs = append(errs, fmt.Errorf("output: want %v, got %v", o.Output, got.Output))
+	}
+	if o.GasUsed != 0 && o.GasUsed != got.GasUsed {
+		errs = append(errs, fmt.Errorf("gas used: want %d, got %d", o.GasUsed, got.GasUsed))
+	}
+	if o.Logs != nil && !EqualLogs(o.Logs, got.Logs) {
+		errs = append(errs, fmt.Errorf("logs: want %v, got %v", o.Logs, got.Logs))
+	}
+	return errors.Join(errs...)
+}
+
+// Vector is a single piece of code executed with a given input and gas
+// limit as a call from DefaultCaller to DefaultRecipient.
+type Vector struct {
+	Name    string        `json:"name"`
+	Code    Bytes         `json:"code"`
+	Input   Bytes         `json:"input,omitempty"`
+	Gas     uint64        `json:"gas"`
+	Storage map[Hash]Hash `json:"storage,omitempty"` // pre-state of the recipient
+	Expect  *Outcome      `json:"expect,omitempty"`
+}
+
+// Hash returns a hex encoded SHA-256 digest of everything determining the
+// execution of the vector: code, input, gas and pre-state. The name and
+// the expected outcome are excluded.
+func (v Vector) Hash() string {
+	data, err := json.Marshal(struct {
+		Code    Bytes
+		Input   Bytes
+		Gas     uint64
+		Storage map[Hash]Hash
+	}{v.Code, v.Input, v.Gas, v.Storage})
+	if err != nil {
+		panic(err) // all fields are plain data
+	}
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:])
+}
+
+// Root locates the corpus directory. EVMBENCH_CORPUS takes precedence;
+// otherwise the working directory and its parents are searched, which
+// makes the corpus reachable from every module in the repository.
+func Root() (string, error) {
+	if dir := os.Getenv("EVMBENCH_CORPUS"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		candidate := filepath.Join(dir, "corpus")
+		if info, err := os.Stat(filepath.Join(candidate, "contracts")); err == nil && info.IsDir() {
+			return candidate, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("corpus directory not found, set EVMBENCH_CORPUS")
+		}
+		dir = parent
+	}
+}
+
+// ReadVectors loads a JSON array of vectors, e.g. vectors converted from
+// state tests.
+func ReadVectors(path string) ([]Vector, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var res []Vector
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return res, nil
+}
+
+// WriteVectors stores vectors as a JSON array.
+func WriteVectors(path string, vectors []Vector) error {
+	data, err := json.MarshalIndent(vectors, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
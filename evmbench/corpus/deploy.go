@@ -0,0 +1,321 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package corpus
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/asm"
+	"golang.org/x/crypto/sha3"
+)
+
+// Limits of EIP-170 and EIP-3860 on the size of deployed code and initcode.
+const (
+	MaxCodeSize     = 24576
+	MaxInitCodeSize = 2 * MaxCodeSize
+)
+
+// deployGas is the gas of the deployment vectors, enough to deploy the
+// largest code.
+const deployGas = 10_000_000
+
+// deploySizes are the sizes of the code deployed by the members of the
+// deploy families, up to the limit of EIP-170.
+var deploySizes = []struct {
+	name string
+	size int
+}{{"0", 0}, {"1k", 1 << 10}, {"4k", 4 << 10}, {"16k", 16 << 10}, {"24k", MaxCodeSize}}
+
+// deploySalt is the salt of all CREATE2 deployments.
+var deploySalt = Hash{31: 0x2a}
+
+// bep20Supply is the word of the total supply minted by the BEP20
+// constructor, 30 million tokens of 18 decimals.
+var bep20Supply = new(big.Int).Mul(big.NewInt(30_000_000), new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)).FillBytes(make([]byte, 32))
+
+// DeployFamilies returns the families deploying 0 bytes to 24 KiB of code
+// with CREATE and CREATE2 from a factory at DefaultRecipient, which takes
+// the initcode as input and returns the created address, its code hash and
+// its code size. The initcode copies the code to memory and returns it, so
+// the time per byte is that of the initcode metering of EIP-3860, the
+// deposit of the code and, for CREATE2, the hashing of the initcode.
+func DeployFamilies() []Family {
+	return []Family{deployFamily("CREATE"), deployFamily("CREATE2")}
+}
+
+// deployFamily builds the deploy family of a creating instruction.
+func deployFamily(op string) Family {
+	name := "deploy-" + strings.ToLower(op)
+	family := Family{
+		Name:        name,
+		Description: fmt.Sprintf("%s of 0 bytes to 24 KiB of code", op),
+		Unit:        "byte",
+		Since:       Shanghai,
+	}
+	for _, size := range deploySizes {
+		code := pattern(size.size)
+		initcode := Initcode("", code)
+		words := uint64(size.size+31) / 32
+		constructor := 18 + 3*words + MemoryGas(size.size) + 200*uint64(size.size)
+		family.Members = append(family.Members, Member{
+			Vector: Vector{
+				Name:   name + "/" + size.name,
+				Code:   factory(op, 1),
+				Input:  initcode,
+				Gas:    deployGas,
+				Expect: &Outcome{Status: Success, Output: deployed(op, initcode, code), GasUsed: factoryGas(op, len(initcode)) + constructor},
+			},
+			X: size.size,
+		})
+	}
+	return family
+}
+
+// LoadDeployments returns the vectors deploying the workload contracts of
+// the corpus rooted at root and the BEP20 token of deployments/bep20.hex
+// with CREATE and CREATE2, named deploy/<contract>-<create|create2>, and
+// the vectors of deployments failing in the creation. The factory of
+// DeployFamilies runs the initcode, which for the BEP20 token sets up its
+// storage and emits its events like the constructor of the token, and for
+// the workload contracts is their creation code compiled by solc. The
+// vectors meter initcode as done from Shanghai.
+func LoadDeployments(root string) ([]Vector, error) {
+	contracts, err := LoadContracts(root)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(root, "deployments", "bep20.hex"))
+	if err != nil {
+		return nil, err
+	}
+	var bep20 Bytes
+	if err := bep20.UnmarshalText(bytes.TrimSpace(data)); err != nil {
+		return nil, fmt.Errorf("bep20.hex: %w", err)
+	}
+
+	var res []Vector
+	for _, op := range []string{"CREATE", "CREATE2"} {
+		initcode := Initcode(bep20Constructor, bep20)
+		res = append(res, Vector{
+			Name:   "deploy/bep20-" + strings.ToLower(op),
+			Code:   factory(op, 1),
+			Input:  initcode,
+			Gas:    deployGas,
+			Expect: &Outcome{Status: Success, Output: deployed(op, initcode, bep20), Logs: bep20Logs()},
+		})
+	}
+	for _, contract := range contracts {
+		for _, op := range []string{"CREATE", "CREATE2"} {
+			res = append(res, Vector{
+				Name:   fmt.Sprintf("deploy/%s-%s", contract.Name, strings.ToLower(op)),
+				Code:   factory(op, 1),
+				Input:  contract.Creation,
+				Gas:    deployGas,
+				Expect: &Outcome{Status: Success, Output: deployed(op, contract.Creation, contract.Runtime)},
+			})
+		}
+	}
+	return append(res, deployFailureVectors()...), nil
+}
+
+// DeploymentsSince is the first revision executing the deployment
+// vectors, which meter initcode like EIP-3860.
+const DeploymentsSince = Shanghai
+
+// Deployments returns the vectors of LoadDeployments for the corpus found
+// by Root if revision executes them, and none before DeploymentsSince.
+func Deployments(revision Revision) ([]Vector, error) {
+	if revision < DeploymentsSince {
+		return nil, nil
+	}
+	root, err := Root()
+	if err != nil {
+		return nil, err
+	}
+	return LoadDeployments(root)
+}
+
+// deployFailureVectors returns the deployments failing in the creation,
+// which pushes zero instead of the address, so that the factory returns
+// zeros. Only initcode above the limit of EIP-3860 fails the factory.
+func deployFailureVectors() []Vector {
+	failed := func(name, initcode string) Vector {
+		return Vector{
+			Name:   "deploy/" + name,
+			Code:   factory("CREATE", 1),
+			Input:  asm.MustAssemble(initcode),
+			Gas:    deployGas,
+			Expect: &Outcome{Status: Success, Output: make([]byte, 96)},
+		}
+	}
+	large := Initcode("", make([]byte, MaxCodeSize))
+	outOfGas := Vector{
+		Name:   "deploy/deposit-out-of-gas",
+		Code:   factory("CREATE", 1),
+		Input:  large,
+		Gas:    1_000_000, // less than the 200 gas per byte of the code
+		Expect: &Outcome{Status: Success, Output: make([]byte, 96)},
+	}
+	collision := Vector{
+		Name:   "deploy/collision",
+		Code:   factory("CREATE2", 2),
+		Input:  Initcode("", nil),
+		Gas:    deployGas,
+		Expect: &Outcome{Status: Success, Output: make([]byte, 96)},
+	}
+	tooLarge := Vector{
+		Name:   "deploy/initcode-too-large",
+		Code:   factory("CREATE", 1),
+		Input:  make([]byte, MaxInitCodeSize+1),
+		Gas:    deployGas,
+		Expect: &Outcome{Status: Failure, GasUsed: deployGas},
+	}
+	return []Vector{
+		failed("constructor-revert", "PUSH1 0 PUSH1 0 REVERT"),
+		failed("constructor-invalid", "INVALID"),
+		failed("code-too-large", fmt.Sprintf("PUSH2 %d PUSH1 0 RETURN", MaxCodeSize+1)),
+		failed("code-ef-prefix", "PUSH1 0xef PUSH1 0 MSTORE8 PUSH1 1 PUSH1 0 RETURN"),
+		outOfGas,
+		collision,
+		tooLarge,
+	}
+}
+
+// Initcode returns initcode running the constructor, given as assembly,
+// and returning code, which is appended to it.
+func Initcode(constructor string, code []byte) []byte {
+	initcode := asm.MustAssemble(fmt.Sprintf(`
+		%s
+		PUSH2 %d DUP1 PUSH @code PUSH1 0 CODECOPY PUSH1 0 RETURN
+		code:
+	`, constructor, len(code)))
+	return append(initcode, code...)
+}
+
+// factory returns the code of a factory creating a contract from its input
+// with CREATE or CREATE2 the given number of times and returning the last
+// created address, its code hash and its code size, 96 bytes in total.
+func factory(op string, times int) []byte {
+	create := fmt.Sprintf("CALLDATASIZE PUSH1 0 PUSH1 0 %s", op)
+	if op == "CREATE2" {
+		create = "PUSH32 " + deploySalt.String() + " " + create
+	}
+	source := []string{"CALLDATASIZE PUSH1 0 PUSH1 0 CALLDATACOPY"}
+	for range times - 1 {
+		source = append(source, create+" POP")
+	}
+	return asm.MustAssemble(strings.Join(append(source, create, `
+		DUP1 PUSH1 0 MSTORE
+		DUP1 EXTCODEHASH PUSH1 32 MSTORE
+		EXTCODESIZE PUSH1 64 MSTORE
+		PUSH1 96 PUSH1 0 RETURN
+	`), "\n"))
+}
+
+// factoryGas returns the gas used by a factory creating a contract once
+// from initcode of the given size, apart from the gas of the initcode.
+func factoryGas(op string, size int) uint64 {
+	words := uint64(size+31) / 32
+	gas := 32_049 + 3*words + 2*words + MemoryGas(max(size, 96)) + 2*100 // the created address is warm
+	if op == "CREATE2" {
+		gas += 3 + 6*words // the salt and the hashing of the initcode
+	}
+	return gas
+}
+
+// deployed returns the output of a factory deploying code from initcode,
+// the created address, the hash of the code and its size.
+func deployed(op string, initcode, code []byte) []byte {
+	address := CreateAddress(DefaultRecipient, 0)
+	if op == "CREATE2" {
+		address = Create2Address(DefaultRecipient, deploySalt, initcode)
+	}
+	output := make([]byte, 96)
+	copy(output[12:], address[:])
+	codeHash := keccak256(code)
+	copy(output[32:], codeHash[:])
+	big.NewInt(int64(len(code))).FillBytes(output[64:])
+	return output
+}
+
+// CreateAddress returns the address of the contract created by sender with
+// CREATE at the given nonce, derived from the RLP list [sender, nonce].
+func CreateAddress(sender Address, nonce uint64) Address {
+	encodedNonce := []byte{0x80}
+	switch {
+	case nonce > 0 && nonce < 0x80:
+		encodedNonce = []byte{byte(nonce)}
+	case nonce >= 0x80:
+		value := new(big.Int).SetUint64(nonce).Bytes()
+		encodedNonce = append([]byte{0x80 + byte(len(value))}, value...)
+	}
+	list := append(append([]byte{0x80 + 20}, sender[:]...), encodedNonce...)
+	hash := keccak256(append([]byte{0xc0 + byte(len(list))}, list...))
+	return Address(hash[12:])
+}
+
+// Create2Address returns the address of the contract created by sender
+// with CREATE2 from initcode with the given salt, as defined by EIP-1014.
+func Create2Address(sender Address, salt Hash, initcode []byte) Address {
+	codeHash := keccak256(initcode)
+	hash := keccak256([]byte{0xff}, sender[:], salt[:], codeHash[:])
+	return Address(hash[12:])
+}
+
+// keccak256 returns the Keccak-256 hash of the concatenated data.
+func keccak256(data ...[]byte) Hash {
+	hasher := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		hasher.Write(d)
+	}
+	var res Hash
+	hasher.Sum(res[:0])
+	return res
+}
+
+// ownershipTransferred is the event signature of OwnershipTransferred of
+// Ownable, emitted by the BEP20 constructor besides a Transfer.
+var ownershipTransferred = keccak256([]byte("OwnershipTransferred(address,address)"))
+
+// bep20Constructor sets up the storage of the BEP20 token template for the
+// factory as owner: the owner in slot 0, the total supply in slot 3 and in
+// the balance of the owner in the mapping of slot 1, the decimals in slot
+// 4 and the symbol and name as short strings in slots 5 and 6. It emits
+// OwnershipTransferred and the Transfer of the minted supply.
+var bep20Constructor = fmt.Sprintf(`
+	CALLER PUSH1 0 SSTORE
+	CALLER PUSH1 0 PUSH32 %s PUSH1 0 PUSH1 0 LOG3
+	PUSH32 %s DUP1 PUSH1 3 SSTORE
+	CALLER PUSH1 0 MSTORE PUSH1 1 PUSH1 32 MSTORE
+	DUP1 PUSH1 64 PUSH1 0 KECCAK256 SSTORE
+	PUSH1 18 PUSH1 4 SSTORE
+	PUSH32 %s PUSH1 5 SSTORE
+	PUSH32 %s PUSH1 6 SSTORE
+	PUSH1 0 MSTORE
+	CALLER PUSH1 0 PUSH32 %s PUSH1 32 PUSH1 0 LOG3
+`, ownershipTransferred, Bytes(bep20Supply), shortString("USDT"), shortString("Tether USD"), logSignature)
+
+// bep20Logs returns the logs emitted by bep20Constructor.
+func bep20Logs() []Log {
+	owner := Hash{}
+	copy(owner[12:], DefaultRecipient[:])
+	return []Log{
+		{Topics: []Hash{ownershipTransferred, {}, owner}},
+		{Topics: []Hash{logSignature, {}, owner}, Data: bep20Supply},
+	}
+}
+
+// shortString returns the storage word of a Solidity string of less than
+// 32 bytes, the bytes followed by twice their length in the last byte.
+func shortString(s string) Hash {
+	var word Hash
+	copy(word[:], s)
+	word[31] = byte(2 * len(s))
+	return word
+}
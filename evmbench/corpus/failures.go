@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package corpus
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/asm"
+)
+
+// failureGas is the gas of the failure vectors. Exceptional halts consume
+// all of it.
+const failureGas = 100_000
+
+// FailureVectors returns vectors ending in each of the ways an execution
+// can fail: running out of gas in a loop and on memory expansion, jumping
+// to an invalid destination, under- and overflowing the stack, executing
+// INVALID and reverting with 32 bytes to 32 KiB of data. Like families they
+// are generated in code; both engine modules check them in TestFailures
+// and time them in BenchmarkFailures.
+func FailureVectors() []Vector {
+	failure := func(name, source string) Vector {
+		return Vector{
+			Name:   "failure/" + name,
+			Code:   asm.MustAssemble(source),
+			Gas:    failureGas,
+			Expect: &Outcome{Status: Failure, GasUsed: failureGas},
+		}
+	}
+	return []Vector{
+		failure("oog-loop", "loop: JUMPDEST PUSH1 1 PUSH1 2 ADD POP PUSH @loop JUMP"),
+		failure("oog-memory", "PUSH1 1 PUSH4 0xffffffff MSTORE"),
+		failure("invalid-jump", "PUSH1 3 JUMP STOP"),
+		failure("invalid-jump-push-data", "PUSH1 4 JUMP PUSH1 0x5b"), // target is the push data 0x5b
+		failure("invalid-jumpi", "PUSH1 1 PUSH1 5 JUMPI STOP"),
+		failure("stack-underflow", "PUSH1 1 ADD"),
+		failure("stack-overflow", strings.Repeat("ADDRESS ", 1025)),
+		failure("invalid-opcode", "PUSH1 1 POP INVALID"),
+		revertVector("revert-32b", 32),
+		revertVector("revert-1k", 1<<10),
+		revertVector("revert-32k", 32<<10),
+	}
+}
+
+// revertVector builds a vector reverting with size bytes of data, marked
+// with words at its start and end.
+func revertVector(name string, size int) Vector {
+	first, last := bytes.Repeat([]byte{0xa5}, 32), bytes.Repeat([]byte{0x3c}, 32)
+	code := asm.MustAssemble(fmt.Sprintf(`
+		PUSH32 %s PUSH1 0 MSTORE
+		PUSH32 %s PUSH2 %d MSTORE
+		PUSH2 %d PUSH1 0 REVERT
+	`, Bytes(first), Bytes(last), size-32, size))
+	output := make([]byte, size)
+	copy(output, first)
+	copy(output[size-32:], last)
+	words := uint64(size / 32)
+	return Vector{
+		Name: "failure/" + name,
+		Code: code,
+		Gas:  failureGas,
+		Expect: &Outcome{
+			Status:  Revert,
+			Output:  output,
+			GasUsed: 8*3 + 3*words + words*words/512, // pushes, MSTOREs and the memory
+		},
+	}
+}
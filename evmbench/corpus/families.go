@@ -0,0 +1,180 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package corpus
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/asm"
+)
+
+// Family is a set of synthetic vectors differing in a single parameter
+// while all other work stays the same. Fitting the time of its members
+// against the parameter separates the cost per unit of the parameter from
+// the fixed cost of a call.
+type Family struct {
+	Name        string
+	Description string
+	Unit        string   // of the parameter, e.g. "instruction"
+	Gas         uint64   // gas per unit of the parameter, if constant
+	Since       Revision // first revision executing the code as intended
+	Members     []Member
+}
+
+// Member is a vector of a family together with its parameter value.
+type Member struct {
+	Vector
+	X int
+}
+
+// Vectors returns the vectors of all members.
+func (f Family) Vectors() []Vector {
+	res := make([]Vector, 0, len(f.Members))
+	for _, member := range f.Members {
+		res = append(res, member.Vector)
+	}
+	return res
+}
+
+// CheckGas verifies the gas per unit of a family against the gas used by
+// its members, given in the order of the members. Other costs of the
+// members are the same and cancel out.
+func (f Family) CheckGas(gasUsed []uint64) error {
+	if f.Gas == 0 || len(gasUsed) != len(f.Members) {
+		return nil
+	}
+	var errs []error
+	for i := 1; i < len(f.Members); i++ {
+		want := uint64(f.Members[i].X-f.Members[0].X) * f.Gas
+		if got := gasUsed[i] - gasUsed[0]; got != want {
+			errs = append(errs, fmt.Errorf("%s: %d gas more than %s, expected %d", f.Members[i].Name, got, f.Members[0].Name, want))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Families returns the synthetic vector families. Unlike the workload
+// contracts they are generated in code and not committed as fixtures.
+func Families() []Family {
+	return append([]Family{
+		dispatchFamily("dispatch-jumpdest", "JUMPDEST", Istanbul, 1, 1),
+		dispatchFamily("dispatch-push0-pop", "PUSH0 POP", Shanghai, 4, 2),
+		dispatchFamily("dispatch-push1-pop", "PUSH1 0 POP", Istanbul, 5, 2),
+	}, slices.Concat(CodeSizeFamilies(), PricingFamilies(), CancunFamilies(), MemoryFamilies(), CalldataFamilies(), LogFamilies(), DeployFamilies())...)
+}
+
+// CodeSizeFamilies returns the families sweeping the code size from 100
+// bytes to the initcode limit of EIP-3860 with different densities of
+// push data and JUMPDESTs. Their code runs straight through, so besides
+// being executed it serves as input of code analysis benchmarks.
+func CodeSizeFamilies() []Family {
+	return []Family{
+		codeSizeFamily("codesize-gas-pop", "GAS POP, no push data or JUMPDEST", "GAS POP", 4),
+		codeSizeFamily("codesize-push1-pop", "PUSH1 1 POP, a third push data", "PUSH1 1 POP", 5),
+		codeSizeFamily("codesize-push32-pop", "PUSH32 POP, 94% push data", "PUSH32 0x"+strings.Repeat("ff", 32)+" POP", 5),
+		codeSizeFamily("codesize-jumpdest-push1-pop", "JUMPDEST PUSH1 1 POP, a JUMPDEST every 4 bytes", "JUMPDEST PUSH1 1 POP", 6),
+	}
+}
+
+// dispatchSizes are the code sizes of the dispatch families. The largest
+// is the contract size limit of EIP-170; the empty sled anchors the fit.
+var dispatchSizes = []struct {
+	name string
+	size int
+}{{"empty", 0}, {"1k", 1 << 10}, {"10k", 10 << 10}, {"24k", 24 << 10}}
+
+// dispatchKernel is the constant work of the dispatch families, returning
+// 7*5+3 as a word.
+var dispatchKernel = asm.MustAssemble(`
+	PUSH1 7 PUSH1 5 MUL PUSH1 3 ADD
+	PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+`)
+
+const (
+	dispatchKernelInstructions = 10
+	dispatchKernelGas          = 6*3 + 5 + 3 + 3 + 3 // pushes, MUL, ADD, MSTORE and its memory
+)
+
+// dispatchFamily builds vectors repeating a unit of code without effect,
+// given as assembly source and costing gas and dispatching instructions
+// per repetition, in front of the kernel. The unit is repeated as often
+// as it fits into the code size along with the kernel, so all members
+// execute the same arithmetic and differ only in the number of
+// dispatches.
+func dispatchFamily(name, source string, since Revision, gas uint64, instructions int) Family {
+	unit := asm.MustAssemble(source)
+	family := Family{
+		Name:        name,
+		Description: fmt.Sprintf("%s sled of 1k to 24k bytes in front of constant arithmetic", source),
+		Unit:        "instruction",
+		Since:       since,
+	}
+	output := make([]byte, 32)
+	output[31] = 7*5 + 3
+	for _, size := range dispatchSizes {
+		code, repetitions := repeat(unit, max(size.size-len(dispatchKernel), 0))
+		code = append(code, dispatchKernel...)
+		family.Members = append(family.Members, Member{
+			Vector: Vector{
+				Name: name + "/" + size.name,
+				Code: code,
+				Gas:  1_000_000,
+				Expect: &Outcome{
+					Status:  Success,
+					Output:  output,
+					GasUsed: uint64(repetitions)*gas + dispatchKernelGas,
+				},
+			},
+			X: repetitions*instructions + dispatchKernelInstructions,
+		})
+	}
+	return family
+}
+
+// codeSizes are the code sizes of the code size families, up to the
+// contract size limit of EIP-170 and twice that for initcode.
+var codeSizes = []struct {
+	name string
+	size int
+}{{"100", 100}, {"1k", 1 << 10}, {"4k", 4 << 10}, {"10k", 10 << 10}, {"24k", 24 << 10}, {"48k", 48 << 10}}
+
+// codeSizeFamily builds vectors of the code sizes repeating a unit of code
+// without effect, given as assembly source and costing gas per repetition.
+// Bytes left over are filled with STOP.
+func codeSizeFamily(name, unitDescription, source string, gas uint64) Family {
+	unit := asm.MustAssemble(source)
+	family := Family{
+		Name:        name,
+		Description: fmt.Sprintf("%s repeated over 100 bytes to 48k bytes", unitDescription),
+		Unit:        "byte",
+		Since:       Istanbul,
+	}
+	for _, size := range codeSizes {
+		code, repetitions := repeat(unit, size.size)
+		code = append(code, make([]byte, size.size-len(code))...)
+		family.Members = append(family.Members, Member{
+			Vector: Vector{
+				Name:   name + "/" + size.name,
+				Code:   code,
+				Gas:    1_000_000,
+				Expect: &Outcome{Status: Success, GasUsed: uint64(repetitions) * gas},
+			},
+			X: size.size,
+		})
+	}
+	return family
+}
+
+// repeat concatenates as many copies of unit as fit into size bytes.
+func repeat(unit []byte, size int) (code []byte, repetitions int) {
+	repetitions = size / len(unit)
+	code = make([]byte, 0, size)
+	for range repetitions {
+		code = append(code, unit...)
+	}
+	return code, repetitions
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package corpus
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/asm"
+)
+
+// logDataSizes are the data sizes of the members of the log-data family.
+var logDataSizes = []struct {
+	name string
+	size int
+}{{"0", 0}, {"256", 256}, {"1k", 1 << 10}, {"4k", 4 << 10}}
+
+// logDataLogs is the number of logs emitted by the members of the log-data
+// family.
+const logDataLogs = 16
+
+// logSignature is the first topic of all logs, the event signature of an
+// ERC-20 Transfer.
+var logSignature = Hash{
+	0xdd, 0xf2, 0x52, 0xad, 0x1b, 0xe2, 0xc8, 0x9b, 0x69, 0xc2, 0xb0, 0x68, 0xfc, 0x37, 0x8d, 0xaa,
+	0x95, 0x2b, 0xa7, 0xf1, 0x63, 0xc4, 0xa1, 0x16, 0x28, 0xf5, 0x5a, 0x4d, 0xf5, 0x23, 0xb3, 0xef,
+}
+
+// LogFamilies returns the families emitting logs: LOG0 to LOG4 with a word
+// of data 16 to 256 times, and 16 logs with three topics, like an ERC-20
+// Transfer, of 0 to 4 KiB of data. Their members expect the logs emitted,
+// so both engine modules check the logs collected by BSC's StateDB and the
+// Tosca context against each other.
+func LogFamilies() []Family {
+	var families []Family
+	for topics := range 5 {
+		families = append(families, logTopicsFamily(topics))
+	}
+	return append(families, logDataFamily())
+}
+
+// logTopicsFamily builds vectors emitting logs with the given number of
+// topics and the word operandA as data.
+func logTopicsFamily(topics int) Family {
+	name := fmt.Sprintf("log%d", topics)
+	family := Family{
+		Name:        name,
+		Description: fmt.Sprintf("LOG%d with a word of data repeated 16 to 256 times", topics),
+		Unit:        "log",
+		Gas:         3*uint64(topics+2) + 375*uint64(topics+1) + 8*32,
+		Since:       Istanbul,
+	}
+	data := wordOf(operandA)
+	for _, repetitions := range pricingRepetitions {
+		source := []string{mstores(0, operandA)}
+		var logs []Log
+		for i := range repetitions {
+			code, log := emitLog(i, topics, data)
+			source = append(source, code)
+			logs = append(logs, log)
+		}
+		family.Members = append(family.Members, Member{
+			Vector: Vector{
+				Name:   fmt.Sprintf("%s/%d", name, repetitions),
+				Code:   asm.MustAssemble(strings.Join(source, "\n")),
+				Gas:    10_000_000,
+				Expect: &Outcome{Status: Success, Logs: logs},
+			},
+			X: repetitions,
+		})
+	}
+	return family
+}
+
+// logDataFamily builds vectors emitting logDataLogs logs with three topics
+// and the data sizes. Memory is expanded to the largest size in advance
+// and marked at its first and last word.
+func logDataFamily() Family {
+	family := Family{
+		Name:        "log-data",
+		Description: fmt.Sprintf("%d LOG3 with 0 to 4 KiB of data", logDataLogs),
+		Unit:        "byte",
+		Gas:         8 * logDataLogs,
+		Since:       Istanbul,
+	}
+	largest := logDataSizes[len(logDataSizes)-1].size
+	memory := make([]byte, largest)
+	copy(memory, wordOf(operandA))
+	copy(memory[largest-32:], wordOf(operandB))
+	for _, size := range logDataSizes {
+		source := []string{mstores(0, operandA), mstores(largest-32, operandB)}
+		var logs []Log
+		for i := range logDataLogs {
+			code, log := emitLog(i, 3, memory[:size.size])
+			source = append(source, code)
+			logs = append(logs, log)
+		}
+		family.Members = append(family.Members, Member{
+			Vector: Vector{
+				Name:   "log-data/" + size.name,
+				Code:   asm.MustAssemble(strings.Join(source, "\n")),
+				Gas:    10_000_000,
+				Expect: &Outcome{Status: Success, Logs: logs},
+			},
+			X: size.size,
+		})
+	}
+	return family
+}
+
+// emitLog returns the code emitting the i-th log of a vector, with the
+// given number of topics and the data at the start of memory, and the log
+// it emits. The first topic is logSignature, the others distinguish the
+// logs.
+func emitLog(i, topics int, data []byte) (string, Log) {
+	log := Log{Data: data}
+	var pushes []string
+	for j := range topics {
+		topic := logSignature
+		if j > 0 {
+			topic = Hash{29: byte(j), 30: byte(i >> 8), 31: byte(i)}
+		}
+		log.Topics = append(log.Topics, topic)
+		pushes = append([]string{"PUSH32 " + topic.String()}, pushes...)
+	}
+	pushes = append(pushes, fmt.Sprintf("PUSH2 %d PUSH1 0 LOG%d", len(data), topics))
+	return strings.Join(pushes, " "), log
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package corpus
+
+import (
+	"fmt"
+
+	"github.com/sonicoperations/evmbench/asm"
+)
+
+// memoryGas is the gas of the memory vectors, the gas limit of an Ethereum
+// block. It bounds the largest expansion.
+const memoryGas = 30_000_000
+
+// memorySizes are the sizes memory is expanded to by the members of the
+// memory families, followed by the largest expansion the gas allows.
+var memorySizes = []struct {
+	name string
+	size int
+}{{"1k", 1 << 10}, {"32k", 32 << 10}, {"1M", 1 << 20}}
+
+// MemoryGas returns the gas charged for expanding memory from zero to size
+// bytes, linear in the words below 724 and quadratic above.
+func MemoryGas(size int) uint64 {
+	words := uint64(size+31) / 32
+	return 3*words + words*words/512
+}
+
+// MemoryFamilies returns the families expanding memory from zero to 1 KiB,
+// 32 KiB, 1 MiB and the largest size 30 million gas allow with a single
+// memory accessing instruction. Their time and allocations over the size
+// show how the memory of an engine scales.
+func MemoryFamilies() []Family {
+	copying := func(op string) func(size int) string {
+		return func(size int) string { return fmt.Sprintf("PUSH4 %d PUSH1 0 PUSH1 0 %s", size, op) }
+	}
+	// gas returns the gas of an expansion costing fixed gas and gasPerWord
+	// for every word of the size besides the expansion.
+	gas := func(fixed, gasPerWord uint64) func(size int) uint64 {
+		return func(size int) uint64 { return fixed + gasPerWord*uint64(size/32) + MemoryGas(size) }
+	}
+	return []Family{
+		memoryFamily("memory-mstore", "MSTORE", Istanbul, gas(9, 0), func(size int) string {
+			return fmt.Sprintf("PUSH1 1 PUSH4 %d MSTORE", size-32)
+		}),
+		memoryFamily("memory-mstore8", "MSTORE8", Istanbul, gas(9, 0), func(size int) string {
+			return fmt.Sprintf("PUSH1 1 PUSH4 %d MSTORE8", size-1)
+		}),
+		memoryFamily("memory-calldatacopy", "CALLDATACOPY", Istanbul, gas(12, 3), copying("CALLDATACOPY")),
+		memoryFamily("memory-codecopy", "CODECOPY", Istanbul, gas(12, 3), copying("CODECOPY")),
+		// The nested call returns size bytes of its own memory, so the
+		// expansion is paid twice. The call costs 100 gas from Berlin.
+		memoryFamily("memory-returndatacopy", "RETURNDATACOPY", Berlin, func(size int) uint64 {
+			return gas(167, 3)(size) + MemoryGas(size)
+		}, func(size int) string {
+			return fmt.Sprintf(`
+				CALLDATASIZE PUSH @returned JUMPI
+				PUSH1 0 PUSH1 0 PUSH1 1 PUSH1 0 ADDRESS GAS STATICCALL POP
+				%s STOP
+				returned: JUMPDEST PUSH4 %d PUSH1 0 RETURN
+			`, copying("RETURNDATACOPY")(size), size)
+		}),
+		memoryFamily("memory-mcopy", "MCOPY", Cancun, gas(12, 3), copying("MCOPY")),
+		memoryFamily("memory-keccak256", "KECCAK256", Istanbul, gas(38, 6), func(size int) string {
+			return fmt.Sprintf("PUSH4 %d PUSH1 0 KECCAK256 POP", size)
+		}),
+	}
+}
+
+// memoryFamily builds vectors expanding memory to the memory sizes with
+// the code returned by expand, which costs the gas returned by gasUsed.
+// The largest member expands to the largest multiple of 1 KiB whose gas
+// stays within the vector gas.
+func memoryFamily(name, op string, since Revision, gasUsed func(size int) uint64, expand func(size int) string) Family {
+	family := Family{
+		Name:        name,
+		Description: fmt.Sprintf("memory expanded to 1 KiB up to the gas limit by %s", op),
+		Unit:        "byte",
+		Since:       since,
+	}
+	largest := memorySizes[len(memorySizes)-1].size
+	for gasUsed(largest+1<<10) <= memoryGas {
+		largest += 1 << 10
+	}
+	member := func(name string, size int) Member {
+		return Member{
+			Vector: Vector{
+				Name:   name,
+				Code:   asm.MustAssemble(expand(size)),
+				Gas:    memoryGas,
+				Expect: &Outcome{Status: Success, GasUsed: gasUsed(size)},
+			},
+			X: size,
+		}
+	}
+	for _, size := range memorySizes {
+		family.Members = append(family.Members, member(name+"/"+size.name, size.size))
+	}
+	family.Members = append(family.Members, member(name+"/max", largest))
+	return family
+}
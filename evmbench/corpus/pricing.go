@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package corpus
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/asm"
+)
+
+// pricingRepetitions are the numbers of units executed by the members of
+// the pricing families.
+var pricingRepetitions = []int{16, 64, 256}
+
+// Operands of the pricing families.
+var (
+	ones     = "0x" + strings.Repeat("ff", 32)
+	operandA = "0x" + strings.Repeat("a5", 32)
+	operandB = "0x" + strings.Repeat("3c", 31) + "01"
+)
+
+// expandMemory expands the memory to 1 KiB, so that units accessing it do
+// not pay for expansion.
+const expandMemory = "PUSH1 0 PUSH2 0x03e0 MSTORE"
+
+// staticCall calls a precompile with input from memory and no output,
+// costing 119 gas besides the precompile.
+func staticCall(precompile, inputSize int) string {
+	return fmt.Sprintf("PUSH1 0 PUSH1 0 PUSH2 %d PUSH1 0 PUSH1 %d GAS STATICCALL POP", inputSize, precompile)
+}
+
+// mstores stores 32 byte words at consecutive offsets from offset.
+func mstores(offset int, words ...string) string {
+	var res []string
+	for i, word := range words {
+		res = append(res, fmt.Sprintf("PUSH32 %s PUSH2 %d MSTORE", word, offset+32*i))
+	}
+	return strings.Join(res, " ")
+}
+
+// coldAddress returns the i-th of distinct accounts not touched otherwise.
+func coldAddress(i int) string {
+	return Address{0xbb, 18: byte(i >> 8), 19: byte(i)}.String()
+}
+
+// PricingFamilies returns the families repeating a unit of an opcode class
+// 16, 64 and 256 times after an optional prologue, e.g. expanding memory or
+// writing precompile input. The gas of a unit is constant, so the time per
+// unit over its gas is the time per gas of the class.
+func PricingFamilies() []Family {
+	same := func(unit string) func(int) string {
+		return func(int) string { return unit }
+	}
+	modexp := func(baseSize, expSize, modSize int, exp string) string {
+		return mstores(0, fmt.Sprint(baseSize), fmt.Sprint(expSize), fmt.Sprint(modSize)) + " " +
+			mstores(96, repeatWord(ones, (baseSize+expSize+modSize+31)/32)...) + " " +
+			fmt.Sprintf("PUSH%d %s PUSH2 %d MSTORE", expSize, exp, 96+baseSize+expSize-32)
+	}
+	return []Family{
+		pricingFamily("gas-add", "ADD", Berlin, 11, "", same("PUSH1 3 PUSH1 5 ADD POP")),
+		pricingFamily("gas-mul", "MUL", Berlin, 13, "", same("PUSH1 3 PUSH1 5 MUL POP")),
+		pricingFamily("gas-div-256", "DIV of 256 bit operands", Berlin, 13, "", same("PUSH32 "+operandB+" PUSH32 "+operandA+" DIV POP")),
+		pricingFamily("gas-mod-256", "MOD of 256 bit operands", Berlin, 13, "", same("PUSH32 "+operandB+" PUSH32 "+operandA+" MOD POP")),
+		pricingFamily("gas-addmod-256", "ADDMOD of 256 bit operands", Berlin, 19, "", same("PUSH32 "+operandB+" PUSH32 "+operandA+" PUSH32 "+ones+" ADDMOD POP")),
+		pricingFamily("gas-mulmod-256", "MULMOD of 256 bit operands", Berlin, 19, "", same("PUSH32 "+operandB+" PUSH32 "+operandA+" PUSH32 "+ones+" MULMOD POP")),
+		pricingFamily("gas-exp-256", "EXP with a 32 byte exponent", Berlin, 1618, "", same("PUSH32 "+ones+" PUSH32 "+operandA+" EXP POP")),
+		pricingFamily("gas-signextend", "SIGNEXTEND", Berlin, 13, "", same("PUSH32 "+operandA+" PUSH1 15 SIGNEXTEND POP")),
+		pricingFamily("gas-shl", "SHL", Berlin, 11, "", same("PUSH32 "+operandA+" PUSH1 13 SHL POP")),
+		pricingFamily("gas-keccak256-32", "KECCAK256 of 32 bytes", Berlin, 44, expandMemory, same("PUSH1 32 PUSH1 0 KECCAK256 POP")),
+		pricingFamily("gas-keccak256-1k", "KECCAK256 of 1 KiB", Berlin, 230, expandMemory, same("PUSH2 1024 PUSH1 0 KECCAK256 POP")),
+		pricingFamily("gas-mload", "MLOAD", Berlin, 8, expandMemory, same("PUSH1 0 MLOAD POP")),
+		pricingFamily("gas-mstore", "MSTORE", Berlin, 9, expandMemory, same("PUSH1 1 PUSH1 0 MSTORE")),
+		pricingFamily("gas-calldatacopy-1k", "CALLDATACOPY of 1 KiB", Berlin, 108, expandMemory, same("PUSH2 1024 PUSH1 0 PUSH1 0 CALLDATACOPY")),
+		pricingFamily("gas-mcopy-1k", "MCOPY of 1 KiB", Cancun, 108, expandMemory, same("PUSH2 1024 PUSH1 0 PUSH1 0 MCOPY")),
+		pricingFamily("gas-sload-warm", "SLOAD of a warm slot", Berlin, 105, "PUSH1 0 SLOAD POP", same("PUSH1 0 SLOAD POP")),
+		pricingFamily("gas-sload-cold", "SLOAD of a cold slot", Berlin, 2105, "", func(i int) string { return fmt.Sprintf("PUSH2 %d SLOAD POP", i+1) }),
+		pricingFamily("gas-sstore-noop", "SSTORE of the current value of a warm slot", Berlin, 106, "PUSH1 0 SLOAD POP", same("PUSH1 0 PUSH1 0 SSTORE")),
+		pricingFamily("gas-tload", "TLOAD", Cancun, 105, "", same("PUSH1 0 TLOAD POP")),
+		pricingFamily("gas-tstore", "TSTORE", Cancun, 106, "", same("PUSH1 1 PUSH1 0 TSTORE")),
+		pricingFamily("gas-balance-warm", "BALANCE of a warm account", Berlin, 104, "", same("ADDRESS BALANCE POP")),
+		pricingFamily("gas-balance-cold", "BALANCE of a cold account", Berlin, 2605, "", func(i int) string { return "PUSH20 " + coldAddress(i) + " BALANCE POP" }),
+		pricingFamily("gas-extcodesize-cold", "EXTCODESIZE of a cold account", Berlin, 2605, "", func(i int) string { return "PUSH20 " + coldAddress(i) + " EXTCODESIZE POP" }),
+		pricingFamily("gas-extcodehash-cold", "EXTCODEHASH of a cold account", Berlin, 2605, "", func(i int) string { return "PUSH20 " + coldAddress(i) + " EXTCODEHASH POP" }),
+		pricingFamily("gas-blockhash", "BLOCKHASH", Berlin, 25, "", same("PUSH1 0 BLOCKHASH POP")),
+		pricingFamily("gas-ecrecover", "ecrecover precompile", Berlin, 119+3000, expandMemory, same(staticCall(1, 128))),
+		pricingFamily("gas-sha256-1k", "SHA-256 precompile of 1 KiB", Berlin, 119+60+12*32, expandMemory, same(staticCall(2, 1024))),
+		pricingFamily("gas-identity-1k", "identity precompile of 1 KiB", Berlin, 119+15+3*32, expandMemory, same(staticCall(4, 1024))),
+		// EIP-2565: max(200, ceil(max(base, mod)/8)^2 * max(bits(exp)-1, 1) / 3)
+		pricingFamily("gas-modexp-256", "MODEXP of 256 bit operands and exponent", Berlin, 119+16*255/3, modexp(32, 32, 32, ones), same(staticCall(5, 96+3*32))),
+		pricingFamily("gas-modexp-2048-e3", "MODEXP of 2048 bit operands and exponent 3", Berlin, 119+max(200, 1024*1/3), modexp(256, 1, 256, "3"), same(staticCall(5, 96+2*256+1))),
+		pricingFamily("gas-bn256add", "BN256 addition precompile", Berlin, 119+150, mstores(0, "1", "2", "1", "2"), same(staticCall(6, 128))),
+		pricingFamily("gas-bn256mul", "BN256 scalar multiplication precompile", Berlin, 119+6000, mstores(0, "1", "2", ones), same(staticCall(7, 96))),
+	}
+}
+
+// repeatWord returns n copies of word.
+func repeatWord(word string, n int) []string {
+	res := make([]string, n)
+	for i := range res {
+		res[i] = word
+	}
+	return res
+}
+
+// pricingFamily builds vectors executing the prologue followed by the
+// repetitions of a unit, given as assembly source by the index of the
+// repetition, costing gas each.
+func pricingFamily(name, class string, since Revision, gas uint64, prologue string, unit func(i int) string) Family {
+	family := Family{
+		Name:        name,
+		Description: fmt.Sprintf("%s repeated 16 to 256 times", class),
+		Unit:        "repetition",
+		Gas:         gas,
+		Since:       since,
+	}
+	for _, repetitions := range pricingRepetitions {
+		source := []string{prologue}
+		for i := range repetitions {
+			source = append(source, unit(i))
+		}
+		family.Members = append(family.Members, Member{
+			Vector: Vector{
+				Name:   fmt.Sprintf("%s/%d", name, repetitions),
+				Code:   asm.MustAssemble(strings.Join(source, "\n")),
+				Gas:    10_000_000,
+				Expect: &Outcome{Status: Success},
+			},
+			X: repetitions,
+		})
+	}
+	return family
+}
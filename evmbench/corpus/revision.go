@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package corpus
+
+import "fmt"
+
+// Revision is an EVM hard fork both engines can be configured for. Names
+// follow Tosca's revision names.
+type Revision int
+
+const (
+	Istanbul Revision = iota
+	Berlin
+	London
+	Paris
+	Shanghai
+	Cancun
+)
+
+// DefaultRevision is the revision vectors are executed in unless
+// configured otherwise. Expected outcomes are computed for it.
+const DefaultRevision = Cancun
+
+// Revisions lists all supported revisions in fork order.
+var Revisions = []Revision{Istanbul, Berlin, London, Paris, Shanghai, Cancun}
+
+var revisionNames = [...]string{"Istanbul", "Berlin", "London", "Paris", "Shanghai", "Cancun"}
+
+func (r Revision) String() string {
+	if r < 0 || int(r) >= len(revisionNames) {
+		return fmt.Sprintf("Revision(%d)", int(r))
+	}
+	return revisionNames[r]
+}
+
+func (r Revision) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Revision) UnmarshalText(text []byte) error {
+	revision, err := ParseRevision(string(text))
+	if err != nil {
+		return err
+	}
+	*r = revision
+	return nil
+}
+
+// ParseRevision returns the revision of the given name.
+func ParseRevision(name string) (Revision, error) {
+	for i, n := range revisionNames {
+		if n == name {
+			return Revision(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown revision %q", name)
+}
@@ -0,0 +1,8 @@
+module github.com/sonicoperations/evmbench
+
+go 1.23.0
+
+require (
+	golang.org/x/crypto v0.35.0
+	golang.org/x/sys v0.30.0
+)
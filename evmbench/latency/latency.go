@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Package latency records the durations of single executions in a
+// histogram of fixed size, so that benchmarks can report percentiles
+// without allocating while they are timed. Durations below 64ns are kept
+// exactly, longer ones in 32 buckets per power of two, a resolution of 3%.
+package latency
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+const (
+	exact      = 64 // durations in ns recorded exactly
+	subBuckets = 32 // buckets per power of two above exact
+	buckets    = exact + (64-6)*subBuckets
+)
+
+// Histogram counts durations. The zero value is empty and ready to use.
+type Histogram struct {
+	counts [buckets]uint64
+	total  uint64
+	max    time.Duration
+}
+
+// bucket returns the index of the bucket of v nanoseconds.
+func bucket(v uint64) int {
+	if v < exact {
+		return int(v)
+	}
+	shift := bits.Len64(v) - 6 // v>>shift is in [32, 64)
+	return exact + (shift-1)*subBuckets + int(v>>shift) - subBuckets
+}
+
+// upperBound returns the largest duration in nanoseconds of bucket i.
+func upperBound(i int) uint64 {
+	if i < exact {
+		return uint64(i)
+	}
+	shift := (i-exact)/subBuckets + 1
+	top := uint64((i-exact)%subBuckets + subBuckets)
+	return (top+1)<<shift - 1
+}
+
+// Record adds a duration; negative ones count as zero.
+func (h *Histogram) Record(d time.Duration) {
+	h.counts[bucket(uint64(max(d, 0)))]++
+	h.total++
+	h.max = max(h.max, d)
+}
+
+// Count returns the number of recorded durations.
+func (h *Histogram) Count() uint64 {
+	return h.total
+}
+
+// Max returns the longest recorded duration.
+func (h *Histogram) Max() time.Duration {
+	return h.max
+}
+
+// Quantile returns the upper bound of the bucket holding the q-quantile,
+// at most the longest duration, or 0 if nothing was recorded.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := uint64(q*float64(h.total) + 0.5)
+	rank = min(max(rank, 1), h.total)
+	sum := uint64(0)
+	for i, count := range h.counts {
+		sum += count
+		if sum >= rank {
+			return min(time.Duration(upperBound(i)), h.max)
+		}
+	}
+	return h.max
+}
+
+// reported are the quantiles passed on by Report, with their units.
+var reported = []struct {
+	q    float64
+	unit string
+}{{0.5, "p50-ns"}, {0.99, "p99-ns"}, {1, "max-ns"}}
+
+// Report passes the median, the 99th percentile and the maximum in
+// nanoseconds to report, each the geometric mean over the non-empty
+// histograms. With a histogram per vector of a mix, short and long
+// vectors weigh alike and a quantile rises with the tails of all vectors,
+// not just with the longest one. report matches the signature of
+// testing.B.ReportMetric.
+func Report(histograms []Histogram, report func(value float64, unit string)) {
+	for _, quantile := range reported {
+		logSum, count := 0.0, 0
+		for i := range histograms {
+			if histograms[i].total == 0 {
+				continue
+			}
+			logSum += math.Log(float64(max(histograms[i].Quantile(quantile.q), 1)))
+			count++
+		}
+		if count > 0 {
+			report(math.Exp(logSum/float64(count)), quantile.unit)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package latency
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBucket_CoversDurationsWithinResolution(t *testing.T) {
+	for _, v := range []uint64{0, 1, 63, 64, 65, 100, 1000, 12345, 1 << 40, 1<<63 - 1, 1<<64 - 1} {
+		i := bucket(v)
+		if i < 0 || i >= buckets {
+			t.Fatalf("bucket of %d out of range: %d", v, i)
+		}
+		upper := upperBound(i)
+		if upper < v || (i > 0 && upperBound(i-1) >= v) {
+			t.Errorf("%d in bucket %d up to %d, previous up to %d", v, i, upper, upperBound(i-1))
+		}
+		if v >= exact && float64(upper-v) > float64(v)/subBuckets {
+			t.Errorf("resolution of %d exceeded: bucket up to %d", v, upper)
+		}
+	}
+}
+
+func TestHistogram_Quantiles(t *testing.T) {
+	var h Histogram
+	for i := 0; i < 990; i++ {
+		h.Record(time.Microsecond)
+	}
+	for i := 0; i < 9; i++ {
+		h.Record(time.Millisecond)
+	}
+	h.Record(time.Second)
+
+	within := func(q float64, want time.Duration) {
+		if got := h.Quantile(q); got < want || float64(got) > float64(want)*1.04 {
+			t.Errorf("quantile %v: wanted about %v, got %v", q, want, got)
+		}
+	}
+	within(0.5, time.Microsecond)
+	within(0.99, time.Microsecond)
+	within(0.999, time.Millisecond)
+	within(1, time.Second)
+	if h.Count() != 1000 || h.Max() != time.Second {
+		t.Errorf("unexpected count %d or max %v", h.Count(), h.Max())
+	}
+}
+
+func TestReport_GeometricMeansOverHistograms(t *testing.T) {
+	histograms := make([]Histogram, 3)
+	histograms[0].Record(1000)
+	histograms[1].Record(4000)
+	metrics := map[string]float64{}
+	Report(histograms, func(value float64, unit string) { metrics[unit] = value })
+	for _, unit := range []string{"p50-ns", "p99-ns", "max-ns"} {
+		if got := metrics[unit]; math.Abs(got-2000) > 1 {
+			t.Errorf("%s: wanted 2000, got %v", unit, got)
+		}
+	}
+	Report(make([]Histogram, 2), func(value float64, unit string) { t.Errorf("unexpected metric %s", unit) })
+}
+
+func TestHistogram_EmptyHasNoQuantiles(t *testing.T) {
+	var h Histogram
+	if h.Quantile(0.5) != 0 || h.Count() != 0 {
+		t.Errorf("quantile of empty histogram: %v", h.Quantile(0.5))
+	}
+}
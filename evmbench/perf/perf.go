@@ -0,0 +1,222 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Package perf collects hardware performance counters around benchmark runs.
+// Counters are read through Linux perf_event_open for the calling thread
+// only, user space only. Events the kernel or CPU does not support are left
+// out of the results instead of failing the run; on other platforms, or if
+// perf events are disabled altogether, Start reports ErrUnavailable.
+package perf
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Names of the collected events.
+const (
+	Instructions = "instructions"
+	Cycles       = "cycles"
+	BranchMisses = "branch-misses"
+	L1IMisses    = "L1i-misses"
+	L1DMisses    = "L1d-misses"
+	ITLBMisses   = "iTLB-misses"
+)
+
+// Events lists all collected events in reporting order.
+var Events = []string{Instructions, Cycles, BranchMisses, L1IMisses, L1DMisses, ITLBMisses}
+
+// ErrUnavailable is returned by Start if no counter could be opened.
+var ErrUnavailable = errors.New("hardware performance counters unavailable")
+
+// Counts holds counter values by event name. Events that could not be
+// measured are absent.
+type Counts map[string]float64
+
+// Record holds the counters of one vector on one engine, summed over all
+// runs.
+type Record struct {
+	Engine string `json:"engine"`
+	Vector string `json:"vector"`
+	Runs   int    `json:"runs"`
+	// Steps is the number of EVM instructions executed per run, or zero if
+	// the engine cannot count them.
+	Steps  uint64 `json:"steps,omitempty"`
+	Counts Counts `json:"counts"`
+}
+
+// PerRun returns the average value of an event per run.
+func (r Record) PerRun(event string) (float64, bool) {
+	value, found := r.Counts[event]
+	if !found || r.Runs == 0 {
+		return 0, false
+	}
+	return value / float64(r.Runs), true
+}
+
+// IPC returns the retired instructions per cycle.
+func (r Record) IPC() (float64, bool) {
+	instructions, found := r.Counts[Instructions]
+	cycles, cyclesFound := r.Counts[Cycles]
+	if !found || !cyclesFound || cycles == 0 {
+		return 0, false
+	}
+	return instructions / cycles, true
+}
+
+// ReportMetrics passes IPC and the per-run value of every measured event
+// to report, and if steps are known, the values per EVM instruction. It
+// matches the signature of testing.B.ReportMetric.
+func (r Record) ReportMetrics(report func(value float64, unit string)) {
+	if ipc, ok := r.IPC(); ok {
+		report(ipc, "IPC")
+	}
+	for _, event := range Events {
+		value, ok := r.PerRun(event)
+		if !ok {
+			continue
+		}
+		report(value, event+"/op")
+		if r.Steps > 0 {
+			report(value/float64(r.Steps), event+"/step")
+		}
+	}
+}
+
+// Collector keeps the latest record per engine and vector and mirrors them
+// to a file. Benchmarks are executed several times with growing iteration
+// counts, so only the final record of each is retained.
+type Collector struct {
+	Path    string // output file, or empty to only keep records in memory
+	records []Record
+	index   map[[2]string]int
+}
+
+// Add stores a record, replacing an earlier one of the same engine and
+// vector, and rewrites the output file.
+func (c *Collector) Add(record Record) error {
+	if c.index == nil {
+		c.index = map[[2]string]int{}
+	}
+	key := [2]string{record.Engine, record.Vector}
+	if i, found := c.index[key]; found {
+		c.records[i] = record
+	} else {
+		c.index[key] = len(c.records)
+		c.records = append(c.records, record)
+	}
+	if c.Path == "" {
+		return nil
+	}
+	return WriteRecords(c.Path, c.records)
+}
+
+// Records returns the collected records in insertion order.
+func (c *Collector) Records() []Record {
+	return c.records
+}
+
+// WriteRecords writes records as newline delimited JSON, replacing the
+// content of the file at path.
+func WriteRecords(path string, records []Record) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+// ReadRecords parses newline delimited JSON records.
+func ReadRecords(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// WriteComparison prints the records of all engines side by side, one table
+// per vector. Values are normalized by the EVM instructions of the vector;
+// since all engines execute the same instruction stream, the step count of
+// any engine reporting it is used for all of them.
+func WriteComparison(w io.Writer, records []Record) error {
+	var vectors []string
+	byVector := map[string][]Record{}
+	steps := map[string]uint64{}
+	for _, record := range records {
+		if _, found := byVector[record.Vector]; !found {
+			vectors = append(vectors, record.Vector)
+		}
+		byVector[record.Vector] = append(byVector[record.Vector], record)
+		if record.Steps > 0 {
+			steps[record.Vector] = record.Steps
+		}
+	}
+	sort.Strings(vectors)
+
+	for _, vector := range vectors {
+		records := byVector[vector]
+		fmt.Fprintf(w, "### %s\n\n", vector)
+		if steps[vector] > 0 {
+			fmt.Fprintf(w, "%d EVM instructions per run\n\n", steps[vector])
+		}
+		fmt.Fprintf(w, "| Metric |")
+		for _, record := range records {
+			fmt.Fprintf(w, " %s |", record.Engine)
+		}
+		fmt.Fprintf(w, "\n|--------|")
+		for range records {
+			fmt.Fprintf(w, "-----:|")
+		}
+		fmt.Fprintln(w)
+
+		row := func(metric string, value func(Record) (float64, bool), format string) {
+			fmt.Fprintf(w, "| %s |", metric)
+			for _, record := range records {
+				if v, ok := value(record); ok {
+					fmt.Fprintf(w, " "+format+" |", v)
+				} else {
+					fmt.Fprintf(w, " - |")
+				}
+			}
+			fmt.Fprintln(w)
+		}
+		row("IPC", Record.IPC, "%.2f")
+		for _, event := range Events {
+			row(event+"/op", func(r Record) (float64, bool) { return r.PerRun(event) }, "%.0f")
+		}
+		if n := steps[vector]; n > 0 {
+			for _, event := range Events {
+				row(event+"/step", func(r Record) (float64, bool) {
+					value, ok := r.PerRun(event)
+					return value / float64(n), ok
+				}, "%.3f")
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
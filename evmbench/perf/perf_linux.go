@@ -0,0 +1,126 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+//go:build linux
+
+package perf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+type event struct {
+	name   string
+	typ    uint32
+	config uint64
+}
+
+// cacheMiss encodes the read misses of a cache as perf_event_open config.
+func cacheMiss(cache uint64) uint64 {
+	return cache | unix.PERF_COUNT_HW_CACHE_OP_READ<<8 | unix.PERF_COUNT_HW_CACHE_RESULT_MISS<<16
+}
+
+var events = []event{
+	{Instructions, unix.PERF_TYPE_HARDWARE, unix.PERF_COUNT_HW_INSTRUCTIONS},
+	{Cycles, unix.PERF_TYPE_HARDWARE, unix.PERF_COUNT_HW_CPU_CYCLES},
+	{BranchMisses, unix.PERF_TYPE_HARDWARE, unix.PERF_COUNT_HW_BRANCH_MISSES},
+	{L1IMisses, unix.PERF_TYPE_HW_CACHE, cacheMiss(unix.PERF_COUNT_HW_CACHE_L1I)},
+	{L1DMisses, unix.PERF_TYPE_HW_CACHE, cacheMiss(unix.PERF_COUNT_HW_CACHE_L1D)},
+	{ITLBMisses, unix.PERF_TYPE_HW_CACHE, cacheMiss(unix.PERF_COUNT_HW_CACHE_ITLB)},
+}
+
+type counter struct {
+	name string
+	fd   int
+}
+
+// Session is a set of enabled counters bound to the calling OS thread.
+type Session struct {
+	counters    []counter
+	unavailable []string
+}
+
+// Start opens and enables all supported counters for the calling thread.
+// The calling goroutine is locked to its thread until Stop is called, so
+// Start and Stop must be called from the same goroutine. Work done by other
+// goroutines, like the concurrent phases of the garbage collector, is not
+// counted.
+func Start() (*Session, error) {
+	runtime.LockOSThread()
+	session := &Session{}
+	for _, e := range events {
+		attr := unix.PerfEventAttr{
+			Type:        e.typ,
+			Size:        uint32(unsafe.Sizeof(unix.PerfEventAttr{})),
+			Config:      e.config,
+			Read_format: unix.PERF_FORMAT_TOTAL_TIME_ENABLED | unix.PERF_FORMAT_TOTAL_TIME_RUNNING,
+			Bits:        unix.PerfBitDisabled | unix.PerfBitExcludeKernel | unix.PerfBitExcludeHv,
+		}
+		fd, err := unix.PerfEventOpen(&attr, 0, -1, -1, unix.PERF_FLAG_FD_CLOEXEC)
+		if err != nil {
+			session.unavailable = append(session.unavailable, e.name)
+			continue
+		}
+		session.counters = append(session.counters, counter{name: e.name, fd: fd})
+	}
+	if len(session.counters) == 0 {
+		runtime.UnlockOSThread()
+		return nil, ErrUnavailable
+	}
+	for _, c := range session.counters {
+		if err := unix.IoctlSetInt(c.fd, unix.PERF_EVENT_IOC_RESET, 0); err != nil {
+			session.close()
+			return nil, fmt.Errorf("failed to reset %s counter: %w", c.name, err)
+		}
+		if err := unix.IoctlSetInt(c.fd, unix.PERF_EVENT_IOC_ENABLE, 0); err != nil {
+			session.close()
+			return nil, fmt.Errorf("failed to enable %s counter: %w", c.name, err)
+		}
+	}
+	return session, nil
+}
+
+// Unavailable returns the events that could not be opened.
+func (s *Session) Unavailable() []string {
+	return s.unavailable
+}
+
+// Stop disables the counters and returns their values. If the kernel
+// multiplexed counters because of a shortage of hardware registers, the
+// values are scaled to the full measurement period.
+func (s *Session) Stop() (Counts, error) {
+	defer s.close()
+	for _, c := range s.counters {
+		if err := unix.IoctlSetInt(c.fd, unix.PERF_EVENT_IOC_DISABLE, 0); err != nil {
+			return nil, fmt.Errorf("failed to disable %s counter: %w", c.name, err)
+		}
+	}
+	counts := Counts{}
+	var buffer [24]byte
+	for _, c := range s.counters {
+		if _, err := unix.Read(c.fd, buffer[:]); err != nil {
+			return nil, fmt.Errorf("failed to read %s counter: %w", c.name, err)
+		}
+		value := binary.NativeEndian.Uint64(buffer[0:])
+		enabled := binary.NativeEndian.Uint64(buffer[8:])
+		running := binary.NativeEndian.Uint64(buffer[16:])
+		if running == 0 {
+			continue // never scheduled on the PMU
+		}
+		counts[c.name] = float64(value) * float64(enabled) / float64(running)
+	}
+	return counts, nil
+}
+
+func (s *Session) close() {
+	for _, c := range s.counters {
+		unix.Close(c.fd)
+	}
+	s.counters = nil
+	runtime.UnlockOSThread()
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+//go:build !linux
+
+package perf
+
+// Session is a set of enabled counters. Counters are only supported on
+// Linux.
+type Session struct{}
+
+// Start reports ErrUnavailable on this platform.
+func Start() (*Session, error) {
+	return nil, ErrUnavailable
+}
+
+// Unavailable returns the events that could not be opened.
+func (s *Session) Unavailable() []string {
+	return Events
+}
+
+// Stop returns no counts.
+func (s *Session) Stop() (Counts, error) {
+	return Counts{}, nil
+}
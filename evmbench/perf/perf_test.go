@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package perf
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSession_CountsInstructions(t *testing.T) {
+	session, err := Start()
+	if errors.Is(err, ErrUnavailable) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := 0
+	for i := 0; i < 1_000_000; i++ {
+		sum += i
+	}
+	counts, err := session.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if instructions, found := counts[Instructions]; found && instructions < 1_000_000 {
+		t.Errorf("too few instructions counted for %d: %f", sum, instructions)
+	}
+}
+
+func TestRecord_ReportMetricsNormalizesBySteps(t *testing.T) {
+	record := Record{Engine: "lfvm", Vector: "v", Runs: 10, Steps: 50, Counts: Counts{
+		Instructions: 2000,
+		Cycles:       1000,
+		BranchMisses: 100,
+	}}
+	metrics := map[string]float64{}
+	record.ReportMetrics(func(value float64, unit string) { metrics[unit] = value })
+
+	want := map[string]float64{
+		"IPC":                2,
+		"instructions/op":    200,
+		"instructions/step":  4,
+		"cycles/op":          100,
+		"cycles/step":        2,
+		"branch-misses/op":   10,
+		"branch-misses/step": 0.2,
+	}
+	if len(metrics) != len(want) {
+		t.Errorf("unexpected metrics: %v", metrics)
+	}
+	for unit, value := range want {
+		if metrics[unit] != value {
+			t.Errorf("unexpected %s, wanted %f, got %f", unit, value, metrics[unit])
+		}
+	}
+}
+
+func TestRecords_RoundTrip(t *testing.T) {
+	records := []Record{
+		{Engine: "lfvm", Vector: "v", Runs: 1, Counts: Counts{Cycles: 10}},
+		{Engine: "bsc", Vector: "v", Runs: 1, Steps: 5, Counts: Counts{}},
+	}
+	path := filepath.Join(t.TempDir(), "perf.ndjson")
+	if err := WriteRecords(path, records); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	restored, err := ReadRecords(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored) != 2 || restored[0].Counts[Cycles] != 10 || restored[1].Steps != 5 {
+		t.Errorf("unexpected records: %+v", restored)
+	}
+}
+
+func TestCollector_KeepsLatestRecordPerVector(t *testing.T) {
+	var collector Collector
+	for runs := 1; runs <= 3; runs++ {
+		if err := collector.Add(Record{Engine: "lfvm", Vector: "v", Runs: runs}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := collector.Add(Record{Engine: "bsc", Vector: "v", Runs: 1}); err != nil {
+		t.Fatal(err)
+	}
+	records := collector.Records()
+	if len(records) != 2 || records[0].Runs != 3 || records[1].Engine != "bsc" {
+		t.Errorf("unexpected records: %+v", records)
+	}
+}
+
+func TestWriteComparison_UsesStepsOfAnyEngine(t *testing.T) {
+	records := []Record{
+		{Engine: "lfvm", Vector: "erc20/transfer", Runs: 2, Counts: Counts{BranchMisses: 40}},
+		{Engine: "bsc", Vector: "erc20/transfer", Runs: 2, Steps: 100, Counts: Counts{BranchMisses: 100}},
+	}
+	var out bytes.Buffer
+	if err := WriteComparison(&out, records); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| Metric | lfvm | bsc |",
+		"| branch-misses/step | 0.200 | 0.500 |",
+		"| IPC | - | - |",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
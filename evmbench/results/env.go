@@ -0,0 +1,107 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package results
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Env describes the machine and toolchain a record was measured with.
+type Env struct {
+	GoVersion  string `json:"goVersion"`
+	GOOS       string `json:"goos"`
+	GOARCH     string `json:"goarch"`
+	CPU        string `json:"cpu"`
+	Cores      int    `json:"cores"`
+	Governor   string `json:"governor,omitempty"` // CPU frequency governor, Linux only
+	GOMAXPROCS int    `json:"gomaxprocs"`
+	GOGC       string `json:"gogc"`
+	GOMEMLIMIT string `json:"gomemlimit,omitempty"` // empty if unset
+	Commit     string `json:"commit,omitempty"`     // revision of the benchmark repository
+}
+
+// HostEnv collects the properties of the current machine. The Go version
+// and GOMAXPROCS are those of the calling process and may need to be
+// replaced by the values the benchmarks were run with.
+func HostEnv() Env {
+	gogc := os.Getenv("GOGC")
+	if gogc == "" {
+		gogc = "100"
+	}
+	return Env{
+		GoVersion:  runtime.Version(),
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		CPU:        cpuModel(),
+		Cores:      runtime.NumCPU(),
+		Governor:   readLine("/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor"),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		GOGC:       gogc,
+		GOMEMLIMIT: os.Getenv("GOMEMLIMIT"),
+		Commit:     command("git", "rev-parse", "--short", "HEAD"),
+	}
+}
+
+// Diff returns the names of the properties that differ between two
+// environments. The repository commit is ignored.
+func (e Env) Diff(other Env) []string {
+	var res []string
+	add := func(name string, differs bool) {
+		if differs {
+			res = append(res, name)
+		}
+	}
+	add("go", e.GoVersion != other.GoVersion)
+	add("os/arch", e.GOOS != other.GOOS || e.GOARCH != other.GOARCH)
+	add("cpu", e.CPU != other.CPU || e.Cores != other.Cores)
+	add("governor", e.Governor != other.Governor)
+	add("gomaxprocs", e.GOMAXPROCS != other.GOMAXPROCS)
+	add("gogc", e.GOGC != other.GOGC)
+	add("gomemlimit", e.GOMEMLIMIT != other.GOMEMLIMIT)
+	return res
+}
+
+func cpuModel() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return command("sysctl", "-n", "machdep.cpu.brand_string")
+	case "linux":
+		file, err := os.Open("/proc/cpuinfo")
+		if err != nil {
+			return ""
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			key, value, found := strings.Cut(scanner.Text(), ":")
+			key = strings.TrimSpace(key)
+			if found && (key == "model name" || key == "Model" || key == "Hardware") {
+				return strings.TrimSpace(value)
+			}
+		}
+	}
+	return ""
+}
+
+func readLine(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// command returns the trimmed output of a command, or an empty string if
+// it fails.
+func command(name string, args ...string) string {
+	out, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package results
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// Fit is a least squares fit of the linear model y = Intercept + Slope*x.
+type Fit struct {
+	Intercept, Slope float64
+	R2               float64 // coefficient of determination
+	N                int     // number of points
+}
+
+// LinearFit fits a line through the points (x[i], y[i]). The fit is
+// undefined, with N below 2, if there are fewer than two distinct x.
+func LinearFit(x, y []float64) Fit {
+	n := float64(len(x))
+	var sx, sy float64
+	for i := range x {
+		sx += x[i]
+		sy += y[i]
+	}
+	mx, my := sx/n, sy/n
+	var sxx, sxy, syy float64
+	for i := range x {
+		sxx += (x[i] - mx) * (x[i] - mx)
+		sxy += (x[i] - mx) * (y[i] - my)
+		syy += (y[i] - my) * (y[i] - my)
+	}
+	if sxx == 0 {
+		return Fit{N: min(len(x), 1)}
+	}
+	fit := Fit{Slope: sxy / sxx, N: len(x), R2: 1}
+	fit.Intercept = my - fit.Slope*mx
+	if syy > 0 {
+		fit.R2 = sxy * sxy / (sxx * syy)
+	}
+	return fit
+}
+
+// FitFamily fits the time per call of the members of a family on an engine
+// and revision against their parameter, using every sample. The slope is
+// the time per unit of the parameter and the intercept the fixed time per
+// call.
+func FitFamily(results *Results, family corpus.Family, engine string, revision corpus.Revision) Fit {
+	var x, y []float64
+	for _, member := range family.Members {
+		record, found := results.Lookup(Key{Engine: engine, Revision: revision, Vector: member.Name})
+		if !found {
+			continue
+		}
+		for _, value := range record.Values("ns/op") {
+			x = append(x, float64(member.X))
+			y = append(y, value)
+		}
+	}
+	return LinearFit(x, y)
+}
+
+// FamilyFits tabulates the fits of all families with records per revision
+// and engine. Each engine after the first is related to the first one by
+// the ratio of the times per unit.
+func FamilyFits(results *Results, families []corpus.Family) Table {
+	engines := results.Engines()
+	table := Table{Header: []string{"Family", "Revision", "Unit"}}
+	for _, engine := range engines {
+		table.Header = append(table.Header, engine+" ns/unit", engine+" fixed ns", engine+" R²")
+	}
+	for _, engine := range engines[min(1, len(engines)):] {
+		table.Header = append(table.Header, engine+"/"+engines[0])
+	}
+	for _, family := range families {
+		for _, revision := range corpus.Revisions {
+			cells := []string{family.Name, revision.String(), family.Unit}
+			var slopes []float64
+			fitted := false
+			for _, engine := range engines {
+				fit := FitFamily(results, family, engine, revision)
+				if fit.N < 2 {
+					cells = append(cells, "-", "-", "-")
+					slopes = append(slopes, math.NaN())
+					continue
+				}
+				fitted = true
+				cells = append(cells, formatFloat(fit.Slope), formatFloat(fit.Intercept), fmt.Sprintf("%.4f", fit.R2))
+				slopes = append(slopes, fit.Slope)
+			}
+			if !fitted {
+				continue
+			}
+			for _, slope := range slopes[min(1, len(slopes)):] {
+				if math.IsNaN(slope) || math.IsNaN(slopes[0]) || slopes[0] <= 0 {
+					cells = append(cells, "-")
+				} else {
+					cells = append(cells, fmt.Sprintf("%.2fx", slope/slopes[0]))
+				}
+			}
+			table.Rows = append(table.Rows, cells)
+		}
+	}
+	return table
+}
+
+// GasPricing tabulates the time per gas of the families with a constant gas
+// per unit, e.g. corpus.PricingFamilies, per engine: the slope of the fit
+// over the gas per unit. Each is related to the median time per gas of all
+// tabulated families on the engine; those at least threshold times the
+// median are flagged as underpriced, those at most its inverse as
+// overpriced.
+func GasPricing(results *Results, families []corpus.Family, revision corpus.Revision, threshold float64) Table {
+	engines := results.Engines()
+	table := Table{Header: []string{"Family", "gas/unit"}}
+	for _, engine := range engines {
+		table.Header = append(table.Header, engine+" ns/gas", engine+" Mgas/s", engine+" vs median")
+	}
+	table.Header = append(table.Header, "Flags")
+
+	nsPerGas := map[string][]float64{}
+	for _, family := range families {
+		for _, engine := range engines {
+			value := math.NaN()
+			if fit := FitFamily(results, family, engine, revision); fit.N >= 2 && family.Gas > 0 {
+				value = fit.Slope / float64(family.Gas)
+			}
+			nsPerGas[engine] = append(nsPerGas[engine], value)
+		}
+	}
+	medians := map[string]float64{}
+	for _, engine := range engines {
+		var values []float64
+		for _, value := range nsPerGas[engine] {
+			if !math.IsNaN(value) {
+				values = append(values, value)
+			}
+		}
+		medians[engine] = Summarize(values).Median
+	}
+
+	for i, family := range families {
+		cells := []string{family.Name, fmt.Sprint(family.Gas)}
+		var flags []string
+		measured := false
+		for _, engine := range engines {
+			value := nsPerGas[engine][i]
+			if math.IsNaN(value) || medians[engine] <= 0 {
+				cells = append(cells, "-", "-", "-")
+				continue
+			}
+			measured = true
+			ratio := value / medians[engine]
+			cells = append(cells, fmt.Sprintf("%.3f", value), formatFloat(1e3/value), fmt.Sprintf("%.2fx", ratio))
+			switch {
+			case ratio >= threshold:
+				flags = append(flags, engine+" underpriced")
+			case ratio <= 1/threshold:
+				flags = append(flags, engine+" overpriced")
+			}
+		}
+		if measured {
+			table.Rows = append(table.Rows, append(cells, strings.Join(flags, ", ")))
+		}
+	}
+	return table
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package results
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// Observation is the outcome of a vector as observed on an engine. The
+// vector tests of the engine modules append them to the file given with
+// -outcomes, which every test binary starts anew.
+type Observation struct {
+	Key
+	Outcome corpus.Outcome `json:"outcome"`
+}
+
+// AppendObservations adds observations to a newline delimited JSON file,
+// creating it if necessary.
+func AppendObservations(path string, observations []Observation) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	for _, observation := range observations {
+		if err := encoder.Encode(observation); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+// ReadObservations parses newline delimited JSON observations.
+func ReadObservations(r io.Reader) ([]Observation, error) {
+	var res []Observation
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var observation Observation
+		if err := json.Unmarshal(scanner.Bytes(), &observation); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		res = append(res, observation)
+	}
+	return res, scanner.Err()
+}
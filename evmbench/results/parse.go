@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package results
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Benchmark is a single result line of go test -bench output.
+type Benchmark struct {
+	Name string // full name without the GOMAXPROCS suffix
+	Sample
+}
+
+// ParseBenchmarks extracts the result lines from go test -bench output.
+// All other lines are ignored. The "-procs" suffix go test appends to
+// names for GOMAXPROCS above one is removed; it needs to be known since
+// sub-benchmark names may end in numbers themselves.
+func ParseBenchmarks(r io.Reader, procs int) ([]Benchmark, error) {
+	suffix := fmt.Sprintf("-%d", procs)
+	var res []Benchmark
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") || len(fields)%2 != 0 {
+			continue
+		}
+		iterations, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		benchmark := Benchmark{Name: fields[0], Sample: Sample{Iterations: iterations}}
+		if procs > 1 {
+			benchmark.Name = strings.TrimSuffix(benchmark.Name, suffix)
+		}
+		for i := 2; i < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q in line %q", fields[i], scanner.Text())
+			}
+			switch unit := fields[i+1]; unit {
+			case "ns/op":
+				benchmark.NsPerOp = value
+			case "B/op":
+				benchmark.BytesPerOp = value
+			case "allocs/op":
+				benchmark.AllocsPerOp = value
+			default:
+				if benchmark.Metrics == nil {
+					benchmark.Metrics = map[string]float64{}
+				}
+				benchmark.Metrics[unit] = value
+			}
+		}
+		res = append(res, benchmark)
+	}
+	return res, scanner.Err()
+}
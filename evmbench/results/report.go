@@ -0,0 +1,178 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package results
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// Formats lists the supported report formats.
+var Formats = []string{"markdown", "html", "csv"}
+
+// Table is a rendered report independent of the output format.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Write renders the table in one of the supported formats.
+func (t Table) Write(w io.Writer, format string) error {
+	switch format {
+	case "markdown", "md":
+		fmt.Fprintf(w, "| %s |\n", strings.Join(t.Header, " | "))
+		align := make([]string, len(t.Header))
+		for i := range align {
+			align[i] = "---:"
+		}
+		align[0] = "---"
+		fmt.Fprintf(w, "|%s|\n", strings.Join(align, "|"))
+		for _, row := range t.Rows {
+			if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | ")); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "html":
+		fmt.Fprintln(w, "<table>")
+		writeRow := func(cells []string, tag string) {
+			fmt.Fprint(w, "<tr>")
+			for _, cell := range cells {
+				fmt.Fprintf(w, "<%s>%s</%s>", tag, html.EscapeString(cell), tag)
+			}
+			fmt.Fprintln(w, "</tr>")
+		}
+		writeRow(t.Header, "th")
+		for _, row := range t.Rows {
+			writeRow(row, "td")
+		}
+		_, err := fmt.Fprintln(w, "</table>")
+		return err
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write(t.Header); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(t.Rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	default:
+		return fmt.Errorf("unsupported format %q, supported are %v", format, Formats)
+	}
+}
+
+// Overview tabulates the median time, memory and allocations of every
+// vector and revision per engine. Each engine after the first is related
+// to the first one by the ratio of the median times.
+func Overview(results *Results) Table {
+	engines := results.Engines()
+	table := Table{Header: []string{"Vector", "Revision"}}
+	for _, engine := range engines {
+		table.Header = append(table.Header, engine+" ns/op", "±", engine+" B/op", engine+" allocs/op")
+	}
+	for _, engine := range engines[min(1, len(engines)):] {
+		table.Header = append(table.Header, engine+"/"+engines[0])
+	}
+
+	var rows []Key
+	seen := map[Key]bool{}
+	sorted := Results{Records: append([]Record(nil), results.Records...)}
+	sorted.Sort()
+	for _, record := range sorted.Records {
+		row := Key{Vector: record.Vector, Revision: record.Revision}
+		if !seen[row] {
+			seen[row] = true
+			rows = append(rows, row)
+		}
+	}
+
+	for _, row := range rows {
+		cells := []string{row.Vector, row.Revision.String()}
+		var times []float64
+		for _, engine := range engines {
+			row.Engine = engine
+			record, found := results.Lookup(row)
+			if !found {
+				cells = append(cells, "-", "", "-", "-")
+				times = append(times, 0)
+				continue
+			}
+			time := Summarize(record.Values("ns/op"))
+			cells = append(cells,
+				formatFloat(time.Median),
+				fmt.Sprintf("%.0f%%", 100*time.Spread()),
+				formatFloat(Summarize(record.Values("B/op")).Median),
+				formatFloat(Summarize(record.Values("allocs/op")).Median),
+			)
+			times = append(times, time.Median)
+		}
+		for _, time := range times[min(1, len(times)):] {
+			if time == 0 || times[0] == 0 {
+				cells = append(cells, "-")
+			} else {
+				cells = append(cells, fmt.Sprintf("%.2fx", time/times[0]))
+			}
+		}
+		table.Rows = append(table.Rows, cells)
+	}
+	return table
+}
+
+// Environments tabulates the distinct engine versions and environments the
+// records were measured with.
+func Environments(results *Results) Table {
+	table := Table{Header: []string{"Engine", "Version", "Go", "OS/Arch", "CPU", "Cores", "Governor", "GOMAXPROCS", "GOGC", "GOMEMLIMIT", "Commit"}}
+	seen := map[string]bool{}
+	for _, r := range results.Records {
+		row := []string{
+			r.Engine, r.EngineVersion, r.Env.GoVersion, r.Env.GOOS + "/" + r.Env.GOARCH, r.Env.CPU,
+			fmt.Sprint(r.Env.Cores), r.Env.Governor, fmt.Sprint(r.Env.GOMAXPROCS), r.Env.GOGC, r.Env.GOMEMLIMIT, r.Env.Commit,
+		}
+		if key := strings.Join(row, "\x00"); !seen[key] {
+			seen[key] = true
+			table.Rows = append(table.Rows, row)
+		}
+	}
+	return table
+}
+
+// Comparison tabulates deltas between two result files. Changes not
+// significant at level alpha are shown as "~", those of changed vectors as
+// "!". The last column lists differences of the measurement setup.
+func Comparison(deltas []Delta, unit string, alpha float64) Table {
+	table := Table{Header: []string{"Vector", "Engine", "Revision", "old " + unit, "new " + unit, "delta", "p", "differs"}}
+	for _, d := range deltas {
+		change := "~"
+		switch {
+		case d.Incomparable:
+			change = "!"
+		case d.Significant(alpha):
+			change = fmt.Sprintf("%+.2f%%", 100*d.Change)
+		}
+		table.Rows = append(table.Rows, []string{
+			d.Vector, d.Engine, d.Revision.String(),
+			fmt.Sprintf("%s ±%.0f%%", formatFloat(d.Old.Median), 100*d.Old.Spread()),
+			fmt.Sprintf("%s ±%.0f%%", formatFloat(d.New.Median), 100*d.New.Spread()),
+			change,
+			fmt.Sprintf("%.3f n=%d+%d", d.P, d.Old.N, d.New.N),
+			strings.Join(d.Notes, ", "),
+		})
+	}
+	return table
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case v >= 100:
+		return fmt.Sprintf("%.0f", v)
+	case v >= 10:
+		return fmt.Sprintf("%.1f", v)
+	default:
+		return fmt.Sprintf("%.2f", v)
+	}
+}
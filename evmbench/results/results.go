@@ -0,0 +1,183 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Package results defines the result files written by the evmbench command:
+// timings of corpus vectors per engine and revision, parsed from the output
+// of the engine modules' benchmarks, and the outcomes observed by their
+// differential tests.
+//
+// Result files hold one self-contained record per vector, engine and
+// revision, carrying the engine version, the hash of the vector and the
+// environment it was measured in. Files of different machines can thus be
+// concatenated, and comparisons detect changed vectors and environments.
+package results
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// Key identifies the measurements of a vector on an engine and revision.
+type Key struct {
+	Engine   string          `json:"engine"`
+	Revision corpus.Revision `json:"revision"`
+	Vector   string          `json:"vector"`
+}
+
+// Sample is one benchmark execution, i.e. one line of go test -bench
+// output or one batch of a worker. Its values are means over the
+// iterations of the execution; the durations of single iterations are
+// kept in Record.Runs.
+type Sample struct {
+	Iterations  int     `json:"iterations"`
+	NsPerOp     float64 `json:"nsPerOp"`
+	BytesPerOp  float64 `json:"bytesPerOp"`
+	AllocsPerOp float64 `json:"allocsPerOp"`
+	// Metrics holds additional metrics reported by the benchmark by unit,
+	// e.g. hardware counters.
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+}
+
+// Record collects all samples of a vector on an engine and revision.
+type Record struct {
+	Key
+	// EngineVersion is the version of the module implementing the engine,
+	// e.g. "github.com/bnb-chain/bsc v1.5.10".
+	EngineVersion string `json:"engineVersion"`
+	// VectorHash identifies the measured code, input and state; see
+	// corpus.Vector.Hash.
+	VectorHash string   `json:"vectorHash"`
+	Env        Env      `json:"env"`
+	Samples    []Sample `json:"samples"` // one per benchmark execution
+	// Runs holds the raw durations in nanoseconds of single iterations,
+	// each timed on its own by a worker process. Timing iterations adds
+	// the overhead of reading the clock, so Runs show the distribution
+	// while Samples give the more accurate mean.
+	Runs []float64 `json:"runs,omitempty"`
+}
+
+// RunUnit is the unit under which Values returns Record.Runs.
+const RunUnit = "ns/run"
+
+// Values returns the values of a metric over all samples. Besides the
+// units of additional metrics, "ns/op", "B/op" and "allocs/op" are
+// supported, and RunUnit for the durations of single iterations.
+func (r Record) Values(unit string) []float64 {
+	if unit == RunUnit {
+		return r.Runs
+	}
+	res := make([]float64, 0, len(r.Samples))
+	for _, s := range r.Samples {
+		switch unit {
+		case "ns/op":
+			res = append(res, s.NsPerOp)
+		case "B/op":
+			res = append(res, s.BytesPerOp)
+		case "allocs/op":
+			res = append(res, s.AllocsPerOp)
+		default:
+			if value, found := s.Metrics[unit]; found {
+				res = append(res, value)
+			}
+		}
+	}
+	return res
+}
+
+// Results is the content of a result file.
+type Results struct {
+	Records []Record `json:"records"`
+}
+
+// Sort orders the records by vector and revision. Records of different
+// engines keep their relative order, which determines the column order of
+// reports.
+func (r *Results) Sort() {
+	sort.SliceStable(r.Records, func(i, j int) bool {
+		a, b := r.Records[i].Key, r.Records[j].Key
+		if a.Vector != b.Vector {
+			return a.Vector < b.Vector
+		}
+		return a.Revision < b.Revision
+	})
+}
+
+// Lookup returns the record of the given key.
+func (r *Results) Lookup(key Key) (Record, bool) {
+	for _, record := range r.Records {
+		if record.Key == key {
+			return record, true
+		}
+	}
+	return Record{}, false
+}
+
+// Engines returns the engines with records, in order of appearance.
+func (r *Results) Engines() []string {
+	var res []string
+	seen := map[string]bool{}
+	for _, record := range r.Records {
+		if !seen[record.Engine] {
+			seen[record.Engine] = true
+			res = append(res, record.Engine)
+		}
+	}
+	return res
+}
+
+// ReadFile loads a result file, either newline delimited JSON records or a
+// JSON array of records.
+func ReadFile(path string) (*Results, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	res := &Results{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &res.Records); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<24)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		res.Records = append(res.Records, record)
+	}
+	return res, scanner.Err()
+}
+
+// WriteFile stores results as a JSON array if path ends in .json and as
+// newline delimited JSON otherwise.
+func WriteFile(path string, results *Results) error {
+	var buffer bytes.Buffer
+	if filepath.Ext(path) == ".json" {
+		data, err := json.MarshalIndent(results.Records, "", "  ")
+		if err != nil {
+			return err
+		}
+		buffer.Write(append(data, '\n'))
+	} else {
+		encoder := json.NewEncoder(&buffer)
+		for _, record := range results.Records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+	}
+	return os.WriteFile(path, buffer.Bytes(), 0o644)
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package results
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+const benchOutput = `goos: linux
+goarch: amd64
+pkg: tosca-standalone-benchmarks
+BenchmarkContracts/erc20/transfer-8         	  500000	      2345 ns/op	     640 B/op	       9 allocs/op
+BenchmarkContracts/sort/sort-256-8          	     100	   1234567 ns/op	    1.50 IPC	     100 B/op	       1 allocs/op
+--- BENCH: BenchmarkContracts/erc20/transfer
+PASS
+ok  	tosca-standalone-benchmarks	1.234s
+`
+
+func TestParseBenchmarks_ExtractsResultLines(t *testing.T) {
+	benchmarks, err := ParseBenchmarks(strings.NewReader(benchOutput), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(benchmarks) != 2 {
+		t.Fatalf("unexpected benchmarks: %+v", benchmarks)
+	}
+	first := benchmarks[0]
+	if first.Name != "BenchmarkContracts/erc20/transfer" || first.Iterations != 500000 ||
+		first.NsPerOp != 2345 || first.BytesPerOp != 640 || first.AllocsPerOp != 9 {
+		t.Errorf("unexpected first benchmark: %+v", first)
+	}
+	second := benchmarks[1]
+	if second.Name != "BenchmarkContracts/sort/sort-256" || second.Metrics["IPC"] != 1.5 {
+		t.Errorf("unexpected second benchmark: %+v", second)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	s := Summarize([]float64{4, 1, 3, 2})
+	if s.N != 4 || s.Median != 2.5 || s.Min != 1 || s.Max != 4 {
+		t.Errorf("unexpected summary: %+v", s)
+	}
+	if want, got := 0.6, s.Spread(); math.Abs(want-got) > 1e-9 {
+		t.Errorf("unexpected spread, wanted %f, got %f", want, got)
+	}
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	tests := map[string]struct {
+		a, b []float64
+		want float64
+	}{
+		// All 1 of C(10,5)=252 orderings is as extreme on either side.
+		"separated exact":   {[]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}, 2.0 / 252},
+		"identical":         {[]float64{1, 1, 1}, []float64{1, 1, 1}, 1},
+		"interleaved exact": {[]float64{1, 4, 5, 8}, []float64{2, 3, 6, 7}, 1},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := MannWhitneyU(test.a, test.b); math.Abs(test.want-got) > 1e-9 {
+				t.Errorf("unexpected p-value, wanted %f, got %f", test.want, got)
+			}
+		})
+	}
+
+	// Separated samples with ties use the normal approximation.
+	if p := MannWhitneyU([]float64{1, 1, 2, 2, 3, 3}, []float64{7, 7, 8, 8, 9, 9}); p > 0.01 {
+		t.Errorf("expected significant p-value, got %f", p)
+	}
+}
+
+func TestCompare_ReportsSignificantChanges(t *testing.T) {
+	key := Key{Engine: "lfvm", Revision: corpus.Cancun, Vector: "erc20/transfer"}
+	samples := func(values ...float64) []Sample {
+		var res []Sample
+		for _, v := range values {
+			res = append(res, Sample{NsPerOp: v})
+		}
+		return res
+	}
+	old := &Results{Records: []Record{{Key: key, Samples: samples(100, 101, 102, 103, 104)}}}
+	new := &Results{Records: []Record{{Key: key, Samples: samples(90, 91, 92, 93, 94)}}}
+
+	deltas := Compare(old, new, "ns/op")
+	if len(deltas) != 1 {
+		t.Fatalf("unexpected deltas: %+v", deltas)
+	}
+	if d := deltas[0]; !d.Significant(0.05) || math.Abs(d.Change+0.098) > 0.001 {
+		t.Errorf("unexpected delta: %+v", d)
+	}
+}
+
+func TestResults_RoundTrip(t *testing.T) {
+	results := &Results{Records: []Record{{
+		Key:           Key{Engine: "bsc", Revision: corpus.Shanghai, Vector: "v"},
+		EngineVersion: "github.com/bnb-chain/bsc v1.5.10",
+		VectorHash:    "abc",
+		Env:           Env{GoVersion: "go1.23.0", CPU: "Apple M2", GOMAXPROCS: 8},
+		Samples:       []Sample{{Iterations: 10, NsPerOp: 5, Metrics: map[string]float64{"IPC": 2}}},
+		Runs:          []float64{4, 5, 7},
+	}}}
+	for _, name := range []string{"results.ndjson", "results.json"} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := WriteFile(path, results); err != nil {
+			t.Fatal(err)
+		}
+		restored, err := ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		record, found := restored.Lookup(results.Records[0].Key)
+		if !found || record.Samples[0].Metrics["IPC"] != 2 || record.Env != results.Records[0].Env ||
+			record.EngineVersion != results.Records[0].EngineVersion || record.VectorHash != "abc" ||
+			!slices.Equal(record.Values(RunUnit), []float64{4, 5, 7}) {
+			t.Errorf("%s: unexpected results: %+v", name, restored)
+		}
+	}
+}
+
+func TestCompare_FlagsChangedVectorsAndEnvironments(t *testing.T) {
+	key := Key{Engine: "lfvm", Revision: corpus.Cancun, Vector: "v"}
+	samples := []Sample{{NsPerOp: 1}, {NsPerOp: 2}, {NsPerOp: 3}, {NsPerOp: 4}, {NsPerOp: 5}}
+	faster := []Sample{{NsPerOp: 0.1}, {NsPerOp: 0.2}, {NsPerOp: 0.3}, {NsPerOp: 0.4}, {NsPerOp: 0.5}}
+	old := &Results{Records: []Record{{Key: key, VectorHash: "a", Env: Env{CPU: "x", GOGC: "100"}, Samples: samples}}}
+	new := &Results{Records: []Record{{Key: key, VectorHash: "b", Env: Env{CPU: "y", GOGC: "100"}, Samples: faster}}}
+
+	deltas := Compare(old, new, "ns/op")
+	if len(deltas) != 1 {
+		t.Fatalf("unexpected deltas: %+v", deltas)
+	}
+	d := deltas[0]
+	if !d.Incomparable || d.Significant(0.05) {
+		t.Errorf("changed vector must not be significant: %+v", d)
+	}
+	if want, got := "vector changed, cpu", strings.Join(d.Notes, ", "); want != got {
+		t.Errorf("unexpected notes, wanted %q, got %q", want, got)
+	}
+}
+
+func TestOverview_RelatesEnginesToFirst(t *testing.T) {
+	results := &Results{Records: []Record{
+		{Key: Key{Engine: "lfvm", Revision: corpus.Cancun, Vector: "v"}, Samples: []Sample{{NsPerOp: 100}}},
+		{Key: Key{Engine: "bsc", Revision: corpus.Cancun, Vector: "v"}, Samples: []Sample{{NsPerOp: 250}}},
+	}}
+	table := Overview(results)
+	if want, got := "bsc/lfvm", table.Header[len(table.Header)-1]; want != got {
+		t.Errorf("unexpected last column, wanted %q, got %q", want, got)
+	}
+	if len(table.Rows) != 1 || table.Rows[0][len(table.Rows[0])-1] != "2.50x" {
+		t.Errorf("unexpected rows: %v", table.Rows)
+	}
+
+	for format, want := range map[string]string{
+		"markdown": "| v | Cancun | 100 |",
+		"html":     "<td>2.50x</td>",
+		"csv":      "v,Cancun,100,0%",
+	} {
+		var out bytes.Buffer
+		if err := table.Write(&out, format); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("%s output does not contain %q:\n%s", format, want, out.String())
+		}
+	}
+}
+
+func TestLinearFit(t *testing.T) {
+	fit := LinearFit([]float64{0, 1, 2, 3}, []float64{10, 12, 14, 16})
+	if fit.N != 4 || math.Abs(fit.Slope-2) > 1e-9 || math.Abs(fit.Intercept-10) > 1e-9 || math.Abs(fit.R2-1) > 1e-9 {
+		t.Errorf("unexpected fit: %+v", fit)
+	}
+	if fit := LinearFit([]float64{1, 1}, []float64{1, 2}); fit.N >= 2 {
+		t.Errorf("fit of a single x must be undefined, got %+v", fit)
+	}
+}
+
+func TestFamilyFits_RelatesSlopesToFirst(t *testing.T) {
+	family := corpus.Family{Name: "f", Unit: "instruction", Members: []corpus.Member{
+		{Vector: corpus.Vector{Name: "f/small"}, X: 10},
+		{Vector: corpus.Vector{Name: "f/large"}, X: 110},
+	}}
+	record := func(engine, vector string, ns ...float64) Record {
+		res := Record{Key: Key{Engine: engine, Revision: corpus.Cancun, Vector: vector}}
+		for _, v := range ns {
+			res.Samples = append(res.Samples, Sample{NsPerOp: v})
+		}
+		return res
+	}
+	results := &Results{Records: []Record{
+		record("lfvm", "f/small", 60, 60),
+		record("lfvm", "f/large", 160, 160),
+		record("bsc", "f/small", 70),
+		record("bsc", "f/large", 270),
+	}}
+	table := FamilyFits(results, []corpus.Family{family})
+	want := []string{"f", "Cancun", "instruction", "1.00", "50.0", "1.0000", "2.00", "50.0", "1.0000", "2.00x"}
+	if len(table.Rows) != 1 || strings.Join(table.Rows[0], ",") != strings.Join(want, ",") {
+		t.Errorf("unexpected rows: %v", table.Rows)
+	}
+}
+
+func TestGasPricing_FlagsOutliersAgainstMedian(t *testing.T) {
+	family := func(name string, gas uint64) corpus.Family {
+		return corpus.Family{Name: name, Gas: gas, Members: []corpus.Member{
+			{Vector: corpus.Vector{Name: name + "/16"}, X: 16},
+			{Vector: corpus.Vector{Name: name + "/256"}, X: 256},
+		}}
+	}
+	families := []corpus.Family{family("cheap", 10), family("fair", 10), family("slow", 100)}
+	record := func(vector string, ns float64) Record {
+		return Record{Key: Key{Engine: "lfvm", Revision: corpus.Cancun, Vector: vector}, Samples: []Sample{{NsPerOp: ns}}}
+	}
+	// Times per unit of 1, 10 and 10000 ns, i.e. 0.1, 1 and 100 ns per gas.
+	results := &Results{Records: []Record{
+		record("cheap/16", 16), record("cheap/256", 256),
+		record("fair/16", 160), record("fair/256", 2560),
+		record("slow/16", 160_000), record("slow/256", 2_560_000),
+	}}
+	table := GasPricing(results, families, corpus.Cancun, 3)
+	var flags []string
+	for _, row := range table.Rows {
+		flags = append(flags, row[0]+":"+row[len(row)-1])
+	}
+	want := "cheap:lfvm overpriced,fair:,slow:lfvm underpriced"
+	if got := strings.Join(flags, ","); got != want {
+		t.Errorf("unexpected flags: got %q, want %q", got, want)
+	}
+}
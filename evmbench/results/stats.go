@@ -0,0 +1,178 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package results
+
+import (
+	"math"
+	"sort"
+)
+
+// Summary describes the distribution of a metric over samples.
+type Summary struct {
+	N                int
+	Median, Min, Max float64
+}
+
+// Summarize computes the summary of a set of values.
+func Summarize(values []float64) Summary {
+	if len(values) == 0 {
+		return Summary{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + median) / 2
+	}
+	return Summary{N: len(sorted), Median: median, Min: sorted[0], Max: sorted[len(sorted)-1]}
+}
+
+// Spread returns the largest relative deviation of the extremes from the
+// median, as printed by benchstat as ±x%.
+func (s Summary) Spread() float64 {
+	if s.Median == 0 {
+		return 0
+	}
+	return math.Max(s.Max-s.Median, s.Median-s.Min) / s.Median
+}
+
+// MannWhitneyU returns the two-sided p-value of the Mann-Whitney U test,
+// the probability of observing samples this different if both come from
+// the same distribution. Small samples without ties use the exact
+// distribution of U, others the normal approximation with tie correction.
+func MannWhitneyU(a, b []float64) float64 {
+	n1, n2 := len(a), len(b)
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+
+	type value struct {
+		v     float64
+		first bool
+	}
+	all := make([]value, 0, n1+n2)
+	for _, v := range a {
+		all = append(all, value{v, true})
+	}
+	for _, v := range b {
+		all = append(all, value{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// Rank with ties receiving the average of their ranks.
+	rankSum, tieTerm := 0.0, 0.0
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].first {
+				rankSum += rank
+			}
+		}
+		t := float64(j - i)
+		tieTerm += t*t*t - t
+		i = j
+	}
+	u := rankSum - float64(n1*(n1+1))/2
+
+	if tieTerm == 0 && n1+n2 <= 50 {
+		dist := uDistribution(n1, n2, map[[2]int][]float64{})
+		total, below, above := 0.0, 0.0, 0.0
+		for k, count := range dist {
+			total += count
+			if float64(k) <= u {
+				below += count
+			}
+			if float64(k) >= u {
+				above += count
+			}
+		}
+		return math.Min(1, 2*math.Min(below, above)/total)
+	}
+
+	n := float64(n1 + n2)
+	mean := float64(n1*n2) / 2
+	sigma := math.Sqrt(float64(n1*n2) / 12 * ((n + 1) - tieTerm/(n*(n-1))))
+	if sigma == 0 {
+		return 1
+	}
+	z := (math.Abs(u-mean) - 0.5) / sigma
+	if z < 0 {
+		return 1
+	}
+	return math.Erfc(z / math.Sqrt2)
+}
+
+// uDistribution returns the number of orderings of n1 and n2 elements
+// resulting in each value of U.
+func uDistribution(n1, n2 int, memo map[[2]int][]float64) []float64 {
+	if n1 == 0 || n2 == 0 {
+		return []float64{1}
+	}
+	if dist, found := memo[[2]int{n1, n2}]; found {
+		return dist
+	}
+	// The largest element either belongs to the first sample, exceeding
+	// all n2 elements of the second, or to the second sample.
+	withFirst := uDistribution(n1-1, n2, memo)
+	withSecond := uDistribution(n1, n2-1, memo)
+	dist := make([]float64, n1*n2+1)
+	for u, count := range withFirst {
+		dist[u+n2] += count
+	}
+	for u, count := range withSecond {
+		dist[u] += count
+	}
+	memo[[2]int{n1, n2}] = dist
+	return dist
+}
+
+// Delta is the change of a metric between two result files.
+type Delta struct {
+	Key
+	Old, New Summary
+	Change   float64 // relative change of the median, e.g. -0.1 for 10% less
+	P        float64
+	// Notes lists what differs between the measurements besides the
+	// samples, e.g. the engine version or the CPU.
+	Notes []string
+	// Incomparable is set if the vector itself changed. Such deltas are
+	// never significant.
+	Incomparable bool
+}
+
+// Significant reports whether the change is significant at level alpha.
+func (d Delta) Significant(alpha float64) bool {
+	return !d.Incomparable && d.P <= alpha
+}
+
+// Compare computes the change of a metric for all keys present in both
+// result sets.
+func Compare(old, new *Results, unit string) []Delta {
+	var res []Delta
+	for _, after := range new.Records {
+		before, found := old.Lookup(after.Key)
+		if !found {
+			continue
+		}
+		a, b := before.Values(unit), after.Values(unit)
+		delta := Delta{Key: after.Key, Old: Summarize(a), New: Summarize(b), P: MannWhitneyU(a, b)}
+		if delta.Old.Median != 0 {
+			delta.Change = delta.New.Median/delta.Old.Median - 1
+		}
+		if before.VectorHash != after.VectorHash {
+			delta.Incomparable = true
+			delta.Notes = append(delta.Notes, "vector changed")
+		}
+		if before.EngineVersion != after.EngineVersion {
+			delta.Notes = append(delta.Notes, "engine version")
+		}
+		delta.Notes = append(delta.Notes, before.Env.Diff(after.Env)...)
+		res = append(res, delta)
+	}
+	return res
+}
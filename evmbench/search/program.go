@@ -0,0 +1,285 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Package search looks for programs executing as slowly per gas as
+// possible on an engine. Programs are loops over generated snippets of
+// code, each executing one instruction on random operands and leaving the
+// stack as it found it, so that any sequence of snippets is valid. A hill
+// climbing search mutates the snippets of the slowest program found so
+// far, timing every candidate under the same gas budget.
+package search
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/asm"
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// Operand kinds of the generated instructions.
+const (
+	word    = iota // any 256 bit value
+	offset         // memory or code offset below 1 KiB
+	size           // byte count of at most 1 KiB
+	slot           // one of 16 storage slots
+	address        // the recipient, the caller, a precompile or a cold account
+	shift          // bit or byte index
+	number         // block number
+	gas            // all remaining gas, for calls
+)
+
+// instruction is an opcode the generator places into snippets together
+// with the kinds of its operands, topmost first.
+type instruction struct {
+	name     string
+	since    corpus.Revision
+	operands []int
+	results  int
+}
+
+// instructions are all opcodes that neither end the execution, jump nor
+// create or call accounts with value, i.e. that keep the loop running.
+var instructions = []instruction{
+	{"ADD", corpus.Istanbul, []int{word, word}, 1},
+	{"MUL", corpus.Istanbul, []int{word, word}, 1},
+	{"SUB", corpus.Istanbul, []int{word, word}, 1},
+	{"DIV", corpus.Istanbul, []int{word, word}, 1},
+	{"SDIV", corpus.Istanbul, []int{word, word}, 1},
+	{"MOD", corpus.Istanbul, []int{word, word}, 1},
+	{"SMOD", corpus.Istanbul, []int{word, word}, 1},
+	{"ADDMOD", corpus.Istanbul, []int{word, word, word}, 1},
+	{"MULMOD", corpus.Istanbul, []int{word, word, word}, 1},
+	{"EXP", corpus.Istanbul, []int{word, word}, 1},
+	{"SIGNEXTEND", corpus.Istanbul, []int{shift, word}, 1},
+	{"LT", corpus.Istanbul, []int{word, word}, 1},
+	{"GT", corpus.Istanbul, []int{word, word}, 1},
+	{"SLT", corpus.Istanbul, []int{word, word}, 1},
+	{"SGT", corpus.Istanbul, []int{word, word}, 1},
+	{"EQ", corpus.Istanbul, []int{word, word}, 1},
+	{"ISZERO", corpus.Istanbul, []int{word}, 1},
+	{"AND", corpus.Istanbul, []int{word, word}, 1},
+	{"OR", corpus.Istanbul, []int{word, word}, 1},
+	{"XOR", corpus.Istanbul, []int{word, word}, 1},
+	{"NOT", corpus.Istanbul, []int{word}, 1},
+	{"BYTE", corpus.Istanbul, []int{shift, word}, 1},
+	{"SHL", corpus.Istanbul, []int{shift, word}, 1},
+	{"SHR", corpus.Istanbul, []int{shift, word}, 1},
+	{"SAR", corpus.Istanbul, []int{shift, word}, 1},
+	{"KECCAK256", corpus.Istanbul, []int{offset, size}, 1},
+	{"ADDRESS", corpus.Istanbul, nil, 1},
+	{"BALANCE", corpus.Istanbul, []int{address}, 1},
+	{"ORIGIN", corpus.Istanbul, nil, 1},
+	{"CALLER", corpus.Istanbul, nil, 1},
+	{"CALLVALUE", corpus.Istanbul, nil, 1},
+	{"CALLDATALOAD", corpus.Istanbul, []int{offset}, 1},
+	{"CALLDATASIZE", corpus.Istanbul, nil, 1},
+	{"CALLDATACOPY", corpus.Istanbul, []int{offset, offset, size}, 0},
+	{"CODESIZE", corpus.Istanbul, nil, 1},
+	{"CODECOPY", corpus.Istanbul, []int{offset, offset, size}, 0},
+	{"GASPRICE", corpus.Istanbul, nil, 1},
+	{"EXTCODESIZE", corpus.Istanbul, []int{address}, 1},
+	{"EXTCODECOPY", corpus.Istanbul, []int{address, offset, offset, size}, 0},
+	{"RETURNDATASIZE", corpus.Istanbul, nil, 1},
+	{"EXTCODEHASH", corpus.Istanbul, []int{address}, 1},
+	{"BLOCKHASH", corpus.Istanbul, []int{number}, 1},
+	{"COINBASE", corpus.Istanbul, nil, 1},
+	{"TIMESTAMP", corpus.Istanbul, nil, 1},
+	{"NUMBER", corpus.Istanbul, nil, 1},
+	{"PREVRANDAO", corpus.Istanbul, nil, 1},
+	{"GASLIMIT", corpus.Istanbul, nil, 1},
+	{"CHAINID", corpus.Istanbul, nil, 1},
+	{"SELFBALANCE", corpus.Istanbul, nil, 1},
+	{"BASEFEE", corpus.London, nil, 1},
+	{"BLOBHASH", corpus.Cancun, []int{word}, 1},
+	{"BLOBBASEFEE", corpus.Cancun, nil, 1},
+	{"MLOAD", corpus.Istanbul, []int{offset}, 1},
+	{"MSTORE", corpus.Istanbul, []int{offset, word}, 0},
+	{"MSTORE8", corpus.Istanbul, []int{offset, word}, 0},
+	{"SLOAD", corpus.Istanbul, []int{slot}, 1},
+	{"SSTORE", corpus.Istanbul, []int{slot, word}, 0},
+	{"PC", corpus.Istanbul, nil, 1},
+	{"MSIZE", corpus.Istanbul, nil, 1},
+	{"GAS", corpus.Istanbul, nil, 1},
+	{"TLOAD", corpus.Cancun, []int{slot}, 1},
+	{"TSTORE", corpus.Cancun, []int{slot, word}, 0},
+	{"MCOPY", corpus.Cancun, []int{offset, offset, size}, 0},
+	{"PUSH0", corpus.Shanghai, nil, 1},
+	{"DUP1", corpus.Istanbul, []int{word}, 2},
+	{"SWAP1", corpus.Istanbul, []int{word, word}, 2},
+	{"LOG0", corpus.Istanbul, []int{offset, size}, 0},
+	{"LOG1", corpus.Istanbul, []int{offset, size, word}, 0},
+	{"LOG2", corpus.Istanbul, []int{offset, size, word, word}, 0},
+	{"LOG3", corpus.Istanbul, []int{offset, size, word, word, word}, 0},
+	{"LOG4", corpus.Istanbul, []int{offset, size, word, word, word, word}, 0},
+	{"STATICCALL", corpus.Istanbul, []int{gas, address, offset, size, offset, size}, 1},
+}
+
+// Snippet is one instruction with its operands pushed before and its
+// results popped after it.
+type Snippet struct {
+	Instruction string
+	Source      string // assembly source
+}
+
+// Program is a sequence of snippets executed in a loop until the gas is
+// exhausted.
+type Program []Snippet
+
+// Code assembles the loop over the snippets.
+func (p Program) Code() []byte {
+	source := make([]string, 0, len(p)+2)
+	source = append(source, "JUMPDEST")
+	for _, snippet := range p {
+		source = append(source, snippet.Source)
+	}
+	source = append(source, "PUSH1 0 JUMP")
+	return asm.MustAssemble(strings.Join(source, "\n"))
+}
+
+// String lists the instructions of the snippets, without their operands.
+func (p Program) String() string {
+	names := make([]string, len(p))
+	for i, snippet := range p {
+		names[i] = snippet.Instruction
+	}
+	return strings.Join(names, " ")
+}
+
+// Generator produces random snippets of the instructions available in a
+// revision.
+type Generator struct {
+	rand         *rand.Rand
+	instructions []instruction
+}
+
+// NewGenerator returns a generator for the given revision, deterministic
+// for a given seed.
+func NewGenerator(revision corpus.Revision, seed int64) *Generator {
+	g := &Generator{rand: rand.New(rand.NewSource(seed))}
+	for _, instruction := range instructions {
+		if instruction.since <= revision {
+			g.instructions = append(g.instructions, instruction)
+		}
+	}
+	return g
+}
+
+// Program returns a program of n random snippets.
+func (g *Generator) Program(n int) Program {
+	res := make(Program, n)
+	for i := range res {
+		res[i] = g.Snippet()
+	}
+	return res
+}
+
+// Snippet returns a random instruction on random operands.
+func (g *Generator) Snippet() Snippet {
+	return g.snippet(g.instructions[g.rand.Intn(len(g.instructions))])
+}
+
+func (g *Generator) snippet(instruction instruction) Snippet {
+	var source []string
+	for i := len(instruction.operands) - 1; i >= 0; i-- {
+		source = append(source, g.operand(instruction.operands[i]))
+	}
+	source = append(source, instruction.name)
+	for range instruction.results {
+		source = append(source, "POP")
+	}
+	return Snippet{Instruction: instruction.name, Source: strings.Join(source, " ")}
+}
+
+// reroll returns a snippet of the same instruction with new operands.
+func (g *Generator) reroll(snippet Snippet) Snippet {
+	for _, instruction := range g.instructions {
+		if instruction.name == snippet.Instruction {
+			return g.snippet(instruction)
+		}
+	}
+	return g.Snippet()
+}
+
+// operand returns the source pushing a random operand of the given kind.
+func (g *Generator) operand(kind int) string {
+	r := g.rand
+	switch kind {
+	case offset:
+		return fmt.Sprintf("PUSH2 %d", r.Intn(1024))
+	case size:
+		sizes := []int{0, 1, 32, r.Intn(1024) + 1, 1024}
+		return fmt.Sprintf("PUSH2 %d", sizes[r.Intn(len(sizes))])
+	case slot:
+		return fmt.Sprintf("PUSH1 %d", r.Intn(16))
+	case address:
+		switch r.Intn(4) {
+		case 0:
+			return "PUSH20 " + corpus.DefaultRecipient.String()
+		case 1:
+			return "PUSH20 " + corpus.DefaultCaller.String()
+		case 2:
+			return fmt.Sprintf("PUSH1 %d", 1+r.Intn(9)) // precompiles
+		}
+		cold := corpus.Address{0xbb}
+		r.Read(cold[16:])
+		return "PUSH20 " + cold.String()
+	case shift:
+		return fmt.Sprintf("PUSH1 %d", r.Intn(256))
+	case number:
+		return fmt.Sprintf("PUSH2 %d", r.Intn(corpus.BlockNumber+257))
+	case gas:
+		return "GAS"
+	}
+	switch r.Intn(6) {
+	case 0:
+		return "PUSH1 0"
+	case 1:
+		return "PUSH1 1"
+	case 2:
+		return fmt.Sprintf("PUSH1 %d", r.Intn(256))
+	case 3:
+		return "PUSH32 0x" + strings.Repeat("ff", 32)
+	case 4:
+		return "PUSH32 0x80" + strings.Repeat("00", 31)
+	}
+	value := make([]byte, 32)
+	r.Read(value[:])
+	value[0] |= 1 // keep the full width
+	return "PUSH32 " + corpus.Bytes(value).String()
+}
+
+// Mutate returns a copy of the program with a random change: a snippet
+// inserted, removed, replaced, duplicated or given new operands, or two
+// snippets swapped. Programs stay between 1 and maxLength snippets.
+func (g *Generator) Mutate(p Program, maxLength int) Program {
+	res := append(Program(nil), p...)
+	r := g.rand
+	i := r.Intn(len(res))
+	switch r.Intn(6) {
+	case 0:
+		if len(res) < maxLength {
+			return append(res[:i], append(Program{g.Snippet()}, res[i:]...)...)
+		}
+		res[i] = g.Snippet()
+	case 1:
+		if len(res) > 1 {
+			return append(res[:i], res[i+1:]...)
+		}
+		res[i] = g.Snippet()
+	case 2:
+		res[i] = g.Snippet()
+	case 3:
+		if len(res) < maxLength {
+			return append(res[:i+1], append(Program{res[i]}, res[i+1:]...)...)
+		}
+		res[r.Intn(len(res))] = res[i]
+	case 4:
+		res[i] = g.reroll(res[i])
+	default:
+		j := r.Intn(len(res))
+		res[i], res[j] = res[j], res[i]
+	}
+	return res
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package search
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// Config parameterizes a search.
+type Config struct {
+	Revision    corpus.Revision
+	Gas         uint64 // budget of every program
+	Length      int    // snippets of the initial program
+	MaxLength   int    // snippets a program may grow to
+	Generations int
+	Children    int   // mutants of the incumbent per generation
+	Keep        int   // offenders returned
+	Seed        int64 // of the generator, making searches repeatable
+}
+
+// Measurement is the timing of a program on the searched engine.
+type Measurement struct {
+	NsPerGas float64
+	Outcome  corpus.Outcome
+}
+
+// Measure times the execution of a vector on an engine.
+type Measure func(vector corpus.Vector) (Measurement, error)
+
+// Offender is a program found by the search with its latest measurement.
+type Offender struct {
+	Program Program
+	Vector  corpus.Vector
+	Measurement
+}
+
+// Search climbs from a random program towards the slowest program per gas.
+// Every generation mutates the incumbent into children; the slowest child
+// replaces it if slower than the incumbent, which is measured again every
+// generation so that a lucky measurement does not stick. The slowest
+// distinct programs measured are re-measured and returned, slowest first.
+// Progress is called after every generation with the incumbent.
+func Search(config Config, measure Measure, progress func(generation int, incumbent Offender)) ([]Offender, error) {
+	generator := NewGenerator(config.Revision, config.Seed)
+	seen := map[string]Offender{}
+	evaluate := func(program Program) (Offender, error) {
+		vector := corpus.Vector{Code: program.Code(), Gas: config.Gas}
+		vector.Name = "search/" + vector.Hash()[:12]
+		measurement, err := measure(vector)
+		if err != nil {
+			return Offender{}, fmt.Errorf("%s: %w", program, err)
+		}
+		offender := Offender{Program: program, Vector: vector, Measurement: measurement}
+		seen[vector.Name] = offender
+		return offender, nil
+	}
+
+	incumbent, err := evaluate(generator.Program(config.Length))
+	if err != nil {
+		return nil, err
+	}
+	for generation := 1; generation <= config.Generations; generation++ {
+		best := Offender{}
+		for range config.Children {
+			child, err := evaluate(generator.Mutate(incumbent.Program, config.MaxLength))
+			if err != nil {
+				return nil, err
+			}
+			if child.NsPerGas > best.NsPerGas {
+				best = child
+			}
+		}
+		if incumbent, err = evaluate(incumbent.Program); err != nil {
+			return nil, err
+		}
+		if best.NsPerGas > incumbent.NsPerGas {
+			incumbent = best
+		}
+		if progress != nil {
+			progress(generation, incumbent)
+		}
+	}
+
+	offenders := make([]Offender, 0, len(seen))
+	for _, offender := range seen {
+		offenders = append(offenders, offender)
+	}
+	slowestFirst := func(a, b Offender) int {
+		if a.NsPerGas != b.NsPerGas {
+			if a.NsPerGas > b.NsPerGas {
+				return -1
+			}
+			return 1
+		}
+		return slices.Compare(a.Vector.Code, b.Vector.Code)
+	}
+	slices.SortFunc(offenders, slowestFirst)
+	offenders = offenders[:min(config.Keep, len(offenders))]
+	for i, offender := range offenders {
+		if offenders[i], err = evaluate(offender.Program); err != nil {
+			return nil, err
+		}
+	}
+	slices.SortFunc(offenders, slowestFirst)
+	for i := range offenders {
+		outcome := offenders[i].Outcome
+		offenders[i].Vector.Expect = &outcome
+	}
+	return offenders, nil
+}
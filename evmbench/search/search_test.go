@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+func TestGenerator_ProducesLoopsOfRevision(t *testing.T) {
+	generator := NewGenerator(corpus.Istanbul, 1)
+	program := generator.Program(4)
+	for range 1000 {
+		program = generator.Mutate(program, 8)
+		if len(program) < 1 || len(program) > 8 {
+			t.Fatalf("program of %d snippets", len(program))
+		}
+		code := program.Code()
+		if code[0] != 0x5b || code[len(code)-1] != 0x56 {
+			t.Fatalf("not a loop: %x", code)
+		}
+		for _, snippet := range program {
+			switch snippet.Instruction {
+			case "PUSH0", "BASEFEE", "TLOAD", "TSTORE", "MCOPY", "BLOBHASH", "BLOBBASEFEE":
+				t.Fatalf("%s is not available in Istanbul", snippet.Instruction)
+			}
+			if !strings.Contains(snippet.Source, snippet.Instruction) {
+				t.Fatalf("snippet %q lacks its instruction %s", snippet.Source, snippet.Instruction)
+			}
+		}
+	}
+}
+
+func TestSearch_ClimbsTowardsSlowerPrograms(t *testing.T) {
+	// The fake engine takes 1ns per gas for every 0x20 byte of the code,
+	// KECCAK256 or push data.
+	measure := func(vector corpus.Vector) (Measurement, error) {
+		keccaks := strings.Count(string(vector.Code), "\x20")
+		return Measurement{NsPerGas: float64(keccaks), Outcome: corpus.Outcome{Status: corpus.Failure, GasUsed: vector.Gas}}, nil
+	}
+	config := Config{Revision: corpus.Cancun, Gas: 1000, Length: 4, MaxLength: 16, Generations: 200, Children: 4, Keep: 3, Seed: 7}
+	var first, last float64
+	offenders, err := Search(config, measure, func(generation int, incumbent Offender) {
+		if generation == 1 {
+			first = incumbent.NsPerGas
+		}
+		last = incumbent.NsPerGas
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last <= first {
+		t.Errorf("search did not climb: %.0f after the first generation, %.0f at the end", first, last)
+	}
+	if len(offenders) != 3 {
+		t.Fatalf("got %d offenders", len(offenders))
+	}
+	for i, offender := range offenders {
+		if i > 0 && offender.NsPerGas > offenders[i-1].NsPerGas {
+			t.Errorf("offenders not sorted: %v", offenders)
+		}
+		if offender.Vector.Gas != 1000 || offender.Vector.Expect == nil || offender.Vector.Expect.GasUsed != 1000 {
+			t.Errorf("unexpected vector %+v", offender.Vector)
+		}
+	}
+}
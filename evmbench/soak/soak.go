@@ -0,0 +1,335 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Package soak runs a workload on a long-lived engine for a fixed duration
+// and samples runtime metrics of the process into a time series: the heap,
+// the GC cycles and the percentiles of their pauses, goroutines and the
+// entries of the engine's code cache. Check judges from the series whether
+// memory, the cache or goroutines keep growing after the warm-up, as a leak
+// in a caching path would.
+package soak
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime/metrics"
+	"slices"
+	"time"
+
+	"github.com/sonicoperations/evmbench/results"
+)
+
+// Sample holds the runtime metrics of the process at one point of a soak
+// run. GC pauses are those of the interval since the previous sample;
+// durations are in nanoseconds.
+type Sample struct {
+	Engine       string        `json:"engine"`
+	Elapsed      time.Duration `json:"elapsed"`
+	Runs         uint64        `json:"runs"`         // executions so far
+	HeapObjects  uint64        `json:"heapObjects"`  // bytes of heap objects, live or not yet swept
+	HeapLive     uint64        `json:"heapLive"`     // bytes marked live by the last GC
+	GCCycles     uint64        `json:"gcCycles"`     // completed so far
+	PauseP50     time.Duration `json:"pauseP50"`     // of the interval, 0 without GC
+	PauseP99     time.Duration `json:"pauseP99"`     // of the interval, 0 without GC
+	PauseMax     time.Duration `json:"pauseMax"`     // of the interval, 0 without GC
+	Goroutines   uint64        `json:"goroutines"`   // at the time of the sample
+	CacheEntries int           `json:"cacheEntries"` // of the engine's code cache
+}
+
+// Config configures a soak run.
+type Config struct {
+	Engine   string
+	Duration time.Duration
+	Interval time.Duration // between samples
+	// Out is the time series file, newline delimited JSON rewritten after
+	// every sample so that an aborted run leaves its samples behind. No
+	// file is written if it is empty.
+	Out string
+}
+
+// Run calls step with the number of the execution until the duration has
+// passed, taking a sample every interval and at the end, and returns the
+// samples. cache reports the entries of the engine's code cache. A failing
+// step ends the run with its error.
+func Run(config Config, step func(run uint64) error, cache func() int) ([]Sample, error) {
+	if config.Interval <= 0 || config.Duration < config.Interval {
+		return nil, fmt.Errorf("soak duration %v must be at least one interval of %v", config.Duration, config.Interval)
+	}
+	sampler := newSampler(config.Engine)
+	var samples []Sample
+	record := func(runs uint64) error {
+		samples = append(samples, sampler.sample(runs, cache()))
+		if config.Out == "" {
+			return nil
+		}
+		return WriteFile(config.Out, samples)
+	}
+	if err := record(0); err != nil {
+		return nil, err
+	}
+	next := sampler.start.Add(config.Interval)
+	end := sampler.start.Add(config.Duration)
+	var runs uint64
+	for {
+		// Reading the clock is cheap, but not compared to short executions.
+		for i := 0; i < 64; i++ {
+			if err := step(runs); err != nil {
+				return samples, fmt.Errorf("run %d: %w", runs, err)
+			}
+			runs++
+		}
+		now := time.Now()
+		if now.Before(next) {
+			continue
+		}
+		if err := record(runs); err != nil {
+			return samples, err
+		}
+		if !now.Before(end) {
+			return samples, nil
+		}
+		next = next.Add(config.Interval)
+	}
+}
+
+// metric names read by the sampler.
+const (
+	heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+	heapLiveMetric    = "/gc/heap/live:bytes"
+	gcCyclesMetric    = "/gc/cycles/total:gc-cycles"
+	gcPausesMetric    = "/sched/pauses/total/gc:seconds"
+	goroutinesMetric  = "/sched/goroutines:goroutines"
+)
+
+type sampler struct {
+	engine string
+	start  time.Time
+	values []metrics.Sample
+	pauses []uint64 // cumulative pause counts per bucket at the last sample
+}
+
+func newSampler(engine string) *sampler {
+	s := &sampler{engine: engine, start: time.Now()}
+	for _, name := range []string{heapObjectsMetric, heapLiveMetric, gcCyclesMetric, gcPausesMetric, goroutinesMetric} {
+		s.values = append(s.values, metrics.Sample{Name: name})
+	}
+	metrics.Read(s.values)
+	// Read reuses the memory of histograms, so the counts are copied.
+	s.pauses = slices.Clone(histogram(s.values[3].Value).Counts)
+	return s
+}
+
+func (s *sampler) sample(runs uint64, cacheEntries int) Sample {
+	metrics.Read(s.values)
+	pauses := histogram(s.values[3].Value)
+	res := Sample{
+		Engine:       s.engine,
+		Elapsed:      time.Since(s.start),
+		Runs:         runs,
+		HeapObjects:  uint64Value(s.values[0].Value),
+		HeapLive:     uint64Value(s.values[1].Value),
+		GCCycles:     uint64Value(s.values[2].Value),
+		Goroutines:   uint64Value(s.values[4].Value),
+		CacheEntries: cacheEntries,
+	}
+	interval := make([]uint64, len(pauses.Counts))
+	for i, count := range pauses.Counts {
+		if i < len(s.pauses) {
+			count -= s.pauses[i]
+		}
+		interval[i] = count
+	}
+	res.PauseP50 = percentile(interval, pauses.Buckets, 0.5)
+	res.PauseP99 = percentile(interval, pauses.Buckets, 0.99)
+	res.PauseMax = percentile(interval, pauses.Buckets, 1)
+	s.pauses = append(s.pauses[:0], pauses.Counts...)
+	return res
+}
+
+// histogram returns the histogram of a metric, empty if the runtime does
+// not provide it.
+func histogram(value metrics.Value) *metrics.Float64Histogram {
+	if value.Kind() != metrics.KindFloat64Histogram {
+		return &metrics.Float64Histogram{}
+	}
+	return value.Float64Histogram()
+}
+
+func uint64Value(value metrics.Value) uint64 {
+	if value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return value.Uint64()
+}
+
+// percentile returns the upper bound of the bucket holding the p-quantile
+// of counts, the lower bound for the last, unbounded bucket, or 0 if there
+// are no counts. Buckets has one boundary more than counts.
+func percentile(counts []uint64, buckets []float64, p float64) time.Duration {
+	total := uint64(0)
+	for _, count := range counts {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(p * float64(total)))
+	sum := uint64(0)
+	for i, count := range counts {
+		sum += count
+		if count > 0 && sum >= rank {
+			bound := buckets[i+1]
+			if math.IsInf(bound, 1) {
+				bound = buckets[i]
+			}
+			return time.Duration(bound * float64(time.Second))
+		}
+	}
+	return 0
+}
+
+// Limits bound the growth Check tolerates after the warm-up.
+type Limits struct {
+	Growth   float64 // relative growth of the heap and the cache, e.g. 0.25
+	HeapSlop uint64  // bytes the live heap may grow if more than Growth allows
+}
+
+// DefaultLimits tolerate a quarter of growth, or 16 MiB of live heap.
+var DefaultLimits = Limits{Growth: 0.25, HeapSlop: 16 << 20}
+
+// ErrTooFewSamples is returned by Check for series too short to judge.
+var ErrTooFewSamples = errors.New("too few samples to judge growth, at least 6 are needed")
+
+// Check judges whether the series keeps growing. The first third of the
+// samples warms up caches and pools and is ignored; the maxima of the live
+// heap, the cache entries and the goroutines of the last third are compared
+// with those of the middle third. Bounded memory has levelled off by then,
+// while a leak keeps raising the maxima.
+func Check(samples []Sample, limits Limits) error {
+	if len(samples) < 6 {
+		return ErrTooFewSamples
+	}
+	third := len(samples) / 3
+	middle, last := peaks(samples[third:len(samples)-third]), peaks(samples[len(samples)-third:])
+	var errs []error
+	if last.HeapLive > max(uint64(float64(middle.HeapLive)*(1+limits.Growth)), middle.HeapLive+limits.HeapSlop) {
+		errs = append(errs, fmt.Errorf("live heap grew from %d to %d bytes", middle.HeapLive, last.HeapLive))
+	}
+	if float64(last.CacheEntries) > float64(middle.CacheEntries)*(1+limits.Growth) {
+		errs = append(errs, fmt.Errorf("code cache grew from %d to %d entries", middle.CacheEntries, last.CacheEntries))
+	}
+	if last.Goroutines > middle.Goroutines {
+		errs = append(errs, fmt.Errorf("goroutines grew from %d to %d", middle.Goroutines, last.Goroutines))
+	}
+	return errors.Join(errs...)
+}
+
+// peaks returns the maxima of the samples.
+func peaks(samples []Sample) Sample {
+	var res Sample
+	for _, sample := range samples {
+		res.HeapLive = max(res.HeapLive, sample.HeapLive)
+		res.CacheEntries = max(res.CacheEntries, sample.CacheEntries)
+		res.Goroutines = max(res.Goroutines, sample.Goroutines)
+	}
+	return res
+}
+
+// Summary tabulates soak runs, one series of samples each: their runs per
+// second, the peak live heap of the middle and the last third, the GC
+// cycles, the worst interval's pause percentiles, the final cache entries,
+// the peak goroutines and the verdict of Check.
+func Summary(series [][]Sample, limits Limits) results.Table {
+	table := results.Table{Header: []string{"Engine", "Duration", "Runs/s", "Live heap MiB", "GC cycles", "Pause p50", "Pause p99", "Pause max", "Cache entries", "Goroutines", "Verdict"}}
+	mib := func(bytes uint64) string { return fmt.Sprintf("%.1f", float64(bytes)/(1<<20)) }
+	for _, samples := range series {
+		if len(samples) == 0 {
+			continue
+		}
+		final := samples[len(samples)-1]
+		var worst Sample
+		for _, sample := range samples {
+			worst.PauseP50 = max(worst.PauseP50, sample.PauseP50)
+			worst.PauseP99 = max(worst.PauseP99, sample.PauseP99)
+			worst.PauseMax = max(worst.PauseMax, sample.PauseMax)
+			worst.Goroutines = max(worst.Goroutines, sample.Goroutines)
+		}
+		heap := "-"
+		if len(samples) >= 6 {
+			third := len(samples) / 3
+			middle, last := peaks(samples[third:len(samples)-third]), peaks(samples[len(samples)-third:])
+			heap = mib(middle.HeapLive) + " -> " + mib(last.HeapLive)
+		}
+		verdict := "ok"
+		if err := Check(samples, limits); err != nil {
+			verdict = err.Error()
+		}
+		table.Rows = append(table.Rows, []string{
+			final.Engine,
+			final.Elapsed.Round(time.Second).String(),
+			fmt.Sprintf("%.0f", float64(final.Runs)/final.Elapsed.Seconds()),
+			heap,
+			fmt.Sprint(final.GCCycles - samples[0].GCCycles),
+			worst.PauseP50.String(),
+			worst.PauseP99.String(),
+			worst.PauseMax.String(),
+			fmt.Sprint(final.CacheEntries),
+			fmt.Sprint(worst.Goroutines),
+			verdict,
+		})
+	}
+	return table
+}
+
+// WriteFile writes samples as newline delimited JSON, replacing the content
+// of the file at path.
+func WriteFile(path string, samples []Sample) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	for _, sample := range samples {
+		if err := encoder.Encode(sample); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+// Read parses newline delimited JSON samples.
+func Read(r io.Reader) ([]Sample, error) {
+	var samples []Sample
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var sample Sample
+		if err := json.Unmarshal(scanner.Bytes(), &sample); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		samples = append(samples, sample)
+	}
+	return samples, scanner.Err()
+}
+
+// ReadFile reads the samples of a time series file.
+func ReadFile(path string) ([]Sample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	samples, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return samples, nil
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package soak
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+func series(heap ...uint64) []Sample {
+	var res []Sample
+	for i, bytes := range heap {
+		res = append(res, Sample{Engine: "lfvm", Elapsed: time.Duration(i) * time.Second, HeapLive: bytes << 20, CacheEntries: 100, Goroutines: 3})
+	}
+	return res
+}
+
+func TestCheck_ToleratesLevelledOffHeap(t *testing.T) {
+	// Warm-up growth and noise within the limits pass.
+	if err := Check(series(1, 50, 100, 104, 98, 110, 101, 112, 105), DefaultLimits); err != nil {
+		t.Errorf("unexpected verdict: %v", err)
+	}
+}
+
+func TestCheck_FlagsGrowingHeap(t *testing.T) {
+	err := Check(series(10, 20, 30, 40, 50, 60, 70, 80, 90), DefaultLimits)
+	if err == nil || !strings.Contains(err.Error(), "live heap grew") {
+		t.Errorf("growth not flagged: %v", err)
+	}
+}
+
+func TestCheck_FlagsGrowingCacheAndGoroutines(t *testing.T) {
+	samples := series(1, 1, 1, 1, 1, 1)
+	samples[5].CacheEntries = 200
+	samples[5].Goroutines = 4
+	err := Check(samples, DefaultLimits)
+	if err == nil || !strings.Contains(err.Error(), "code cache grew from 100 to 200") || !strings.Contains(err.Error(), "goroutines grew from 3 to 4") {
+		t.Errorf("growth not flagged: %v", err)
+	}
+}
+
+func TestCheck_RejectsShortSeries(t *testing.T) {
+	if err := Check(series(1, 2, 3), DefaultLimits); !errors.Is(err, ErrTooFewSamples) {
+		t.Errorf("unexpected verdict: %v", err)
+	}
+}
+
+func TestPercentile_ReturnsBucketBounds(t *testing.T) {
+	buckets := []float64{0, 1e-6, 1e-5, 1e-4, math.Inf(1)}
+	counts := []uint64{90, 9, 0, 1}
+	for _, test := range []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0.5, time.Microsecond},
+		{0.95, 10 * time.Microsecond},
+		{0.99, 10 * time.Microsecond},
+		{1, 100 * time.Microsecond},
+	} {
+		if got := percentile(counts, buckets, test.p); got != test.want {
+			t.Errorf("p%v: wanted %v, got %v", test.p, test.want, got)
+		}
+	}
+	if got := percentile(make([]uint64, 4), buckets, 0.5); got != 0 {
+		t.Errorf("percentile of no pauses: %v", got)
+	}
+}
+
+func TestRun_SamplesEveryIntervalIntoFile(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "soak.ndjson")
+	samples, err := Run(Config{Engine: "test", Duration: 200 * time.Millisecond, Interval: 40 * time.Millisecond, Out: out}, func(run uint64) error {
+		if run%1000 == 0 {
+			runtime.GC()
+		}
+		return nil
+	}, func() int { return 7 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	final := samples[len(samples)-1]
+	if len(samples) < 5 || samples[0].Runs != 0 || final.Runs == 0 || final.Elapsed < 200*time.Millisecond || final.GCCycles <= samples[0].GCCycles {
+		t.Errorf("unexpected samples %+v", samples)
+	}
+	for _, sample := range samples[1:] {
+		if sample.PauseMax == 0 || sample.PauseP50 > sample.PauseP99 || sample.PauseP99 > sample.PauseMax {
+			t.Errorf("unexpected pauses of interval with GC %+v", sample)
+		}
+	}
+	for _, sample := range samples {
+		if sample.Engine != "test" || sample.CacheEntries != 7 || sample.Goroutines == 0 || sample.HeapObjects == 0 {
+			t.Errorf("unexpected sample %+v", sample)
+		}
+	}
+	read, err := ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != len(samples) || read[len(read)-1] != samples[len(samples)-1] {
+		t.Errorf("file holds %+v, wanted %+v", read, samples)
+	}
+}
+
+func TestRun_StopsOnFailingStep(t *testing.T) {
+	failure := errors.New("boom")
+	_, err := Run(Config{Duration: time.Second, Interval: 100 * time.Millisecond}, func(run uint64) error {
+		if run == 10 {
+			return failure
+		}
+		return nil
+	}, func() int { return 0 })
+	if !errors.Is(err, failure) || !strings.Contains(err.Error(), "run 10") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestWorkload_InterleavesFreshCode(t *testing.T) {
+	fresh := []corpus.Vector{{Name: "a", Code: corpus.Bytes{0x00}}, {Name: "b", Code: corpus.Bytes{0x01}}}
+	workload := Workload{Mix: append(fresh, corpus.Vector{Name: "c"}), Fresh: fresh, Every: 2}
+	var steps []string
+	hashes := map[string]bool{}
+	for n := range uint64(6) {
+		i, code := workload.Step(n)
+		if code == nil {
+			steps = append(steps, workload.Mix[i].Name)
+			continue
+		}
+		if !bytes.HasPrefix(code, workload.Fresh[i].Code) {
+			t.Errorf("fresh code %x does not start with that of %s", code, workload.Fresh[i].Name)
+		}
+		hashes[string(code)] = true
+		steps = append(steps, "fresh "+workload.Fresh[i].Name)
+	}
+	if want, got := "fresh a,b,fresh b,a,fresh a,c", strings.Join(steps, ","); want != got {
+		t.Errorf("unexpected steps, wanted %q, got %q", want, got)
+	}
+	if len(hashes) != 3 {
+		t.Errorf("fresh code repeats: %d distinct of 3", len(hashes))
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package soak
+
+import (
+	"encoding/binary"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// Workload is the mix TestSoak and BenchmarkMixedWorkload of the engine
+// modules execute round-robin on a long-lived engine.
+type Workload struct {
+	Mix []corpus.Vector
+	// Fresh are the vectors of the contract corpus, which every Every-th
+	// execution calls with code never seen before; never if Every is 0.
+	Fresh []corpus.Vector
+	Every int
+}
+
+// NewWorkload returns the workload of the contract corpus, the failure
+// vectors and the deployments executed in revision.
+func NewWorkload(revision corpus.Revision, every int) (Workload, error) {
+	root, err := corpus.Root()
+	if err != nil {
+		return Workload{}, err
+	}
+	contracts, err := corpus.LoadContracts(root)
+	if err != nil {
+		return Workload{}, err
+	}
+	deployments, err := corpus.Deployments(revision)
+	if err != nil {
+		return Workload{}, err
+	}
+	res := Workload{Every: every}
+	for _, contract := range contracts {
+		res.Fresh = append(res.Fresh, contract.Vectors()...)
+	}
+	res.Mix = append(append(append(res.Mix, res.Fresh...), corpus.FailureVectors()...), deployments...)
+	return res, nil
+}
+
+// Step returns the vector of the n-th execution, an index into Mix, or for
+// executions of fresh code an index into Fresh and the code replacing that
+// of the vector.
+func (w Workload) Step(n uint64) (i int, fresh []byte) {
+	if w.Every > 0 && n%uint64(w.Every) == 0 {
+		i = int(n/uint64(w.Every)) % len(w.Fresh)
+		return i, FreshCode(w.Fresh[i].Code, n)
+	}
+	return int(n) % len(w.Mix), nil
+}
+
+// FreshCode returns code behaving like the given one but with a new code
+// hash for every n: a STOP and a PUSH8 of n that is never reached, so it
+// adds no jump destination.
+func FreshCode(code []byte, n uint64) []byte {
+	res := append(append(make([]byte, 0, len(code)+10), code...), 0x00, 0x67)
+	return binary.BigEndian.AppendUint64(res, n)
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package statetest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/sonicoperations/evmbench/results"
+)
+
+// Status is the verdict of running a subtest on an engine.
+type Status string
+
+const (
+	Pass Status = "pass"
+	Fail Status = "fail"
+	Skip Status = "skip" // the engine or harness does not support the subtest
+)
+
+// Result is the verdict of a subtest on an engine. The engine modules'
+// TestStateTests write them with -statetest-results.
+type Result struct {
+	Engine  string `json:"engine"`
+	Subtest string `json:"subtest"` // see Subtest.Name
+	Fork    string `json:"fork"`
+	Status  Status `json:"status"`
+	Message string `json:"message,omitempty"` // reason of failures and skips
+}
+
+// WriteResults stores results as newline delimited JSON.
+func WriteResults(path string, res []Result) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	for _, result := range res {
+		if err := encoder.Encode(result); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+// ReadResults parses newline delimited JSON results.
+func ReadResults(r io.Reader) ([]Result, error) {
+	var res []Result
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var result Result
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		res = append(res, result)
+	}
+	return res, scanner.Err()
+}
+
+// Summary tabulates the number of passed, failed and skipped subtests per
+// fork and engine. Engines appear in order of their first result, forks
+// in order of their revision.
+func Summary(res []Result) results.Table {
+	type key struct {
+		engine, fork string
+		status       Status
+	}
+	counts := map[key]int{}
+	var engines, forks []string
+	seen := map[string]bool{}
+	for _, result := range res {
+		counts[key{result.Engine, result.Fork, result.Status}]++
+		if !seen["e:"+result.Engine] {
+			seen["e:"+result.Engine] = true
+			engines = append(engines, result.Engine)
+		}
+		if !seen["f:"+result.Fork] {
+			seen["f:"+result.Fork] = true
+			forks = append(forks, result.Fork)
+		}
+	}
+	sort.Slice(forks, func(i, j int) bool {
+		a, knownA := ParseFork(forks[i])
+		b, knownB := ParseFork(forks[j])
+		if knownA != knownB {
+			return knownA
+		}
+		if a != b {
+			return a < b
+		}
+		return forks[i] < forks[j]
+	})
+
+	table := results.Table{Header: []string{"Fork"}}
+	for _, engine := range engines {
+		table.Header = append(table.Header, engine+" pass", engine+" fail", engine+" skip")
+	}
+	for _, fork := range forks {
+		row := []string{fork}
+		for _, engine := range engines {
+			for _, status := range []Status{Pass, Fail, Skip} {
+				row = append(row, fmt.Sprint(counts[key{engine, fork, status}]))
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
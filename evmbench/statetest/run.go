@@ -0,0 +1,132 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package statetest
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// Executor runs a subtest on an engine and returns its verdict, with a
+// reason for failures and skips.
+type Executor func(Subtest) (Status, string)
+
+// Run executes all subtests of tests whose names match filter. Subtests of
+// forks outside the corpus revisions are skipped without calling execute.
+func Run(tests []*Test, filter *regexp.Regexp, engine string, execute Executor) []Result {
+	var res []Result
+	for _, test := range tests {
+		for _, subtest := range test.Subtests() {
+			name := subtest.Name()
+			if filter != nil && !filter.MatchString(name) {
+				continue
+			}
+			result := Result{Engine: engine, Subtest: name, Fork: subtest.Fork}
+			if _, supported := subtest.Revision(); supported {
+				result.Status, result.Message = execute(subtest)
+			} else {
+				result.Status, result.Message = Skip, "unsupported fork"
+			}
+			res = append(res, result)
+		}
+	}
+	return res
+}
+
+// FailedVectors converts the subtests failed in res into corpus vectors.
+// Subtests that cannot be converted, e.g. contract creations, are
+// returned as errors.
+func FailedVectors(tests []*Test, res []Result) ([]corpus.Vector, []error) {
+	failed := map[string]bool{}
+	for _, result := range res {
+		if result.Status == Fail {
+			failed[result.Subtest] = true
+		}
+	}
+	var vectors []corpus.Vector
+	var errs []error
+	for _, test := range tests {
+		for _, subtest := range test.Subtests() {
+			name := subtest.Name()
+			if !failed[name] {
+				continue
+			}
+			delete(failed, name) // once per subtest even if several engines failed it
+			vector, err := subtest.Vector()
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			vectors = append(vectors, vector)
+		}
+	}
+	return vectors, errs
+}
+
+// Options are the settings of TestStateTests in the engine modules, given
+// with their -statetests, -statetest-results and -statetest-vectors flags.
+type Options struct {
+	Fixtures string // fixture directory or file; the corpus sample if empty
+	Results  string // NDJSON file the results are written to, if set
+	Vectors  string // JSON file failed subtests are converted into, if set
+}
+
+// Load reads the fixtures selected by the options.
+func (o Options) Load() ([]*Test, error) {
+	path := o.Fixtures
+	if path == "" {
+		root, err := corpus.Root()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate corpus: %w", err)
+		}
+		path = filepath.Join(root, "statetests")
+	}
+	return Load(path)
+}
+
+// Logger receives the failures reported by Options.Report, e.g. a
+// *testing.T.
+type Logger interface {
+	Errorf(format string, args ...any)
+	Logf(format string, args ...any)
+}
+
+// Report reports the failed subtests of res, writes the results and the
+// converted vectors if requested and, for external fixtures, prints the
+// summary to out. Failures of the corpus sample are errors; those of
+// external fixtures are only logged.
+func (o Options) Report(log Logger, out io.Writer, tests []*Test, res []Result) error {
+	for _, result := range res {
+		if result.Status != Fail {
+			continue
+		}
+		if o.Fixtures == "" {
+			log.Errorf("%s: %s", result.Subtest, result.Message)
+		} else {
+			log.Logf("%s: %s", result.Subtest, result.Message)
+		}
+	}
+	if o.Results != "" {
+		if err := WriteResults(o.Results, res); err != nil {
+			return fmt.Errorf("failed to write results: %w", err)
+		}
+	}
+	if o.Vectors != "" {
+		vectors, errs := FailedVectors(tests, res)
+		for _, err := range errs {
+			log.Logf("Not converted: %v", err)
+		}
+		if err := corpus.WriteVectors(o.Vectors, vectors); err != nil {
+			return fmt.Errorf("failed to write vectors: %w", err)
+		}
+	}
+	if o.Fixtures != "" {
+		return Summary(res).Write(out, "markdown")
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Package statetest reads Ethereum GeneralStateTests fixtures, the filled
+// JSON format of ethereum/tests and execution-spec-tests, and records the
+// results of running them on an engine. Execution is left to the engine
+// modules; this package describes tests, subtests and their expectations in
+// engine neutral terms, converts subtests into corpus vectors and
+// summarizes results per engine and fork.
+package statetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// Uint64 is a number encoded as a hex or decimal string in JSON.
+type Uint64 uint64
+
+func (u *Uint64) UnmarshalText(text []byte) error {
+	value, err := strconv.ParseUint(string(text), 0, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q: %w", text, err)
+	}
+	*u = Uint64(value)
+	return nil
+}
+
+// Account is an account of a pre or post state.
+type Account struct {
+	Balance corpus.Hash                 `json:"balance"`
+	Nonce   Uint64                      `json:"nonce"`
+	Code    corpus.Bytes                `json:"code"`
+	Storage map[corpus.Hash]corpus.Hash `json:"storage"`
+}
+
+// Env is the block environment of a test.
+type Env struct {
+	Coinbase      corpus.Address `json:"currentCoinbase"`
+	Difficulty    *corpus.Hash   `json:"currentDifficulty"`
+	Random        *corpus.Hash   `json:"currentRandom"`
+	GasLimit      Uint64         `json:"currentGasLimit"`
+	Number        Uint64         `json:"currentNumber"`
+	Timestamp     Uint64         `json:"currentTimestamp"`
+	BaseFee       *corpus.Hash   `json:"currentBaseFee"`
+	ExcessBlobGas *Uint64        `json:"currentExcessBlobGas"`
+}
+
+// AccessTuple is an entry of an EIP-2930 access list.
+type AccessTuple struct {
+	Address     corpus.Address `json:"address"`
+	StorageKeys []corpus.Hash  `json:"storageKeys"`
+}
+
+// Transaction is the transaction template of a test. Data, gas limit and
+// value are lists indexed by the subtests.
+type Transaction struct {
+	GasPrice             *corpus.Hash    `json:"gasPrice"`
+	MaxFeePerGas         *corpus.Hash    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *corpus.Hash    `json:"maxPriorityFeePerGas"`
+	Nonce                Uint64          `json:"nonce"`
+	To                   string          `json:"to"` // empty for contract creation
+	Data                 []corpus.Bytes  `json:"data"`
+	AccessLists          [][]AccessTuple `json:"accessLists"` // per data index, nil entries for legacy transactions
+	GasLimit             []Uint64        `json:"gasLimit"`
+	Value                []corpus.Hash   `json:"value"`
+	SecretKey            corpus.Bytes    `json:"secretKey"`
+	Sender               *corpus.Address `json:"sender"`
+	BlobVersionedHashes  []corpus.Hash   `json:"blobVersionedHashes"`
+	MaxFeePerBlobGas     *corpus.Hash    `json:"maxFeePerBlobGas"`
+}
+
+// Post is the expected result of a subtest.
+type Post struct {
+	Hash            corpus.Hash  `json:"hash"` // state root
+	Logs            corpus.Hash  `json:"logs"` // keccak256 of the RLP encoded logs
+	TxBytes         corpus.Bytes `json:"txbytes"`
+	ExpectException string       `json:"expectException"`
+	Indexes         struct {
+		Data  int `json:"data"`
+		Gas   int `json:"gas"`
+		Value int `json:"value"`
+	} `json:"indexes"`
+	// State lists the post-state accounts; only execution-spec-tests
+	// fixtures provide it.
+	State map[corpus.Address]Account `json:"state"`
+}
+
+// Test is a single named state test.
+type Test struct {
+	Name        string                     `json:"-"`
+	Path        string                     `json:"-"` // fixture file
+	Raw         json.RawMessage            `json:"-"` // the test's JSON for engines with their own parser
+	Env         Env                        `json:"env"`
+	Pre         map[corpus.Address]Account `json:"pre"`
+	Transaction Transaction                `json:"transaction"`
+	Post        map[string][]Post          `json:"post"`
+}
+
+// Subtest is one fork and set of transaction indexes of a test.
+type Subtest struct {
+	Test  *Test
+	Fork  string
+	Index int // position in Post[Fork]
+}
+
+// Subtests returns all subtests of a test ordered by fork and index.
+func (t *Test) Subtests() []Subtest {
+	var res []Subtest
+	for fork, posts := range t.Post {
+		for i := range posts {
+			res = append(res, Subtest{Test: t, Fork: fork, Index: i})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Fork != res[j].Fork {
+			return res[i].Fork < res[j].Fork
+		}
+		return res[i].Index < res[j].Index
+	})
+	return res
+}
+
+// Name identifies the subtest as <test>/<fork>/d<data>g<gas>v<value>.
+func (s Subtest) Name() string {
+	indexes := s.Post().Indexes
+	return fmt.Sprintf("%s/%s/d%dg%dv%d", s.Test.Name, s.Fork, indexes.Data, indexes.Gas, indexes.Value)
+}
+
+// Post returns the expectation of the subtest.
+func (s Subtest) Post() Post {
+	return s.Test.Post[s.Fork][s.Index]
+}
+
+// Revision returns the revision of the subtest's fork. Forks with
+// additional EIPs, e.g. "Berlin+1153", and forks outside of the corpus
+// revisions are not supported.
+func (s Subtest) Revision() (corpus.Revision, bool) {
+	return ParseFork(s.Fork)
+}
+
+// Data, GasLimit, Value and AccessList return the transaction fields
+// selected by the subtest's indexes.
+func (s Subtest) Data() corpus.Bytes {
+	return s.Test.Transaction.Data[s.Post().Indexes.Data]
+}
+
+func (s Subtest) GasLimit() uint64 {
+	return uint64(s.Test.Transaction.GasLimit[s.Post().Indexes.Gas])
+}
+
+func (s Subtest) Value() corpus.Hash {
+	return s.Test.Transaction.Value[s.Post().Indexes.Value]
+}
+
+// AccessList returns the access list of the subtest's transaction and
+// whether the transaction has one, i.e. is not a legacy transaction.
+func (s Subtest) AccessList() ([]AccessTuple, bool) {
+	lists, index := s.Test.Transaction.AccessLists, s.Post().Indexes.Data
+	if index >= len(lists) || lists[index] == nil {
+		return nil, false
+	}
+	return lists[index], true
+}
+
+// forks maps fork names of the fixtures to revisions.
+var forks = map[string]corpus.Revision{
+	"Istanbul": corpus.Istanbul,
+	"Berlin":   corpus.Berlin,
+	"London":   corpus.London,
+	"Merge":    corpus.Paris,
+	"Paris":    corpus.Paris,
+	"Shanghai": corpus.Shanghai,
+	"Cancun":   corpus.Cancun,
+}
+
+// ParseFork returns the revision of a fork name of the fixtures.
+func ParseFork(name string) (corpus.Revision, bool) {
+	revision, found := forks[name]
+	return revision, found
+}
+
+// Load reads all tests of the JSON files below root, or of root itself if
+// it is a file, ordered by name.
+func Load(root string) ([]*Test, error) {
+	var res []*Test
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		tests, err := LoadFile(path)
+		if err != nil {
+			return err
+		}
+		res = append(res, tests...)
+		return nil
+	})
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, err
+}
+
+// LoadFile reads the tests of a single fixture file.
+func LoadFile(path string) ([]*Test, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var res []*Test
+	for name, message := range raw {
+		test := &Test{Name: name, Path: path, Raw: message}
+		if err := json.Unmarshal(message, test); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, name, err)
+		}
+		res = append(res, test)
+	}
+	return res, nil
+}
+
+// Intrinsic gas costs of a transaction.
+const (
+	txGas                     = 21_000
+	txGasContractCreation     = 53_000
+	txDataZeroGas             = 4
+	txDataNonZeroGas          = 16
+	txAccessListAddressGas    = 2_400
+	txAccessListStorageKeyGas = 1_900
+	initCodeWordGas           = 2
+)
+
+// IntrinsicGas returns the gas charged for a transaction before executing
+// any code, following the rules of the supported revisions.
+func IntrinsicGas(data []byte, accessList []AccessTuple, create bool, revision corpus.Revision) uint64 {
+	gas := uint64(txGas)
+	if create {
+		gas = txGasContractCreation
+	}
+	for _, b := range data {
+		if b == 0 {
+			gas += txDataZeroGas
+		} else {
+			gas += txDataNonZeroGas
+		}
+	}
+	if create && revision >= corpus.Shanghai {
+		gas += initCodeWordGas * ((uint64(len(data)) + 31) / 32)
+	}
+	for _, tuple := range accessList {
+		gas += txAccessListAddressGas + txAccessListStorageKeyGas*uint64(len(tuple.StorageKeys))
+	}
+	return gas
+}
+
+// Recipient returns the address called by the subtest's transaction, or
+// false for contract creations.
+func (s Subtest) Recipient() (corpus.Address, bool) {
+	if strings.TrimSpace(s.Test.Transaction.To) == "" {
+		return corpus.Address{}, false
+	}
+	var address corpus.Address
+	if err := address.UnmarshalText([]byte(s.Test.Transaction.To)); err != nil {
+		return corpus.Address{}, false
+	}
+	return address, true
+}
+
+// Vector converts the subtest into a corpus vector: the code and storage
+// of the called account executed with the transaction's input and the gas
+// left after the intrinsic costs. Other accounts of the pre-state, the
+// value and the block environment are not part of a vector, so the vector
+// may behave differently than the subtest; it has no expected outcome.
+func (s Subtest) Vector() (corpus.Vector, error) {
+	revision, supported := s.Revision()
+	if !supported {
+		return corpus.Vector{}, fmt.Errorf("%s: unsupported fork %s", s.Name(), s.Fork)
+	}
+	recipient, isCall := s.Recipient()
+	if !isCall {
+		return corpus.Vector{}, fmt.Errorf("%s: contract creations cannot be converted", s.Name())
+	}
+	accessList, _ := s.AccessList()
+	intrinsic := IntrinsicGas(s.Data(), accessList, false, revision)
+	if s.GasLimit() < intrinsic {
+		return corpus.Vector{}, fmt.Errorf("%s: gas limit below intrinsic gas", s.Name())
+	}
+	account := s.Test.Pre[recipient]
+	return corpus.Vector{
+		Name:    "statetest/" + s.Name(),
+		Code:    account.Code,
+		Input:   s.Data(),
+		Gas:     s.GasLimit() - intrinsic,
+		Storage: account.Storage,
+	}, nil
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package statetest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+const fixture = `{
+  "add": {
+    "env": {"currentCoinbase": "0x2adc25665018aa1fe0e6bc666dac8fc2697ff9ba", "currentGasLimit": "0x05f5e100",
+            "currentNumber": "0x01", "currentTimestamp": "0x03e8", "currentBaseFee": "0x0a"},
+    "pre": {
+      "0x1000000000000000000000000000000000000000": {"balance": "0x00", "nonce": "0x00", "code": "0x6001600101600055",
+                                                     "storage": {"0x01": "0x02"}}
+    },
+    "transaction": {"gasPrice": "0x0a", "nonce": "0x00", "to": "0x1000000000000000000000000000000000000000",
+                    "data": ["0x", "0x0001"], "gasLimit": ["0x0186a0"], "value": ["0x00"],
+                    "sender": "0xa94f5374fce5edbc8e2a8697c15331677e6ebf0b"},
+    "post": {
+      "Berlin": [{"hash": "0x01", "logs": "0x02", "indexes": {"data": 1, "gas": 0, "value": 0}}],
+      "Cancun": [{"hash": "0x03", "logs": "0x04", "indexes": {"data": 0, "gas": 0, "value": 0}},
+                 {"hash": "0x05", "logs": "0x06", "indexes": {"data": 1, "gas": 0, "value": 0}}],
+      "Frontier": [{"hash": "0x07", "logs": "0x08", "indexes": {"data": 0, "gas": 0, "value": 0}}]
+    }
+  }
+}`
+
+func loadFixture(t *testing.T) []*Test {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "add.json")
+	if err := os.WriteFile(path, []byte(fixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests, err := Load(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tests) != 1 || tests[0].Name != "add" {
+		t.Fatalf("unexpected tests: %v", tests)
+	}
+	return tests
+}
+
+func TestLoad_ProvidesSubtestsAndVectors(t *testing.T) {
+	test := loadFixture(t)[0]
+	subtests := test.Subtests()
+	var names []string
+	for _, subtest := range subtests {
+		names = append(names, subtest.Name())
+	}
+	want := []string{"add/Berlin/d1g0v0", "add/Cancun/d0g0v0", "add/Cancun/d1g0v0", "add/Frontier/d0g0v0"}
+	if len(names) != len(want) {
+		t.Fatalf("unexpected subtests %v, wanted %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("unexpected subtest %d, wanted %s, got %s", i, want[i], names[i])
+		}
+	}
+
+	berlin := subtests[0]
+	if revision, _ := berlin.Revision(); revision != corpus.Berlin {
+		t.Errorf("unexpected revision %v", revision)
+	}
+	if _, supported := subtests[3].Revision(); supported {
+		t.Errorf("Frontier must not be supported")
+	}
+	vector, err := berlin.Vector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 21000 plus 4 for the zero and 16 for the non-zero byte of the input.
+	if vector.Gas != 100_000-21_020 {
+		t.Errorf("unexpected gas %d", vector.Gas)
+	}
+	if vector.Name != "statetest/add/Berlin/d1g0v0" || !bytes.Equal(vector.Input, []byte{0, 1}) || len(vector.Code) != 8 {
+		t.Errorf("unexpected vector %+v", vector)
+	}
+	if _, err := subtests[3].Vector(); err == nil {
+		t.Errorf("vectors of unsupported forks must not be converted")
+	}
+}
+
+func TestRun_SkipsUnsupportedForksAndConvertsFailures(t *testing.T) {
+	tests := loadFixture(t)
+	executed := 0
+	res := Run(tests, regexp.MustCompile("d1"), "fake", func(subtest Subtest) (Status, string) {
+		executed++
+		if subtest.Fork == "Cancun" {
+			return Fail, "state root mismatch"
+		}
+		return Pass, ""
+	})
+	if executed != 2 || len(res) != 2 {
+		t.Fatalf("unexpected results %v", res)
+	}
+
+	vectors, errs := FailedVectors(tests, res)
+	if len(errs) != 0 || len(vectors) != 1 || vectors[0].Name != "statetest/add/Cancun/d1g0v0" {
+		t.Errorf("unexpected conversion %v, errors %v", vectors, errs)
+	}
+
+	res = append(res, Result{Engine: "fake", Subtest: "add/Frontier/d0g0v0", Fork: "Frontier", Status: Skip})
+	table := Summary(res)
+	want := [][]string{{"Berlin", "1", "0", "0"}, {"Cancun", "0", "1", "0"}, {"Frontier", "0", "0", "1"}}
+	if len(table.Rows) != len(want) {
+		t.Fatalf("unexpected summary %v", table.Rows)
+	}
+	for i, row := range want {
+		for j, cell := range row {
+			if table.Rows[i][j] != cell {
+				t.Errorf("unexpected summary row %v, wanted %v", table.Rows[i], row)
+				break
+			}
+		}
+	}
+}
+
+// recorder is a Logger keeping the messages it receives.
+type recorder struct {
+	errors, logs []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Logf(format string, args ...any) {
+	r.logs = append(r.logs, fmt.Sprintf(format, args...))
+}
+
+func TestOptions_ReportFailsOnlyOnCorpusSample(t *testing.T) {
+	tests := loadFixture(t)
+	res := []Result{{Engine: "fake", Subtest: "add/Cancun/d1g0v0", Fork: "Cancun", Status: Fail, Message: "state root mismatch"}}
+
+	var sample recorder
+	var out bytes.Buffer
+	if err := (Options{}).Report(&sample, &out, tests, res); err != nil {
+		t.Fatal(err)
+	}
+	if len(sample.errors) != 1 || len(sample.logs) != 0 || out.Len() != 0 {
+		t.Errorf("failures of the corpus sample must be errors, got %+v and output %q", sample, out.String())
+	}
+
+	dir := t.TempDir()
+	options := Options{Fixtures: dir, Results: filepath.Join(dir, "results.ndjson"), Vectors: filepath.Join(dir, "vectors.json")}
+	var external recorder
+	if err := options.Report(&external, &out, tests, res); err != nil {
+		t.Fatal(err)
+	}
+	if len(external.errors) != 0 || len(external.logs) != 1 || !strings.Contains(out.String(), "Cancun") {
+		t.Errorf("failures of external fixtures must be logged, got %+v and output %q", external, out.String())
+	}
+	if vectors, err := corpus.ReadVectors(options.Vectors); err != nil || len(vectors) != 1 {
+		t.Errorf("unexpected vectors %v, error %v", vectors, err)
+	}
+	if _, err := os.Stat(options.Results); err != nil {
+		t.Errorf("results not written: %v", err)
+	}
+}
+
+func TestIntrinsicGas(t *testing.T) {
+	accessList := []AccessTuple{{StorageKeys: make([]corpus.Hash, 2)}}
+	tests := []struct {
+		data       []byte
+		accessList []AccessTuple
+		create     bool
+		revision   corpus.Revision
+		want       uint64
+	}{
+		{nil, nil, false, corpus.Cancun, 21_000},
+		{[]byte{0, 1}, nil, false, corpus.Cancun, 21_020},
+		{nil, accessList, false, corpus.Berlin, 21_000 + 2_400 + 2*1_900},
+		{make([]byte, 33), nil, true, corpus.London, 53_000 + 33*4},
+		{make([]byte, 33), nil, true, corpus.Shanghai, 53_000 + 33*4 + 2*2},
+	}
+	for _, test := range tests {
+		if got := IntrinsicGas(test.data, test.accessList, test.create, test.revision); got != test.want {
+			t.Errorf("unexpected intrinsic gas of %d bytes, create %t in %v: wanted %d, got %d",
+				len(test.data), test.create, test.revision, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package trace
+
+import (
+	"fmt"
+
+	"github.com/sonicoperations/evmbench/asm"
+)
+
+// Difference is the first point where two traces disagree.
+type Difference struct {
+	Step  int    // index of the step, or -1 for the summary
+	Pc    uint64 // of the step in the first trace
+	Op    byte
+	Field string
+	A, B  string
+}
+
+func (d Difference) String() string {
+	if d.Step < 0 {
+		return fmt.Sprintf("summary: %s %s != %s", d.Field, d.A, d.B)
+	}
+	return fmt.Sprintf("step %d (pc %d, %v): %s %s != %s", d.Step, d.Pc, asm.Opcode(d.Op), d.Field, d.A, d.B)
+}
+
+// callOps are the instructions starting a new frame. Clients account the
+// gas passed to the frame differently in their gas costs, e.g. go-ethereum
+// includes the full allowance while LFVM's trace logs the step before its
+// cost is known, so their costs are not compared.
+var callOps = map[byte]bool{0xf0: true, 0xf1: true, 0xf2: true, 0xf4: true, 0xf5: true, 0xfa: true}
+
+// revert is the REVERT opcode, which go-ethereum marks with an error.
+const revert = 0xfd
+
+// Diff returns the first difference of two traces, or nil if they agree.
+// Errors are compared by presence only since their messages are client
+// specific, and not at all for REVERT. Neither are the gas costs of failing
+// steps: go-ethereum reports the cost it failed to charge, LFVM none.
+// Summaries are compared if both traces have one.
+func Diff(a, b Trace) *Difference {
+	for i := 0; i < len(a.Steps) && i < len(b.Steps); i++ {
+		if field, x, y := diffStep(a.Steps[i], b.Steps[i]); field != "" {
+			return &Difference{Step: i, Pc: a.Steps[i].Pc, Op: a.Steps[i].Op, Field: field, A: x, B: y}
+		}
+	}
+	if len(a.Steps) != len(b.Steps) {
+		i := min(len(a.Steps), len(b.Steps))
+		d := &Difference{Step: i, Field: "steps", A: fmt.Sprint(len(a.Steps)), B: fmt.Sprint(len(b.Steps))}
+		if i < len(a.Steps) {
+			d.Pc, d.Op = a.Steps[i].Pc, a.Steps[i].Op
+		} else {
+			d.Pc, d.Op = b.Steps[i].Pc, b.Steps[i].Op
+		}
+		return d
+	}
+	if a.Summary == nil || b.Summary == nil {
+		return nil
+	}
+	switch x, y := *a.Summary, *b.Summary; {
+	case x.GasUsed != y.GasUsed:
+		return &Difference{Step: -1, Field: "gasUsed", A: fmt.Sprint(uint64(x.GasUsed)), B: fmt.Sprint(uint64(y.GasUsed))}
+	case x.Output != y.Output:
+		return &Difference{Step: -1, Field: "output", A: "0x" + x.Output, B: "0x" + y.Output}
+	case (x.Error == "") != (y.Error == ""):
+		return &Difference{Step: -1, Field: "error", A: quote(x.Error), B: quote(y.Error)}
+	}
+	return nil
+}
+
+// diffStep returns the first differing field of two steps and its values.
+func diffStep(a, b Step) (field, x, y string) {
+	switch {
+	case a.Depth != b.Depth:
+		return "depth", fmt.Sprint(a.Depth), fmt.Sprint(b.Depth)
+	case a.Pc != b.Pc:
+		return "pc", fmt.Sprint(a.Pc), fmt.Sprint(b.Pc)
+	case a.Op != b.Op:
+		return "op", asm.Opcode(a.Op).String(), asm.Opcode(b.Op).String()
+	case a.Gas != b.Gas:
+		return "gas", fmt.Sprint(uint64(a.Gas)), fmt.Sprint(uint64(b.Gas))
+	case a.GasCost != b.GasCost && !callOps[a.Op] && a.Error == "" && b.Error == "":
+		return "gasCost", fmt.Sprint(uint64(a.GasCost)), fmt.Sprint(uint64(b.GasCost))
+	case a.MemSize != b.MemSize:
+		return "memSize", fmt.Sprint(a.MemSize), fmt.Sprint(b.MemSize)
+	case len(a.Stack) != len(b.Stack):
+		return "stack size", fmt.Sprint(len(a.Stack)), fmt.Sprint(len(b.Stack))
+	case a.Refund != b.Refund:
+		return "refund", fmt.Sprint(a.Refund), fmt.Sprint(b.Refund)
+	case (a.Error == "") != (b.Error == "") && a.Op != revert:
+		return "error", quote(a.Error), quote(b.Error)
+	}
+	// Stack entries are compared from the top, where differences arise.
+	for i := 1; i <= len(a.Stack); i++ {
+		if x, y := a.Stack[len(a.Stack)-i], b.Stack[len(b.Stack)-i]; x != y {
+			return fmt.Sprintf("stack[%d]", i-1), x.String(), y.String()
+		}
+	}
+	return "", "", ""
+}
+
+func quote(err string) string {
+	if err == "" {
+		return "no error"
+	}
+	return fmt.Sprintf("%q", err)
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Package trace reads, writes and compares EIP-3155 execution traces: one
+// JSON object per executed instruction, followed by a summary of the
+// outermost call. The engine modules write them with -traces in the format
+// of go-ethereum's JSON logger, so that traces of other clients saved as
+// local files can be read and diffed against them.
+package trace
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sonicoperations/evmbench/asm"
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// Quantity is an unsigned integer encoded as 0x-prefixed hex. Decimal
+// strings and JSON numbers are accepted as well.
+type Quantity uint64
+
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"0x%x"`, uint64(q))), nil
+}
+
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	var value uint64
+	var err error
+	if hex, found := strings.CutPrefix(s, "0x"); found {
+		value, err = strconv.ParseUint(hex, 16, 64)
+	} else {
+		value, err = strconv.ParseUint(s, 10, 64)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid quantity %s", data)
+	}
+	*q = Quantity(value)
+	return nil
+}
+
+// Word is a stack entry encoded as a hex quantity without leading zeros.
+type Word corpus.Hash
+
+func (w Word) String() string {
+	return "0x" + new(big.Int).SetBytes(w[:]).Text(16)
+}
+
+func (w Word) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
+
+func (w *Word) UnmarshalText(text []byte) error {
+	return (*corpus.Hash)(w).UnmarshalText(text)
+}
+
+// Step is the state of the interpreter before executing an instruction.
+// Gas is the gas left before the instruction and GasCost what it consumed;
+// Refund is the refund counter of the transaction. The stack is listed
+// bottom first.
+type Step struct {
+	Pc      uint64   `json:"pc"`
+	Op      byte     `json:"op"`
+	Gas     Quantity `json:"gas"`
+	GasCost Quantity `json:"gasCost"`
+	MemSize int      `json:"memSize"`
+	Stack   []Word   `json:"stack"`
+	Depth   int      `json:"depth"` // 1 for the outermost call
+	Refund  uint64   `json:"refund"`
+	OpName  string   `json:"opName"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Summary is the result of the outermost call, written after its steps.
+type Summary struct {
+	Output  string   `json:"output"` // hex without 0x prefix
+	GasUsed Quantity `json:"gasUsed"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// OutcomeSummary returns the summary of an outcome. Outcomes carry no error
+// messages, so failures are described generically.
+func OutcomeSummary(outcome corpus.Outcome) Summary {
+	summary := Summary{Output: hex.EncodeToString(outcome.Output), GasUsed: Quantity(outcome.GasUsed)}
+	switch outcome.Status {
+	case corpus.Revert:
+		summary.Error = "execution reverted"
+	case corpus.Failure:
+		summary.Error = "execution failed"
+	}
+	return summary
+}
+
+// Trace is a parsed trace file.
+type Trace struct {
+	Steps   []Step
+	Summary *Summary
+}
+
+// FileName returns the name of the trace file of a vector or state test,
+// e.g. "erc20_transfer.jsonl" for "erc20/transfer".
+func FileName(name string) string {
+	return strings.NewReplacer("/", "_", " ", "_").Replace(name) + ".jsonl"
+}
+
+// Writer writes a trace as JSON lines.
+type Writer struct {
+	encoder *json.Encoder
+	buffer  *bufio.Writer
+	file    *os.File
+}
+
+// NewWriter returns a writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{encoder: json.NewEncoder(w)}
+}
+
+// Create creates the trace file at path.
+func Create(path string) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	buffer := bufio.NewWriter(file)
+	return &Writer{encoder: json.NewEncoder(buffer), buffer: buffer, file: file}, nil
+}
+
+// Step writes a step, filling in the name of its opcode.
+func (w *Writer) Step(step Step) error {
+	if step.Stack == nil {
+		step.Stack = []Word{}
+	}
+	step.OpName = asm.Opcode(step.Op).String()
+	return w.encoder.Encode(step)
+}
+
+// Summary writes the summary of the outermost call.
+func (w *Writer) Summary(summary Summary) error {
+	return w.encoder.Encode(summary)
+}
+
+// Close flushes and closes a writer obtained from Create.
+func (w *Writer) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	if err := w.buffer.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// Read parses a trace. Lines other than steps and summaries, e.g. call
+// frames or state roots, are skipped. go-ethereum logs a faulting
+// instruction twice, the second time with the error; such repetitions are
+// merged into a single step.
+func Read(r io.Reader) (Trace, error) {
+	var res Trace
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<26)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &fields); err != nil {
+			return Trace{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		switch {
+		case fields["pc"] != nil && fields["op"] != nil:
+			var step Step
+			if err := json.Unmarshal(scanner.Bytes(), &step); err != nil {
+				return Trace{}, fmt.Errorf("line %d: %w", line, err)
+			}
+			if n := len(res.Steps); n > 0 && step.Error != "" && res.Steps[n-1].Error == "" && isRepetition(res.Steps[n-1], step) {
+				res.Steps[n-1].Error = step.Error
+				continue
+			}
+			res.Steps = append(res.Steps, step)
+		case fields["gasUsed"] != nil && fields["output"] != nil:
+			var summary Summary
+			if err := json.Unmarshal(scanner.Bytes(), &summary); err != nil {
+				return Trace{}, fmt.Errorf("line %d: %w", line, err)
+			}
+			res.Summary = &summary
+		}
+	}
+	return res, scanner.Err()
+}
+
+// isRepetition reports whether b logs the same instruction as a.
+func isRepetition(a, b Step) bool {
+	return a.Pc == b.Pc && a.Op == b.Op && a.Depth == b.Depth && a.Gas == b.Gas
+}
+
+// ReadFile parses the trace file at path.
+func ReadFile(path string) (Trace, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Trace{}, err
+	}
+	defer file.Close()
+	res, err := Read(file)
+	if err != nil {
+		return Trace{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return res, nil
+}
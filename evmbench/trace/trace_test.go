@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package trace
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// gethTrace is the output of go-ethereum's JSON logger for PUSH1 1 REVERT
+// with call frames, including the repeated line of the faulting REVERT.
+const gethTrace = `{"from":"0x1000000000000000000000000000000000000001","to":"0x0100000000000000000000000000000000000000","gas":"0x64","value":"0x0","type":"CALL"}
+{"pc":0,"op":96,"gas":"0x64","gasCost":"0x3","memSize":0,"stack":[],"depth":1,"refund":0,"opName":"PUSH1"}
+{"pc":2,"op":253,"gas":"0x61","gasCost":"0x0","memSize":0,"stack":["0x1"],"depth":1,"refund":0,"opName":"REVERT"}
+{"pc":2,"op":253,"gas":"0x61","gasCost":"0x0","memSize":0,"stack":["0x1"],"depth":1,"refund":0,"opName":"REVERT","error":"execution reverted"}
+{"output":"","gasUsed":"0x3","error":"execution reverted"}
+`
+
+func TestRead_MergesRepeatedFaultsAndSkipsOtherLines(t *testing.T) {
+	trace, err := Read(strings.NewReader(gethTrace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trace.Steps) != 2 {
+		t.Fatalf("unexpected steps %+v", trace.Steps)
+	}
+	revert := trace.Steps[1]
+	if revert.Pc != 2 || revert.Gas != 97 || revert.Error != "execution reverted" || len(revert.Stack) != 1 || revert.Stack[0] != (Word{31: 1}) {
+		t.Errorf("unexpected step %+v", revert)
+	}
+	if trace.Summary == nil || trace.Summary.GasUsed != 3 {
+		t.Errorf("unexpected summary %+v", trace.Summary)
+	}
+}
+
+func TestWriter_WritesReadableTraces(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := NewWriter(&buffer)
+	steps := []Step{
+		{Pc: 0, Op: 0x60, Gas: 100, GasCost: 3, Depth: 1},
+		{Pc: 2, Op: 0xfd, Gas: 97, Stack: []Word{{31: 1}}, Depth: 1},
+	}
+	for _, step := range steps {
+		if err := writer.Step(step); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Summary(Summary{GasUsed: 3, Error: "execution reverted"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffer.String(), `"gas":"0x64","gasCost":"0x3","memSize":0,"stack":[],"depth":1,"refund":0,"opName":"PUSH1"`) {
+		t.Errorf("unexpected encoding:\n%s", buffer.String())
+	}
+	trace, err := Read(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := Read(strings.NewReader(gethTrace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := Diff(trace, expected); d != nil {
+		t.Errorf("written trace differs from go-ethereum's: %v", d)
+	}
+}
+
+func TestQuantity_AcceptsDecimals(t *testing.T) {
+	trace, err := Read(strings.NewReader(`{"pc":0,"op":0,"gas":100,"gasCost":"0","memSize":0,"stack":["0x00ff"],"depth":1,"refund":0}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if step := trace.Steps[0]; step.Gas != 100 || step.Stack[0] != (Word{31: 0xff}) || step.Stack[0].String() != "0xff" {
+		t.Errorf("unexpected step %+v", step)
+	}
+}
+
+func TestDiff_ReportsFirstDifference(t *testing.T) {
+	base := func() Trace {
+		return Trace{Steps: []Step{
+			{Pc: 0, Op: 0x60, Gas: 100, GasCost: 3, Depth: 1},
+			{Pc: 2, Op: 0xf1, Gas: 97, GasCost: 50, Stack: []Word{{31: 1}, {31: 2}}, Depth: 1},
+			{Pc: 3, Op: 0x00, Gas: 47, Stack: []Word{{31: 1}}, Depth: 1},
+		}, Summary: &Summary{GasUsed: 53}}
+	}
+	a, b := base(), base()
+	b.Steps[1].GasCost = 2000
+	if d := Diff(a, b); d != nil {
+		t.Errorf("gas costs of calls must not be compared, got %v", d)
+	}
+	b.Steps[1].Stack[0] = Word{31: 3}
+	b.Steps[2].Gas = 46
+	d := Diff(a, b)
+	if d == nil || d.Step != 1 || d.Field != "stack[1]" || d.A != "0x1" || d.B != "0x3" {
+		t.Errorf("unexpected difference %v", d)
+	}
+
+	b = base()
+	b.Steps = b.Steps[:2]
+	if d := Diff(a, b); d == nil || d.Field != "steps" || d.Pc != 3 {
+		t.Errorf("unexpected difference %v", d)
+	}
+	b = base()
+	a.Steps[2].Error, b.Steps[2].Error, b.Steps[2].GasCost = "out of gas", "out of gas", 60
+	if d := Diff(a, b); d != nil {
+		t.Errorf("gas costs of failing steps must not be compared, got %v", d)
+	}
+	a = base()
+	b = base()
+	b.Summary.GasUsed = 54
+	if d := Diff(a, b); d == nil || d.Step != -1 || d.Field != "gasUsed" {
+		t.Errorf("unexpected difference %v", d)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package worker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/sonicoperations/evmbench/conformance"
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+)
+
+// Client is the coordinator's end of the connection to a worker.
+type Client struct {
+	Engine   string
+	Revision corpus.Revision
+
+	in    io.Writer
+	out   *bufio.Reader
+	close func() error
+}
+
+// NewClient connects to a worker reading requests from w and writing
+// responses to r, and waits for its greeting.
+func NewClient(r io.Reader, w io.Writer) (*Client, error) {
+	client := &Client{in: w, out: bufio.NewReader(r)}
+	var greeting Response
+	if err := ReadFrame(client.out, &greeting); err != nil {
+		return nil, fmt.Errorf("no greeting from worker: %w", err)
+	}
+	if greeting.Engine == "" {
+		return nil, errors.New("worker did not announce its engine")
+	}
+	client.Engine, client.Revision = greeting.Engine, greeting.Revision
+	return client, nil
+}
+
+// Start launches a worker process and connects to it. The command's
+// stdin and stdout must not be set; its stderr is left to the caller.
+func Start(cmd *exec.Cmd) (*Client, error) {
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	client, err := NewClient(out, in)
+	if err != nil {
+		in.Close()
+		return nil, errors.Join(err, cmd.Wait())
+	}
+	client.close = func() error {
+		in.Close()
+		// Drain whatever the process prints after the protocol ended, e.g.
+		// the final PASS of a test binary.
+		io.Copy(io.Discard, out)
+		return cmd.Wait()
+	}
+	return client, nil
+}
+
+func (c *Client) call(request Request) (Response, error) {
+	if err := WriteFrame(c.in, request); err != nil {
+		return Response{}, fmt.Errorf("%s worker: %w", c.Engine, err)
+	}
+	var response Response
+	if err := ReadFrame(c.out, &response); err != nil {
+		return Response{}, fmt.Errorf("%s worker: %w", c.Engine, err)
+	}
+	if response.Error != "" {
+		return response, fmt.Errorf("%s worker: %s", c.Engine, response.Error)
+	}
+	return response, nil
+}
+
+// Load prepares a vector in the worker and returns the outcome of its
+// first execution.
+func (c *Client) Load(vector corpus.Vector) (corpus.Outcome, error) {
+	response, err := c.call(Request{Kind: Load, Vector: &vector})
+	if err != nil {
+		return corpus.Outcome{}, err
+	}
+	if response.Outcome == nil {
+		return corpus.Outcome{}, fmt.Errorf("%s worker: no outcome reported", c.Engine)
+	}
+	return *response.Outcome, nil
+}
+
+// Batch executes the loaded vector the given number of times.
+func (c *Client) Batch(iterations int) (results.Sample, error) {
+	response, err := c.call(Request{Kind: Batch, Iterations: iterations})
+	if err != nil {
+		return results.Sample{}, err
+	}
+	if response.Sample == nil {
+		return results.Sample{}, fmt.Errorf("%s worker: no sample reported", c.Engine)
+	}
+	return *response.Sample, nil
+}
+
+// Runs executes the loaded vector the given number of times and returns
+// the duration of every execution in nanoseconds.
+func (c *Client) Runs(iterations int) ([]float64, error) {
+	response, err := c.call(Request{Kind: Runs, Iterations: iterations})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Runs) != max(1, iterations) {
+		return nil, fmt.Errorf("%s worker: %d runs reported, wanted %d", c.Engine, len(response.Runs), iterations)
+	}
+	return response.Runs, nil
+}
+
+// Step executes up to steps instructions of a conformance state.
+func (c *Client) Step(state conformance.State, steps int) (conformance.State, error) {
+	response, err := c.call(Request{Kind: Step, State: &state, Steps: steps})
+	if err != nil {
+		return conformance.State{}, err
+	}
+	if response.State == nil {
+		return conformance.State{}, fmt.Errorf("%s worker: no state reported", c.Engine)
+	}
+	return *response.State, nil
+}
+
+// Close asks the worker to quit and waits for a started process to exit.
+func (c *Client) Close() error {
+	_, err := c.call(Request{Kind: Quit})
+	if c.close != nil {
+		err = errors.Join(err, c.close())
+	}
+	return err
+}
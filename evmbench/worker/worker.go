@@ -0,0 +1,228 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+//
+// Package worker implements the protocol between the evmbench coordinator
+// and engine worker processes. The engines live in separate Go modules and
+// cannot be linked into one binary; workers allow measuring them within one
+// session by alternating short timed batches between the processes, so that
+// thermal and frequency drifts affect all engines alike.
+//
+// Messages are JSON documents framed by their length as a 4 byte big endian
+// integer. A worker starts by sending a Response announcing its engine and
+// revision and then answers every Request with one Response:
+//
+//	load   prepare a vector and execute it once, reporting its outcome
+//	batch  execute the loaded vector a number of times and report the timing
+//	runs   execute the loaded vector a number of times, timing each execution
+//	step   execute instructions of a conformance state and report the result
+//	quit   stop serving
+package worker
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+
+	"github.com/sonicoperations/evmbench/conformance"
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+)
+
+// maxFrame limits the size of a message, guarding against reading garbage
+// written to the protocol stream as a length.
+const maxFrame = 64 << 20
+
+// Request kinds.
+const (
+	Load  = "load"
+	Batch = "batch"
+	Runs  = "runs"
+	Step  = "step"
+	Quit  = "quit"
+)
+
+// Request is a message from the coordinator to a worker.
+type Request struct {
+	Kind       string             `json:"kind"`
+	Vector     *corpus.Vector     `json:"vector,omitempty"`     // for load
+	Iterations int                `json:"iterations,omitempty"` // for batch and runs
+	State      *conformance.State `json:"state,omitempty"`      // for step
+	Steps      int                `json:"steps,omitempty"`      // for step
+}
+
+// Response is a message from a worker to the coordinator.
+type Response struct {
+	Engine   string             `json:"engine,omitempty"` // for the greeting
+	Revision corpus.Revision    `json:"revision"`         // for the greeting
+	Outcome  *corpus.Outcome    `json:"outcome,omitempty"`
+	Sample   *results.Sample    `json:"sample,omitempty"`
+	Runs     []float64          `json:"runs,omitempty"` // ns of every execution
+	State    *conformance.State `json:"state,omitempty"`
+	Error    string             `json:"error,omitempty"`
+}
+
+// WriteFrame writes a length-prefixed JSON message.
+func WriteFrame(w io.Writer, message any) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	frame := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(data)), uint32(len(data)))
+	_, err = w.Write(append(frame, data...))
+	return err
+}
+
+// ReadFrame reads a length-prefixed JSON message into message.
+func ReadFrame(r io.Reader, message any) error {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return err
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size > maxFrame {
+		return fmt.Errorf("frame of %d bytes exceeds the limit of %d", size, maxFrame)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, message)
+}
+
+// Runner executes a loaded vector once, reverting all state changes.
+type Runner func() error
+
+// LoadFunc prepares a vector for repeated execution and reports the outcome
+// of a first execution.
+type LoadFunc func(vector corpus.Vector) (Runner, corpus.Outcome, error)
+
+// StepFunc executes up to steps instructions of a running conformance state
+// and returns the resulting state. States the engine cannot execute are
+// reported as errors.
+type StepFunc func(state conformance.State, steps int) (conformance.State, error)
+
+// Serve answers requests read from r on w until a quit request or the end
+// of r. Engines without a step function answer step requests with an
+// error. Failures of single requests are reported to the coordinator; the
+// returned error is that of the communication.
+func Serve(r io.Reader, w io.Writer, engine string, revision corpus.Revision, load LoadFunc, step StepFunc) error {
+	in, out := bufio.NewReader(r), bufio.NewWriter(w)
+	send := func(response Response) error {
+		if err := WriteFrame(out, response); err != nil {
+			return err
+		}
+		return out.Flush()
+	}
+	if err := send(Response{Engine: engine, Revision: revision}); err != nil {
+		return err
+	}
+
+	var runner Runner
+	for {
+		var request Request
+		if err := ReadFrame(in, &request); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		var response Response
+		switch request.Kind {
+		case Load:
+			runner = nil
+			if request.Vector == nil {
+				response.Error = "load request without vector"
+				break
+			}
+			loaded, outcome, err := load(*request.Vector)
+			if err != nil {
+				response.Error = err.Error()
+				break
+			}
+			runner, response.Outcome = loaded, &outcome
+		case Batch:
+			if runner == nil {
+				response.Error = "no vector loaded"
+				break
+			}
+			sample, err := measure(runner, max(1, request.Iterations))
+			if err != nil {
+				response.Error = err.Error()
+				break
+			}
+			response.Sample = &sample
+		case Runs:
+			if runner == nil {
+				response.Error = "no vector loaded"
+				break
+			}
+			runs, err := timeRuns(runner, max(1, request.Iterations))
+			if err != nil {
+				response.Error = err.Error()
+				break
+			}
+			response.Runs = runs
+		case Step:
+			if step == nil || request.State == nil {
+				response.Error = "step not supported or without state"
+				break
+			}
+			result, err := step(*request.State, max(1, request.Steps))
+			if err != nil {
+				response.Error = err.Error()
+				break
+			}
+			response.State = &result
+		case Quit:
+			return send(response)
+		default:
+			response.Error = fmt.Sprintf("unknown request %q", request.Kind)
+		}
+		if err := send(response); err != nil {
+			return err
+		}
+	}
+}
+
+// measure executes a runner the given number of times and reports time
+// and allocations per execution the way testing.B does.
+func measure(run Runner, iterations int) (results.Sample, error) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		if err := run(); err != nil {
+			return results.Sample{}, err
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	n := float64(iterations)
+	return results.Sample{
+		Iterations:  iterations,
+		NsPerOp:     float64(elapsed.Nanoseconds()) / n,
+		BytesPerOp:  float64(after.TotalAlloc-before.TotalAlloc) / n,
+		AllocsPerOp: float64(after.Mallocs-before.Mallocs) / n,
+	}, nil
+}
+
+// timeRuns executes a runner the given number of times and returns the
+// duration of every execution in nanoseconds.
+func timeRuns(run Runner, iterations int) ([]float64, error) {
+	res := make([]float64, iterations)
+	runtime.GC()
+	for i := range res {
+		start := time.Now()
+		if err := run(); err != nil {
+			return nil, err
+		}
+		res[i] = float64(time.Since(start).Nanoseconds())
+	}
+	return res, nil
+}
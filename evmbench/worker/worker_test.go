@@ -0,0 +1,133 @@
+// Copyright (c) 2025 Sonic Operations Ltd
+// SPDX-License-Identifier: BSL-1.1
+
+package worker
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sonicoperations/evmbench/conformance"
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// connect serves load and step in a goroutine and returns a client
+// connected to it.
+func connect(t *testing.T, load LoadFunc, step StepFunc) (*Client, chan error) {
+	t.Helper()
+	requests, requestWriter := io.Pipe()
+	responseReader, responses := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(requests, responses, "fake", corpus.Berlin, load, step)
+		responses.Close()
+	}()
+	client, err := NewClient(responseReader, requestWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, done
+}
+
+func TestWorker_LoadsAndMeasuresVectors(t *testing.T) {
+	runs := 0
+	client, done := connect(t, func(vector corpus.Vector) (Runner, corpus.Outcome, error) {
+		if vector.Gas == 0 {
+			return nil, corpus.Outcome{}, errors.New("no gas")
+		}
+		runs = 0
+		return func() error { runs++; return nil }, corpus.Outcome{Status: corpus.Success, GasUsed: vector.Gas / 2}, nil
+	}, nil)
+	if client.Engine != "fake" || client.Revision != corpus.Berlin {
+		t.Errorf("unexpected greeting: %s in %s", client.Engine, client.Revision)
+	}
+
+	if _, err := client.Batch(1); err == nil || !strings.Contains(err.Error(), "no vector loaded") {
+		t.Errorf("batch without vector must fail, got %v", err)
+	}
+	if _, err := client.Load(corpus.Vector{Name: "empty"}); err == nil || !strings.Contains(err.Error(), "no gas") {
+		t.Errorf("load errors must be reported, got %v", err)
+	}
+	outcome, err := client.Load(corpus.Vector{Name: "v", Code: corpus.Bytes{0x00}, Gas: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outcome.Status != corpus.Success || outcome.GasUsed != 50 {
+		t.Errorf("unexpected outcome: %+v", outcome)
+	}
+	sample, err := client.Batch(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sample.Iterations != 10 || runs != 10 || sample.NsPerOp <= 0 {
+		t.Errorf("unexpected sample %+v after %d runs", sample, runs)
+	}
+	times, err := client.Runs(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(times) != 5 || runs != 15 || times[0] < 0 {
+		t.Errorf("unexpected runs %v after %d runs", times, runs)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("worker failed: %v", err)
+	}
+}
+
+func TestWorker_StepsConformanceStates(t *testing.T) {
+	client, done := connect(t, nil, func(state conformance.State, steps int) (conformance.State, error) {
+		if len(state.Code) == 0 {
+			return conformance.State{}, errors.New("no code")
+		}
+		state.Pc += uint64(steps)
+		state.Gas -= 3 * int64(steps)
+		return state, nil
+	})
+
+	state := conformance.State{Status: conformance.Running, Revision: corpus.Cancun, Code: corpus.Bytes{0x5b, 0x5b}, Gas: 10,
+		Storage: map[corpus.Hash]conformance.Slot{{31: 1}: {Current: corpus.Hash{31: 2}, Warm: true}}}
+	result, err := client.Step(state, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Pc != 2 || result.Gas != 4 || result.Revision != corpus.Cancun || result.Storage[corpus.Hash{31: 1}].Current != (corpus.Hash{31: 2}) {
+		t.Errorf("unexpected state %+v", result)
+	}
+	if _, err := client.Step(conformance.State{}, 1); err == nil || !strings.Contains(err.Error(), "no code") {
+		t.Errorf("step errors must be reported, got %v", err)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("worker failed: %v", err)
+	}
+}
+
+func TestWorker_RejectsStepsWithoutStepFunction(t *testing.T) {
+	client, done := connect(t, nil, nil)
+	if _, err := client.Step(conformance.State{Code: corpus.Bytes{0x00}}, 1); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("expected unsupported step error, got %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("worker failed: %v", err)
+	}
+}
+
+func TestReadFrame_RejectsOversizedFrames(t *testing.T) {
+	var message Request
+	err := ReadFrame(strings.NewReader("PASS\n"), &message)
+	if err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("expected size error, got %v", err)
+	}
+}
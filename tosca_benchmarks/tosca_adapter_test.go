@@ -0,0 +1,212 @@
+// Adapter executing engine neutral corpus vectors on Tosca interpreters.
+// Vectors run as a call from corpus.DefaultCaller to corpus.DefaultRecipient
+// in the revision selected with -revision (Cancun by default) with sender,
+// recipient, precompiles and, from Shanghai, the coinbase pre-warmed,
+// mirroring the BSC interpreter adapter.
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/0xsoniclabs/tosca/go/interpreter/lfvm"
+	"github.com/0xsoniclabs/tosca/go/tosca"
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+var revisionFlag = flag.String("revision", corpus.DefaultRevision.String(), "EVM revision corpus vectors are executed in")
+
+// corpusRevision returns the revision selected with -revision.
+func corpusRevision() corpus.Revision {
+	revision, err := corpus.ParseRevision(*revisionFlag)
+	if err != nil {
+		panic(err)
+	}
+	return revision
+}
+
+var toscaRevisions = map[corpus.Revision]tosca.Revision{
+	corpus.Istanbul: tosca.R07_Istanbul,
+	corpus.Berlin:   tosca.R09_Berlin,
+	corpus.London:   tosca.R10_London,
+	corpus.Paris:    tosca.R11_Paris,
+	corpus.Shanghai: tosca.R12_Shanghai,
+	corpus.Cancun:   tosca.R13_Cancun,
+}
+
+// toscaBlockParameters returns the block environment shared by all corpus
+// runs.
+func toscaBlockParameters(revision corpus.Revision) tosca.BlockParameters {
+	var chainID tosca.Word
+	chainID[31] = corpus.ChainID
+	block := tosca.BlockParameters{
+		ChainID:     chainID,
+		BlockNumber: corpus.BlockNumber,
+		Timestamp:   corpus.Timestamp,
+		GasLimit:    corpus.BlockGasLimit,
+		Revision:    toscaRevisions[revision],
+	}
+	if revision >= corpus.Cancun {
+		block.BlobBaseFee = tosca.NewValue(corpus.BlobBaseFee)
+	}
+	return block
+}
+
+// toscaTransactionParameters returns the transaction environment shared by
+// all corpus runs, carrying the blobs of corpus.BlobHashes from Cancun.
+func toscaTransactionParameters(revision corpus.Revision) tosca.TransactionParameters {
+	transaction := tosca.TransactionParameters{Origin: tosca.Address(corpus.DefaultCaller)}
+	if revision >= corpus.Cancun {
+		for _, hash := range corpus.BlobHashes {
+			transaction.BlobHashes = append(transaction.BlobHashes, tosca.Hash(hash))
+		}
+	}
+	return transaction
+}
+
+// toscaRunner executes a single vector repeatedly. Every run is rolled back,
+// so each iteration observes the same pre-state.
+type toscaRunner struct {
+	context *toscaContext
+	params  tosca.Parameters
+	logs    []tosca.Log // emitted by the last run
+}
+
+func newToscaRunner(interpreter tosca.Interpreter, vector corpus.Vector) *toscaRunner {
+	block := toscaBlockParameters(corpusRevision())
+	transaction := toscaTransactionParameters(corpusRevision())
+	context := newToscaContext(interpreter, block, transaction)
+
+	storage := make(map[tosca.Key]tosca.Word, len(vector.Storage))
+	for key, value := range vector.Storage {
+		storage[tosca.Key(key)] = tosca.Word(value)
+	}
+	recipient := tosca.Address(corpus.DefaultRecipient)
+	context.setAccount(recipient, tosca.Code(vector.Code), storage)
+	context.setAccount(tosca.Address(corpus.DefaultCaller), nil, nil)
+	context.warm(tosca.Address(corpus.DefaultCaller), recipient)
+	context.warm(toscaPrecompileAddresses(block.Revision)...)
+	if corpusRevision() >= corpus.Shanghai {
+		context.warm(block.Coinbase) // EIP-3651
+	}
+
+	codeHash := keccak(vector.Code)
+	return &toscaRunner{
+		context: context,
+		params: tosca.Parameters{
+			BlockParameters:       block,
+			TransactionParameters: transaction,
+			Context:               context,
+			Kind:                  tosca.Call,
+			Gas:                   tosca.Gas(vector.Gas),
+			Recipient:             recipient,
+			Sender:                tosca.Address(corpus.DefaultCaller),
+			Input:                 tosca.Data(vector.Input),
+			CodeHash:              &codeHash,
+			Code:                  tosca.Code(vector.Code),
+		},
+	}
+}
+
+// run executes the vector once and reverts all state changes afterwards.
+func (r *toscaRunner) run() (tosca.Result, error) {
+	snapshot := r.context.CreateSnapshot()
+	result, err := r.context.interpreter.Run(r.params)
+	// The rollback truncates the logs of the context but leaves them in
+	// place until the next run.
+	r.logs = r.context.GetLogs()
+	r.context.RestoreSnapshot(snapshot)
+	return result, err
+}
+
+// outcome converts the result of the last run into an engine neutral
+// outcome including the logs it emitted.
+func (r *toscaRunner) outcome(result tosca.Result) corpus.Outcome {
+	outcome := toscaOutcome(uint64(r.params.Gas), result)
+	if result.Success && len(r.logs) > 0 {
+		outcome.Logs = make([]corpus.Log, len(r.logs))
+		for i, log := range r.logs {
+			outcome.Logs[i].Data = corpus.Bytes(log.Data)
+			for _, topic := range log.Topics {
+				outcome.Logs[i].Topics = append(outcome.Logs[i].Topics, corpus.Hash(topic))
+			}
+		}
+	}
+	return outcome
+}
+
+// toscaOutcome converts an interpreter result into an engine neutral
+// outcome without logs, which are kept by the context. Tosca does not
+// distinguish reverts from failures in its result. Failures consume all gas
+// and return no data, so an unsuccessful run with gas left or output is a
+// revert. A REVERT of no data spending exactly all gas looks like a failure
+// and is reported as one, while BSC reports a revert; the gas used is the
+// same either way.
+func toscaOutcome(gas uint64, result tosca.Result) corpus.Outcome {
+	status := corpus.Success
+	if !result.Success {
+		status = corpus.Failure
+		if result.GasLeft > 0 || len(result.Output) > 0 {
+			status = corpus.Revert
+		}
+	}
+	return corpus.Outcome{
+		Status:  status,
+		Output:  corpus.Bytes(result.Output),
+		GasUsed: gas - uint64(result.GasLeft),
+	}
+}
+
+func TestToscaRunner_WarmsCoinbaseFromShanghai(t *testing.T) {
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+	// COINBASE, BALANCE, STOP
+	vector := corpus.Vector{Name: "coinbase", Code: []byte{0x41, 0x31, 0x00}, Gas: 10_000}
+	runner := newToscaRunner(interpreter, vector)
+	result, err := runner.run()
+	if err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	want := uint64(2 + 2600)
+	if corpusRevision() >= corpus.Shanghai {
+		want = 2 + 100
+	}
+	if got := runner.outcome(result).GasUsed; got != want {
+		t.Errorf("BALANCE of the coinbase used %d gas, wanted %d", got, want)
+	}
+}
+
+func TestToscaOutcome_RevertSpendingAllGas(t *testing.T) {
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+	tests := map[string]struct {
+		code []byte
+		gas  uint64
+		want corpus.Status
+	}{
+		// PUSH1 1, PUSH1 0, REVERT: 3+3 gas and 3 for a word of memory
+		"revert with output":      {[]byte{0x60, 0x01, 0x60, 0x00, 0xfd}, 9, corpus.Revert},
+		"revert with gas left":    {[]byte{0x60, 0x00, 0x60, 0x00, 0xfd}, 7, corpus.Revert},
+		"revert of nothing":       {[]byte{0x60, 0x00, 0x60, 0x00, 0xfd}, 6, corpus.Failure}, // indistinguishable
+		"out of gas":              {[]byte{0x60, 0x00, 0x60, 0x00, 0xfd}, 5, corpus.Failure},
+		"invalid instruction":     {[]byte{0xfe}, 100, corpus.Failure},
+		"return spending all gas": {[]byte{0x60, 0x01, 0x60, 0x00, 0xf3}, 9, corpus.Success},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			runner := newToscaRunner(interpreter, corpus.Vector{Name: name, Code: test.code, Gas: test.gas})
+			result, err := runner.run()
+			if err != nil {
+				t.Fatalf("Execution failed: %v", err)
+			}
+			outcome := runner.outcome(result)
+			if outcome.Status != test.want || outcome.GasUsed != test.gas-uint64(result.GasLeft) {
+				t.Errorf("got %s using %d gas, wanted %s", outcome.Status, outcome.GasUsed, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Allocation attribution report for Tosca LFVM
+// Run with: go test -run TestAllocationReport -alloc-report
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/0xsoniclabs/tosca/go/interpreter/lfvm"
+	"github.com/0xsoniclabs/tosca/go/tosca"
+	"github.com/sonicoperations/evmbench/allocs"
+)
+
+var allocReport = flag.Bool("alloc-report", false, "print the per-vector allocation breakdown")
+
+// lfvmAllocationRules attribute LFVM allocations to the parts of a run.
+// Allocations of the in-memory RunContext stand in for the StateDB of a
+// real client and are reported separately from the interpreter.
+func lfvmAllocationRules() []allocs.Rule {
+	pkg := allocs.CallerPackage()
+	return append([]allocs.Rule{
+		{Category: "stack allocation", Prefixes: []string{"lfvm.NewStack"}},
+		{Category: "memory growth", Prefixes: []string{"lfvm.NewMemory", "lfvm.(*Memory)."}},
+		{Category: "result copy", Prefixes: []string{"lfvm.getData", "lfvm.opReturn", "lfvm.opRevert"}},
+		{Category: "log topics", Prefixes: []string{"lfvm.opLog"}},
+		{Category: "converter output", Prefixes: []string{"lfvm.(*Converter).", "lfvm.convert"}},
+		{Category: "execution context", Prefixes: []string{"lfvm.run"}, SiteOnly: true},
+		{Category: "parameters copying", Prefixes: []string{"lfvm.(*lfvm).Run"}, SiteOnly: true},
+		{Category: "host: precompiles", Prefixes: []string{"vm."}},
+		{Category: "host: run context", Prefixes: []string{pkg + ".(*toscaContext)."}},
+	}, allocs.HarnessRules(pkg)...)
+}
+
+func TestAllocationReport(t *testing.T) {
+	if !*allocReport {
+		t.Skip("enable with -alloc-report")
+	}
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+
+	rules := lfvmAllocationRules()
+	var reports []allocs.Report
+
+	// The loop body of BenchmarkSimpleOperations, including the harness
+	// decoding the code on every iteration as older benchmarks did.
+	reports = append(reports, allocs.Measure("lfvm", "legacy/SimpleArithmetic", 1000, rules, func() {
+		code, _ := hex.DecodeString(simpleArithmeticCode)
+		_, _ = interpreter.Run(tosca.Parameters{Gas: 10000, Code: code})
+	}))
+
+	for _, contract := range loadContracts(t) {
+		for _, vector := range contract.Vectors() {
+			runner := newToscaRunner(interpreter, vector)
+			reports = append(reports, allocs.Measure("lfvm", vector.Name, 1000, rules, func() {
+				_, _ = runner.run()
+			}))
+		}
+	}
+	if err := allocs.WriteMarkdown(os.Stdout, reports...); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/0xsoniclabs/tosca/go/interpreter/lfvm"
 	"github.com/0xsoniclabs/tosca/go/tosca"
+	"github.com/sonicoperations/evmbench/corpus"
 )
 
 // Simple arithmetic bytecode for basic performance testing
@@ -39,7 +40,7 @@ func BenchmarkSimpleOperations(b *testing.B) {
 // Optimized LFVM benchmarks with super-instructions enabled
 func BenchmarkOptimizedLFVM(b *testing.B) {
 	// Register experimental configurations to enable super-instructions
-	err := lfvm.RegisterExperimentalInterpreterConfigurations()
+	err := registerExperimentalInterpreters()
 	if err != nil {
 		b.Fatalf("Failed to register experimental configurations: %v", err)
 	}
@@ -310,7 +311,7 @@ func BenchmarkExtensiveOpcodeCoverageWithCaching(b *testing.B) {
 // Extensive opcode coverage benchmarks for Tosca LFVM (OPTIMIZED WITH CACHING)
 func BenchmarkExtensiveOpcodeCoverageOptimizedWithCaching(b *testing.B) {
 	// Register experimental configurations
-	err := lfvm.RegisterExperimentalInterpreterConfigurations()
+	err := registerExperimentalInterpreters()
 	if err != nil {
 		b.Fatalf("Failed to register experimental configurations: %v", err)
 	}
@@ -472,7 +473,7 @@ func BenchmarkRepeatedContractCalls(b *testing.B) {
 // Benchmark repeated calls with optimized LFVM (super-instructions + caching)
 func BenchmarkOptimizedRepeatedCalls(b *testing.B) {
 	// Register experimental configurations
-	err := lfvm.RegisterExperimentalInterpreterConfigurations()
+	err := registerExperimentalInterpreters()
 	if err != nil {
 		b.Fatalf("Failed to register experimental configurations: %v", err)
 	}
@@ -530,7 +531,7 @@ func BenchmarkPreConvertedExecution(b *testing.B) {
 	}
 
 	// Register experimental configurations
-	err = lfvm.RegisterExperimentalInterpreterConfigurations()
+	err = registerExperimentalInterpreters()
 	if err != nil {
 		b.Fatalf("Failed to register experimental configurations: %v", err)
 	}
@@ -595,7 +596,6 @@ func BenchmarkCacheWarming(b *testing.B) {
 
 	testCases := map[string]string{
 		"SimpleArithmetic":     simpleArithmeticCode,
-		"BEP20Token":          bep20ContractBytecode[:1000], // First 1000 chars of BEP20 contract
 		"ComplexContract":     "608060405234801561001057600080fd5b506004361061012c5760003560e01c8063893d20e8", // Complex initialization
 	}
 
@@ -635,6 +635,27 @@ func BenchmarkCacheWarming(b *testing.B) {
 			}
 		})
 	}
+
+	// A full ERC20 transfer from the contract corpus, executed with state
+	// access through the corpus adapter. The adapter provides the code hash,
+	// so all calls after the first one hit the conversion cache.
+	b.Run("ERC20Transfer", func(b *testing.B) {
+		erc20, err := corpus.LoadContractFromRoot("erc20")
+		if err != nil {
+			b.Fatalf("Failed to load ERC20 contract: %v", err)
+		}
+		call, _ := erc20.Call("transfer")
+		runner := newToscaRunner(interpreter, erc20.Vector(call))
+
+		// First call - cache miss (conversion happens)
+		_, _ = runner.run()
+
+		// Now benchmark subsequent calls - cache hits
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, _ = runner.run()
+		}
+	})
 }
 
 func buildMemoryBoundaryCode() string {
@@ -0,0 +1,38 @@
+// Code size scaling benchmarks for the LFVM code conversion
+// Converts the code of the code size families of the shared corpus with and
+// without super-instructions. evmbench codesize relates the times to BSC's
+// JUMPDEST analysis of the same code.
+package main
+
+import (
+	"testing"
+
+	"github.com/0xsoniclabs/tosca/go/interpreter/lfvm"
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+func BenchmarkCodeAnalysis(b *testing.B) {
+	configs := map[string]lfvm.ConversionConfig{
+		"convert":       {CacheSize: -1},
+		"convert-super": {CacheSize: -1, WithSuperInstructions: true},
+	}
+	for _, family := range corpus.CodeSizeFamilies() {
+		for _, vector := range family.Vectors() {
+			for _, name := range []string{"convert", "convert-super"} {
+				b.Run(vector.Name+"/"+name, func(b *testing.B) {
+					converter, err := lfvm.NewConverter(configs[name])
+					if err != nil {
+						b.Fatalf("Failed to create converter: %v", err)
+					}
+					b.SetBytes(int64(len(vector.Code)))
+					b.ResetTimer()
+					for i := 0; i < b.N; i++ {
+						if _, err := converter.Convert(vector.Code, nil); err != nil {
+							b.Fatalf("Conversion failed: %v", err)
+						}
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,482 @@
+// Tosca's conformance test specification on LFVM and BSC
+// Generates states from the rules of Tosca's CT specification (go/ct/spc)
+// and executes one instruction of each on LFVM and on the BSC interpreter,
+// comparing both to the state the specification expects. Run with:
+// go test -run TestConformance -ct [-ct-filter <rule regexp>]
+//
+// BSC cannot be linked into this module, so bscCTTarget exposes a BSC
+// worker process through the CT interface: states are converted to the
+// engine neutral conformance.State, stepped by the worker built from
+// ../bsc_interpreter_benchmarks (or given with -ct-worker) and the changes
+// are applied to a copy of the input state. Calls and contract creations
+// are resolved from the specification's call journal, which BSC cannot
+// consult; states executing them are skipped, as are states of revisions
+// outside the corpus.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/0xsoniclabs/tosca/go/ct"
+	. "github.com/0xsoniclabs/tosca/go/ct/common"
+	"github.com/0xsoniclabs/tosca/go/ct/rlz"
+	"github.com/0xsoniclabs/tosca/go/ct/spc"
+	"github.com/0xsoniclabs/tosca/go/ct/st"
+	"github.com/0xsoniclabs/tosca/go/interpreter/lfvm"
+	"github.com/0xsoniclabs/tosca/go/tosca"
+	"github.com/0xsoniclabs/tosca/go/tosca/vm"
+	"github.com/sonicoperations/evmbench/conformance"
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+	"github.com/sonicoperations/evmbench/worker"
+	"pgregory.net/rand"
+)
+
+var (
+	ctFlag   = flag.Bool("ct", false, "run the CT specification on LFVM and BSC in TestConformance")
+	ctFilter = flag.String("ct-filter", "", "regular expression selecting CT rules by name; all if empty")
+	ctStates = flag.Int("ct-states", 10, "states generated per CT rule")
+	ctSeed   = flag.Uint64("ct-seed", 42, "seed of the CT state generation")
+	ctWorker = flag.String("ct-worker", "", "BSC worker binary; built from ../bsc_interpreter_benchmarks if empty")
+	ctDiffs  = flag.Int("ct-diffs", 10, "differences logged in detail")
+)
+
+// ctUnsupported lists the instructions resolved from the call journal.
+var ctUnsupported = map[vm.OpCode]bool{
+	vm.CALL: true, vm.CALLCODE: true, vm.DELEGATECALL: true, vm.STATICCALL: true, vm.CREATE: true, vm.CREATE2: true,
+}
+
+var ctStatuses = map[st.StatusCode]conformance.Status{
+	st.Running:  conformance.Running,
+	st.Stopped:  conformance.Stopped,
+	st.Reverted: conformance.Reverted,
+	st.Failed:   conformance.Failed,
+}
+
+// ctExport mirrors the parts of Tosca's state serialization that are not
+// accessible otherwise.
+type ctExport struct {
+	Stack   []U256
+	Memory  Bytes
+	Storage *struct {
+		Current, Original map[U256]U256
+		Warm              map[U256]bool
+	}
+	TransientStorage *struct{ Storage map[U256]U256 }
+	Accounts         *struct {
+		Balance map[tosca.Address]U256
+		Code    map[tosca.Address]Bytes
+		Warm    map[tosca.Address]bool
+	}
+	SelfDestructedJournal []struct{ Account, Beneficiary tosca.Address }
+}
+
+func ctHash(value U256) corpus.Hash {
+	return corpus.Hash(value.Bytes32be())
+}
+
+// ctToConformance converts a CT state, using scratch as a temporary file.
+// States of revisions outside the corpus are reported as
+// tosca.ErrUnsupportedRevision.
+func ctToConformance(state *st.State, scratch string) (conformance.State, error) {
+	revision, found := corpus.Revision(0), false
+	for r, toscaRevision := range toscaRevisions {
+		if toscaRevision == state.Revision {
+			revision, found = r, true
+		}
+	}
+	if !found {
+		return conformance.State{}, &tosca.ErrUnsupportedRevision{Revision: state.Revision}
+	}
+	if err := st.ExportStateJSON(state, scratch); err != nil {
+		return conformance.State{}, err
+	}
+	data, err := os.ReadFile(scratch)
+	if err != nil {
+		return conformance.State{}, err
+	}
+	var export ctExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return conformance.State{}, err
+	}
+
+	res := conformance.State{
+		Status:             ctStatuses[state.Status],
+		Revision:           revision,
+		ReadOnly:           state.ReadOnly,
+		Pc:                 uint64(state.Pc),
+		Gas:                int64(state.Gas),
+		Refund:             int64(state.GasRefund),
+		Code:               state.Code.Copy(),
+		Memory:             export.Memory.ToBytes(),
+		Address:            corpus.Address(state.CallContext.AccountAddress),
+		Caller:             corpus.Address(state.CallContext.CallerAddress),
+		Value:              ctHash(state.CallContext.Value),
+		CallData:           state.CallData.ToBytes(),
+		LastCallReturnData: state.LastCallReturnData.ToBytes(),
+		ReturnData:         state.ReturnData.ToBytes(),
+		HasSelfDestructed:  state.HasSelfDestructed,
+	}
+	for _, value := range export.Stack {
+		res.Stack = append(res.Stack, ctHash(value))
+	}
+	if export.Storage != nil {
+		res.Storage = map[corpus.Hash]conformance.Slot{}
+		slot := func(key U256) conformance.Slot {
+			return conformance.Slot{
+				Current:  ctHash(export.Storage.Current[key]),
+				Original: ctHash(export.Storage.Original[key]),
+				Warm:     export.Storage.Warm[key],
+			}
+		}
+		for _, keys := range []map[U256]U256{export.Storage.Current, export.Storage.Original} {
+			for key := range keys {
+				res.Storage[ctHash(key)] = slot(key)
+			}
+		}
+		for key := range export.Storage.Warm {
+			res.Storage[ctHash(key)] = slot(key)
+		}
+	}
+	if export.TransientStorage != nil {
+		res.Transient = map[corpus.Hash]corpus.Hash{}
+		for key, value := range export.TransientStorage.Storage {
+			res.Transient[ctHash(key)] = ctHash(value)
+		}
+	}
+	if export.Accounts != nil {
+		res.Accounts = map[corpus.Address]conformance.Account{}
+		res.WarmAccounts = map[corpus.Address]bool{}
+		for address, balance := range export.Accounts.Balance {
+			res.Accounts[corpus.Address(address)] = conformance.Account{Balance: ctHash(balance), Code: export.Accounts.Code[address].ToBytes()}
+		}
+		for address, warm := range export.Accounts.Warm {
+			res.WarmAccounts[corpus.Address(address)] = warm
+		}
+	}
+	for _, entry := range state.Logs.Entries {
+		log := conformance.Log{Data: bytes.Clone(entry.Data)}
+		for _, topic := range entry.Topics {
+			log.Topics = append(log.Topics, ctHash(topic))
+		}
+		res.Logs = append(res.Logs, log)
+	}
+	for _, entry := range export.SelfDestructedJournal {
+		res.SelfDestructs = append(res.SelfDestructs, conformance.SelfDestruct{
+			Account:     corpus.Address(entry.Account),
+			Beneficiary: corpus.Address(entry.Beneficiary),
+		})
+	}
+
+	block := state.BlockContext
+	res.Block = conformance.Block{
+		ChainID:     ctHash(block.ChainID),
+		Number:      block.BlockNumber,
+		Timestamp:   block.TimeStamp,
+		Coinbase:    corpus.Address(block.CoinBase),
+		GasLimit:    block.GasLimit,
+		GasPrice:    ctHash(block.GasPrice),
+		PrevRandao:  ctHash(block.PrevRandao),
+		BaseFee:     ctHash(block.BaseFee),
+		BlobBaseFee: ctHash(block.BlobBaseFee),
+		Origin:      corpus.Address(state.TransactionContext.OriginAddress),
+	}
+	for i := uint64(0); i < 256; i++ {
+		res.Block.RecentHashes = append(res.Block.RecentHashes, corpus.Hash(state.RecentBlockHashes.Get(i)))
+	}
+	for _, hash := range state.TransactionContext.BlobHashes {
+		res.Block.BlobHashes = append(res.Block.BlobHashes, corpus.Hash(hash))
+	}
+	return res, nil
+}
+
+// ctApply returns a copy of input with the changes of an engine's result
+// applied. Code, environment, balances and the call journal cannot be
+// changed by the instructions an engine steps and are kept.
+func ctApply(input *st.State, result conformance.State) *st.State {
+	state := input.Clone()
+	for code, status := range ctStatuses {
+		if status == result.Status {
+			state.Status = code
+		}
+	}
+	state.Pc = uint16(min(result.Pc, 1<<16-1))
+	state.Gas = tosca.Gas(result.Gas)
+	state.GasRefund = tosca.Gas(result.Refund)
+	values := make([]U256, len(result.Stack))
+	for i, value := range result.Stack {
+		values[i] = NewU256FromBytes(value[:]...)
+	}
+	state.Stack = st.NewStack(values...)
+	state.Memory = st.NewMemory(bytes.Clone(result.Memory)...)
+	for key, slot := range result.Storage {
+		k, current := NewU256FromBytes(key[:]...), NewU256FromBytes(slot.Current[:]...)
+		if state.Storage.GetCurrent(k) != current {
+			state.Storage.SetCurrent(k, current)
+		}
+		if slot.Warm && !state.Storage.IsWarm(k) {
+			state.Storage.MarkWarm(k)
+		}
+	}
+	for key, value := range result.Transient {
+		k, v := NewU256FromBytes(key[:]...), NewU256FromBytes(value[:]...)
+		if state.TransientStorage.Get(k) != v {
+			state.TransientStorage.Set(k, v)
+		}
+	}
+	for address, warm := range result.WarmAccounts {
+		if warm && !state.Accounts.IsWarm(tosca.Address(address)) {
+			state.Accounts.MarkWarm(tosca.Address(address))
+		}
+	}
+	state.Logs = st.NewLogs()
+	for _, log := range result.Logs {
+		topics := make([]U256, len(log.Topics))
+		for i, topic := range log.Topics {
+			topics[i] = NewU256FromBytes(topic[:]...)
+		}
+		state.Logs.AddLog(bytes.Clone(log.Data), topics...)
+	}
+	state.HasSelfDestructed = result.HasSelfDestructed
+	state.SelfDestructedJournal = nil
+	for _, entry := range result.SelfDestructs {
+		state.SelfDestructedJournal = append(state.SelfDestructedJournal,
+			st.NewSelfDestructEntry(tosca.Address(entry.Account), tosca.Address(entry.Beneficiary)))
+	}
+	state.LastCallReturnData = NewBytes(result.LastCallReturnData)
+	state.ReturnData = NewBytes(result.ReturnData)
+	return state
+}
+
+// bscCTTarget steps CT states on the BSC interpreter of a worker process.
+type bscCTTarget struct {
+	client  *worker.Client
+	scratch string
+}
+
+var _ ct.Evm = bscCTTarget{}
+
+func (b bscCTTarget) StepN(state *st.State, numSteps int) (*st.State, error) {
+	input, err := ctToConformance(state, b.scratch)
+	if err != nil {
+		return nil, err
+	}
+	result, err := b.client.Step(input, numSteps)
+	if err != nil {
+		return nil, err
+	}
+	return ctApply(state, result), nil
+}
+
+// startBSCWorker starts the BSC worker given with -ct-worker or built from
+// the BSC module.
+func startBSCWorker(t *testing.T) *worker.Client {
+	t.Helper()
+	binary := *ctWorker
+	if binary == "" {
+		binary = filepath.Join(t.TempDir(), "bsc.test")
+		build := exec.Command("go", "test", "-c", "-o", binary, ".")
+		build.Dir = filepath.Join("..", "bsc_interpreter_benchmarks")
+		if out, err := build.CombinedOutput(); err != nil {
+			t.Fatalf("Failed to build BSC worker: %v\n%s", err, out)
+		}
+	}
+	cmd := exec.Command(binary, "-test.run", "^TestWorker$", "-worker")
+	cmd.Stderr = os.Stderr
+	client, err := worker.Start(cmd)
+	if err != nil {
+		t.Fatalf("Failed to start BSC worker: %v", err)
+	}
+	return client
+}
+
+// ctCounts are the comparison results of the states of one instruction.
+type ctCounts struct {
+	states, lfvm, bsc, bscLfvm, skipped int
+}
+
+func TestConformance(t *testing.T) {
+	if !*ctFlag {
+		t.Skip("use -ct to run the CT specification on LFVM and BSC")
+	}
+	filter, err := regexp.Compile(*ctFilter)
+	if err != nil {
+		t.Fatalf("Invalid -ct-filter: %v", err)
+	}
+	client := startBSCWorker(t)
+	defer func() {
+		if err := client.Close(); err != nil {
+			t.Errorf("BSC worker failed: %v", err)
+		}
+	}()
+	bsc := bscCTTarget{client: client, scratch: filepath.Join(t.TempDir(), "state.json")}
+	lfvmTarget := lfvm.NewConformanceTestingTarget()
+
+	counts := map[vm.OpCode]*ctCounts{}
+	diffs := 0
+	report := func(input, result, expected *st.State, engine, reference string) {
+		if diffs++; diffs > *ctDiffs {
+			return
+		}
+		rule := spc.Spec.GetRulesFor(input)[0].Name
+		t.Logf("%s differs from %s in rule %s:\n%v", engine, reference, rule, result.Diff(expected))
+	}
+	compare := func(input *st.State) {
+		op, _ := input.Code.GetOperation(int(input.Pc))
+		c := counts[op]
+		if c == nil {
+			c = &ctCounts{}
+			counts[op] = c
+		}
+		rules := spc.Spec.GetRulesFor(input)
+		if len(rules) == 0 || ctUnsupported[op] {
+			c.skipped++
+			return
+		}
+		expected := input.Clone()
+		rules[0].Effect.Apply(expected)
+
+		lfvmResult, err := lfvmTarget.StepN(input.Clone(), 1)
+		var unsupported *tosca.ErrUnsupportedRevision
+		if errors.As(err, &unsupported) {
+			c.skipped++
+			return
+		} else if err != nil {
+			t.Fatalf("LFVM failed: %v", err)
+		}
+		bscResult, err := bsc.StepN(input.Clone(), 1)
+		if errors.As(err, &unsupported) {
+			c.skipped++
+			return
+		}
+
+		c.states++
+		if !lfvmResult.Eq(expected) {
+			c.lfvm++
+			report(input, lfvmResult, expected, "lfvm", "spec")
+		}
+		if err != nil {
+			c.bsc++
+			c.bscLfvm++
+			if diffs++; diffs <= *ctDiffs {
+				t.Logf("bsc failed in rule %s: %v", rules[0].Name, err)
+			}
+			return
+		}
+		if !bscResult.Eq(expected) {
+			c.bsc++
+			report(input, bscResult, expected, "bsc", "spec")
+		}
+		if !bscResult.Eq(lfvmResult) {
+			c.bscLfvm++
+		}
+	}
+
+	for _, rule := range spc.FilterRules(spc.Spec.GetRules(), filter) {
+		generated := 0
+		// The generator reuses states; the conditions are checked as
+		// spc.ForEachState does outside its full mode.
+		err := rule.EnumerateTestCases(rand.New(*ctSeed), func(state *st.State) rlz.ConsumerResult {
+			if applies, err := rule.Condition.Check(state); !applies || err != nil {
+				return rlz.ConsumeContinue
+			}
+			if !state.Code.IsCode(int(state.Pc)) {
+				return rlz.ConsumeContinue
+			}
+			compare(state.Clone())
+			if generated++; generated >= *ctStates {
+				return rlz.ConsumeAbort
+			}
+			return rlz.ConsumeContinue
+		})
+		if err != nil {
+			t.Fatalf("Failed to generate states of rule %s: %v", rule.Name, err)
+		}
+	}
+
+	ops := make([]vm.OpCode, 0, len(counts))
+	for op := range counts {
+		ops = append(ops, op)
+	}
+	slices.Sort(ops)
+	table := results.Table{Header: []string{"Opcode", "states", "lfvm != spec", "bsc != spec", "bsc != lfvm", "skipped"}}
+	var total ctCounts
+	row := func(name string, c ctCounts) []string {
+		return []string{name, strconv.Itoa(c.states), strconv.Itoa(c.lfvm), strconv.Itoa(c.bsc), strconv.Itoa(c.bscLfvm), strconv.Itoa(c.skipped)}
+	}
+	for _, op := range ops {
+		c := *counts[op]
+		table.Rows = append(table.Rows, row(op.String(), c))
+		total.states += c.states
+		total.lfvm += c.lfvm
+		total.bsc += c.bsc
+		total.bscLfvm += c.bscLfvm
+		total.skipped += c.skipped
+	}
+	table.Rows = append(table.Rows, row("total", total))
+	if err := table.Write(os.Stdout, "markdown"); err != nil {
+		t.Fatal(err)
+	}
+	if diffs > *ctDiffs {
+		fmt.Printf("%d further differences not shown, raise -ct-diffs to see them\n", diffs-*ctDiffs)
+	}
+}
+
+func TestCTConversion_RoundTripsStates(t *testing.T) {
+	scratch := filepath.Join(t.TempDir(), "state.json")
+	rules := spc.FilterRules(spc.Spec.GetRules(), regexp.MustCompile("^(sstore_regular|log2_regular|selfdestruct_regular|tstore_regular|blockhash_regular)"))
+	if len(rules) == 0 {
+		t.Fatal("no rules selected")
+	}
+	converted := 0
+	for _, rule := range rules {
+		err := rule.EnumerateTestCases(rand.New(1), func(state *st.State) rlz.ConsumerResult {
+			res, err := ctToConformance(state, scratch)
+			var unsupported *tosca.ErrUnsupportedRevision
+			if errors.As(err, &unsupported) {
+				return rlz.ConsumeContinue
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if back := ctApply(state, res); !back.Eq(state) {
+				t.Fatalf("state of rule %s changed by conversion: %v", rule.Name, back.Diff(state))
+			}
+			converted++
+			return rlz.ConsumeAbort
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if converted == 0 {
+		t.Fatal("no state converted")
+	}
+
+	state := st.NewState(st.NewCode([]byte{byte(vm.SSTORE)}))
+	state.Revision = tosca.R13_Cancun
+	state.Storage = st.NewStorageBuilder().SetOriginal(NewU256(1), NewU256(2)).Build()
+	res, err := ctToConformance(state, scratch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Storage[corpus.Hash{31: 1}] = conformance.Slot{Current: corpus.Hash{31: 3}, Original: corpus.Hash{31: 2}, Warm: true}
+	res.Logs = append(res.Logs, conformance.Log{Topics: []corpus.Hash{{31: 4}}, Data: []byte{5}})
+	want := state.Clone()
+	want.Storage.SetCurrent(NewU256(1), NewU256(3))
+	want.Storage.MarkWarm(NewU256(1))
+	want.Logs.AddLog([]byte{5}, NewU256(4))
+	if back := ctApply(state, res); !back.Eq(want) {
+		t.Errorf("changes not applied: %v", back.Diff(want))
+	}
+}
@@ -0,0 +1,544 @@
+// In-memory RunContext for executing corpus vectors on Tosca interpreters.
+// Tosca interpreters only see the world through tosca.RunContext, so this
+// file provides the state, access lists, logs and nested calls a full
+// client would supply. All modifications are journaled to allow rolling back
+// every benchmark iteration with CreateSnapshot/RestoreSnapshot.
+package main
+
+import (
+	"bytes"
+	"maps"
+
+	"github.com/0xsoniclabs/tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/common"
+	geth "github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sonicoperations/evmbench/corpus"
+	"golang.org/x/crypto/sha3"
+)
+
+type toscaAccount struct {
+	balance        tosca.Value
+	nonce          uint64
+	code           tosca.Code
+	storage        map[tosca.Key]tosca.Word
+	committed      map[tosca.Key]tosca.Word // storage at the start of the transaction
+	selfDestructed bool
+	created        bool // by the transaction, destructed for good by EIP-6780
+}
+
+type toscaSlot struct {
+	address tosca.Address
+	key     tosca.Key
+}
+
+// toscaContext implements tosca.RunContext on top of in-memory accounts.
+// Nested calls are executed recursively on the same interpreter.
+type toscaContext struct {
+	interpreter tosca.Interpreter
+	block       tosca.BlockParameters
+	transaction tosca.TransactionParameters
+	depth       int
+	static      bool
+
+	accounts       map[tosca.Address]*toscaAccount
+	transient      map[toscaSlot]tosca.Word
+	accessAccounts map[tosca.Address]bool
+	accessSlots    map[toscaSlot]bool
+	logs           []tosca.Log
+
+	// touched holds the accounts of EIP-161, those the transaction modified
+	// or called, which are removed at its end if empty.
+	touched map[tosca.Address]bool
+
+	// blockHash overrides the zero block hashes seen by corpus vectors.
+	blockHash func(number int64) tosca.Hash
+
+	journal []func()
+}
+
+func newToscaContext(interpreter tosca.Interpreter, block tosca.BlockParameters, transaction tosca.TransactionParameters) *toscaContext {
+	return &toscaContext{
+		interpreter:    interpreter,
+		block:          block,
+		transaction:    transaction,
+		accounts:       map[tosca.Address]*toscaAccount{},
+		transient:      map[toscaSlot]tosca.Word{},
+		accessAccounts: map[tosca.Address]bool{},
+		accessSlots:    map[toscaSlot]bool{},
+		touched:        map[tosca.Address]bool{},
+	}
+}
+
+// setAccount installs an account with the given code and committed storage
+// outside of the journal.
+func (c *toscaContext) setAccount(address tosca.Address, code tosca.Code, storage map[tosca.Key]tosca.Word) {
+	if storage == nil {
+		storage = map[tosca.Key]tosca.Word{}
+	}
+	c.accounts[address] = &toscaAccount{
+		code:      code,
+		storage:   storage,
+		committed: maps.Clone(storage),
+	}
+}
+
+// warm adds addresses to the access list outside of the journal, as done
+// for the sender, recipient and precompiles at the start of a transaction.
+func (c *toscaContext) warm(addresses ...tosca.Address) {
+	for _, address := range addresses {
+		c.accessAccounts[address] = true
+	}
+}
+
+func (c *toscaContext) record(undo func()) {
+	c.journal = append(c.journal, undo)
+}
+
+// touch marks an account as touched by the transaction.
+func (c *toscaContext) touch(address tosca.Address) {
+	if c.touched[address] {
+		return
+	}
+	c.touched[address] = true
+	c.record(func() { delete(c.touched, address) })
+}
+
+func (c *toscaContext) account(address tosca.Address) *toscaAccount {
+	return c.accounts[address]
+}
+
+// getOrCreate returns the account at address, creating it if needed.
+func (c *toscaContext) getOrCreate(address tosca.Address) *toscaAccount {
+	if account, found := c.accounts[address]; found {
+		return account
+	}
+	account := &toscaAccount{storage: map[tosca.Key]tosca.Word{}, committed: map[tosca.Key]tosca.Word{}}
+	c.accounts[address] = account
+	c.record(func() { delete(c.accounts, address) })
+	return account
+}
+
+// --- tosca.WorldState ---
+
+func (c *toscaContext) AccountExists(address tosca.Address) bool {
+	_, found := c.accounts[address]
+	return found
+}
+
+func (c *toscaContext) CreateAccount(address tosca.Address) {
+	previous, found := c.accounts[address]
+	c.accounts[address] = &toscaAccount{storage: map[tosca.Key]tosca.Word{}, committed: map[tosca.Key]tosca.Word{}}
+	c.touch(address)
+	c.record(func() {
+		if found {
+			c.accounts[address] = previous
+		} else {
+			delete(c.accounts, address)
+		}
+	})
+}
+
+func (c *toscaContext) GetBalance(address tosca.Address) tosca.Value {
+	if account := c.account(address); account != nil {
+		return account.balance
+	}
+	return tosca.Value{}
+}
+
+func (c *toscaContext) SetBalance(address tosca.Address, value tosca.Value) {
+	account := c.getOrCreate(address)
+	previous := account.balance
+	account.balance = value
+	c.record(func() { account.balance = previous })
+	c.touch(address)
+}
+
+func (c *toscaContext) GetNonce(address tosca.Address) uint64 {
+	if account := c.account(address); account != nil {
+		return account.nonce
+	}
+	return 0
+}
+
+func (c *toscaContext) SetNonce(address tosca.Address, nonce uint64) {
+	account := c.getOrCreate(address)
+	previous := account.nonce
+	account.nonce = nonce
+	c.record(func() { account.nonce = previous })
+	c.touch(address)
+}
+
+func (c *toscaContext) GetCode(address tosca.Address) tosca.Code {
+	if account := c.account(address); account != nil {
+		return account.code
+	}
+	return nil
+}
+
+func (c *toscaContext) GetCodeHash(address tosca.Address) tosca.Hash {
+	account := c.account(address)
+	if account == nil {
+		return tosca.Hash{}
+	}
+	return keccak(account.code)
+}
+
+func (c *toscaContext) GetCodeSize(address tosca.Address) int {
+	return len(c.GetCode(address))
+}
+
+func (c *toscaContext) SetCode(address tosca.Address, code tosca.Code) {
+	account := c.getOrCreate(address)
+	previous := account.code
+	account.code = bytes.Clone(code)
+	c.record(func() { account.code = previous })
+	c.touch(address)
+}
+
+func (c *toscaContext) HasEmptyStorage(address tosca.Address) bool {
+	account := c.account(address)
+	if account == nil {
+		return true
+	}
+	for _, value := range account.storage {
+		if value != (tosca.Word{}) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *toscaContext) GetStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	if account := c.account(address); account != nil {
+		return account.storage[key]
+	}
+	return tosca.Word{}
+}
+
+func (c *toscaContext) SetStorage(address tosca.Address, key tosca.Key, value tosca.Word) tosca.StorageStatus {
+	account := c.getOrCreate(address)
+	current, found := account.storage[key]
+	account.storage[key] = value
+	c.record(func() {
+		if found {
+			account.storage[key] = current
+		} else {
+			delete(account.storage, key)
+		}
+	})
+	return tosca.GetStorageStatus(account.committed[key], current, value)
+}
+
+func (c *toscaContext) SelfDestruct(address tosca.Address, beneficiary tosca.Address) bool {
+	account := c.getOrCreate(address)
+	if address != beneficiary {
+		balance := tosca.Add(c.GetBalance(beneficiary), account.balance)
+		c.SetBalance(beneficiary, balance)
+		c.SetBalance(address, tosca.Value{})
+	}
+	if account.selfDestructed {
+		return false
+	}
+	account.selfDestructed = true
+	c.record(func() { account.selfDestructed = false })
+	return true
+}
+
+// --- tosca.TransactionContext ---
+
+func (c *toscaContext) CreateSnapshot() tosca.Snapshot {
+	return tosca.Snapshot(len(c.journal))
+}
+
+func (c *toscaContext) RestoreSnapshot(snapshot tosca.Snapshot) {
+	for len(c.journal) > int(snapshot) {
+		last := len(c.journal) - 1
+		c.journal[last]()
+		c.journal[last] = nil
+		c.journal = c.journal[:last]
+	}
+}
+
+func (c *toscaContext) GetTransientStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	return c.transient[toscaSlot{address, key}]
+}
+
+func (c *toscaContext) SetTransientStorage(address tosca.Address, key tosca.Key, value tosca.Word) {
+	slot := toscaSlot{address, key}
+	previous, found := c.transient[slot]
+	c.transient[slot] = value
+	c.record(func() {
+		if found {
+			c.transient[slot] = previous
+		} else {
+			delete(c.transient, slot)
+		}
+	})
+}
+
+func (c *toscaContext) AccessAccount(address tosca.Address) tosca.AccessStatus {
+	if c.accessAccounts[address] {
+		return tosca.WarmAccess
+	}
+	c.accessAccounts[address] = true
+	c.record(func() { delete(c.accessAccounts, address) })
+	return tosca.ColdAccess
+}
+
+func (c *toscaContext) AccessStorage(address tosca.Address, key tosca.Key) tosca.AccessStatus {
+	slot := toscaSlot{address, key}
+	if c.accessSlots[slot] {
+		return tosca.WarmAccess
+	}
+	c.accessSlots[slot] = true
+	c.record(func() { delete(c.accessSlots, slot) })
+	return tosca.ColdAccess
+}
+
+func (c *toscaContext) EmitLog(log tosca.Log) {
+	c.logs = append(c.logs, log)
+	c.record(func() { c.logs = c.logs[:len(c.logs)-1] })
+}
+
+func (c *toscaContext) GetLogs() []tosca.Log {
+	return c.logs
+}
+
+func (c *toscaContext) GetBlockHash(number int64) tosca.Hash {
+	if c.blockHash != nil {
+		return c.blockHash(number)
+	}
+	return tosca.Hash{}
+}
+
+func (c *toscaContext) GetCommittedStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	if account := c.account(address); account != nil {
+		return account.committed[key]
+	}
+	return tosca.Word{}
+}
+
+func (c *toscaContext) IsAddressInAccessList(address tosca.Address) bool {
+	return c.accessAccounts[address]
+}
+
+func (c *toscaContext) IsSlotInAccessList(address tosca.Address, key tosca.Key) (addressPresent, slotPresent bool) {
+	return c.accessAccounts[address], c.accessSlots[toscaSlot{address, key}]
+}
+
+func (c *toscaContext) HasSelfDestructed(address tosca.Address) bool {
+	account := c.account(address)
+	return account != nil && account.selfDestructed
+}
+
+// --- tosca.RunContext ---
+
+// maxCallDepth is the EVM call depth limit.
+const maxCallDepth = 1024
+
+// Call executes a nested message call or contract creation following the
+// semantics of Tosca's floria processor. Precompiles are served by the geth
+// implementations of the active revision.
+func (c *toscaContext) Call(kind tosca.CallKind, parameters tosca.CallParameters) (tosca.CallResult, error) {
+	if kind == tosca.Create || kind == tosca.Create2 {
+		return c.create(kind, parameters)
+	}
+	failed := tosca.CallResult{GasLeft: parameters.Gas}
+	if c.depth >= maxCallDepth {
+		return failed, nil
+	}
+	transfer := (kind == tosca.Call || kind == tosca.CallCode) && parameters.Value != (tosca.Value{})
+	if transfer && c.GetBalance(parameters.Sender).Cmp(parameters.Value) < 0 {
+		return failed, nil
+	}
+
+	recipient := parameters.Recipient
+	precompile, isPrecompile := toscaPrecompiles(c.block.Revision)[common.Address(recipient)]
+	if !isPrecompile && !transfer && !c.AccountExists(recipient) {
+		return tosca.CallResult{Success: true, GasLeft: parameters.Gas}, nil
+	}
+
+	snapshot := c.CreateSnapshot()
+	if kind == tosca.Call || kind == tosca.StaticCall {
+		c.touch(recipient) // by the transfer, if of no value, as in geth
+	}
+	if transfer && parameters.Sender != recipient {
+		c.SetBalance(parameters.Sender, tosca.Sub(c.GetBalance(parameters.Sender), parameters.Value))
+		c.SetBalance(recipient, tosca.Add(c.GetBalance(recipient), parameters.Value))
+	}
+
+	if isPrecompile {
+		result := runPrecompile(precompile, parameters)
+		if !result.Success {
+			c.RestoreSnapshot(snapshot)
+		}
+		return result, nil
+	}
+
+	codeAddress := recipient
+	if kind == tosca.DelegateCall || kind == tosca.CallCode {
+		codeAddress = parameters.CodeAddress
+	}
+	codeHash := c.GetCodeHash(codeAddress)
+
+	static := c.static
+	c.static = c.static || kind == tosca.StaticCall
+	c.depth++
+	result, err := c.interpreter.Run(tosca.Parameters{
+		BlockParameters:       c.block,
+		TransactionParameters: c.transaction,
+		Context:               c,
+		Kind:                  kind,
+		Static:                c.static,
+		Depth:                 c.depth,
+		Gas:                   parameters.Gas,
+		Recipient:             recipient,
+		Sender:                parameters.Sender,
+		Input:                 parameters.Input,
+		Value:                 parameters.Value,
+		CodeHash:              &codeHash,
+		Code:                  c.GetCode(codeAddress),
+	})
+	c.depth--
+	c.static = static
+	if err != nil || !result.Success {
+		c.RestoreSnapshot(snapshot)
+	}
+	return tosca.CallResult{
+		Output:    result.Output,
+		GasLeft:   result.GasLeft,
+		GasRefund: result.GasRefund,
+		Success:   result.Success,
+	}, err
+}
+
+// Costs and limits of contract creation of the yellow paper and EIP-3541;
+// that of EIP-170 is corpus.MaxCodeSize.
+const (
+	codeDepositGas    = 200 // per byte of deployed code
+	invalidCodePrefix = 0xef
+)
+
+// create executes a nested CREATE or CREATE2 like floria and geth's
+// evm.Create: the nonce of the sender is incremented, the address derived
+// from it or from the salt and the initcode hash, and the code returned by
+// the initcode deposited at 200 gas per byte. Collisions, exceptional halts
+// and invalid or unaffordable code consume all gas; reverts return the gas
+// left and their output. The interpreter charges the initcode metering of
+// EIP-3860 before calling.
+func (c *toscaContext) create(kind tosca.CallKind, parameters tosca.CallParameters) (tosca.CallResult, error) {
+	failed := tosca.CallResult{GasLeft: parameters.Gas}
+	if c.depth >= maxCallDepth || c.GetBalance(parameters.Sender).Cmp(parameters.Value) < 0 {
+		return failed, nil
+	}
+	nonce := c.GetNonce(parameters.Sender)
+	if nonce+1 < nonce {
+		return failed, nil
+	}
+	c.SetNonce(parameters.Sender, nonce+1)
+
+	code := tosca.Code(parameters.Input)
+	codeHash := keccak(code)
+	address := tosca.Address(crypto.CreateAddress(common.Address(parameters.Sender), nonce))
+	if kind == tosca.Create2 {
+		address = tosca.Address(crypto.CreateAddress2(common.Address(parameters.Sender), common.Hash(parameters.Salt), codeHash[:]))
+	}
+	if c.block.Revision >= tosca.R09_Berlin {
+		c.AccessAccount(address)
+	}
+	if existing := c.account(address); existing != nil && (existing.nonce != 0 || len(existing.code) > 0 || !c.HasEmptyStorage(address)) {
+		return tosca.CallResult{}, nil
+	}
+
+	snapshot := c.CreateSnapshot()
+	account := c.getOrCreate(address)
+	account.created = true
+	c.record(func() { account.created = false })
+	c.SetNonce(address, 1)
+	if parameters.Value != (tosca.Value{}) {
+		c.SetBalance(parameters.Sender, tosca.Sub(c.GetBalance(parameters.Sender), parameters.Value))
+		c.SetBalance(address, tosca.Add(c.GetBalance(address), parameters.Value))
+	}
+
+	c.depth++
+	result, err := c.interpreter.Run(tosca.Parameters{
+		BlockParameters:       c.block,
+		TransactionParameters: c.transaction,
+		Context:               c,
+		Kind:                  kind,
+		Static:                c.static,
+		Depth:                 c.depth,
+		Gas:                   parameters.Gas,
+		Recipient:             address,
+		Sender:                parameters.Sender,
+		Value:                 parameters.Value,
+		CodeHash:              &codeHash,
+		Code:                  code,
+	})
+	c.depth--
+	if err != nil || !result.Success {
+		c.RestoreSnapshot(snapshot)
+		if err != nil || (result.GasLeft == 0 && len(result.Output) == 0) {
+			return tosca.CallResult{}, err
+		}
+		return tosca.CallResult{Output: result.Output, GasLeft: result.GasLeft, CreatedAddress: address}, nil
+	}
+
+	deployed := result.Output
+	deposit := tosca.Gas(codeDepositGas * len(deployed))
+	invalid := c.block.Revision >= tosca.R10_London && len(deployed) > 0 && deployed[0] == invalidCodePrefix
+	if len(deployed) > corpus.MaxCodeSize || invalid || result.GasLeft < deposit {
+		c.RestoreSnapshot(snapshot)
+		return tosca.CallResult{}, nil
+	}
+	c.SetCode(address, tosca.Code(deployed))
+	return tosca.CallResult{
+		GasLeft:        result.GasLeft - deposit,
+		GasRefund:      result.GasRefund,
+		Success:        true,
+		CreatedAddress: address,
+	}, nil
+}
+
+// toscaPrecompiles returns the precompiled contracts of a revision.
+func toscaPrecompiles(revision tosca.Revision) map[common.Address]geth.PrecompiledContract {
+	switch {
+	case revision >= tosca.R13_Cancun:
+		return geth.PrecompiledContractsCancun
+	case revision >= tosca.R09_Berlin:
+		return geth.PrecompiledContractsBerlin
+	default:
+		return geth.PrecompiledContractsIstanbul
+	}
+}
+
+// toscaPrecompileAddresses lists the precompiles warmed at the start of a
+// transaction.
+func toscaPrecompileAddresses(revision tosca.Revision) []tosca.Address {
+	var res []tosca.Address
+	for address := range toscaPrecompiles(revision) {
+		res = append(res, tosca.Address(address))
+	}
+	return res
+}
+
+func runPrecompile(precompile geth.PrecompiledContract, parameters tosca.CallParameters) tosca.CallResult {
+	cost := tosca.Gas(precompile.RequiredGas(parameters.Input))
+	if cost > parameters.Gas {
+		return tosca.CallResult{}
+	}
+	output, err := precompile.Run(parameters.Input)
+	if err != nil {
+		return tosca.CallResult{}
+	}
+	return tosca.CallResult{Output: output, GasLeft: parameters.Gas - cost, Success: true}
+}
+
+func keccak(data []byte) tosca.Hash {
+	var res tosca.Hash
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(data)
+	hasher.Sum(res[:0])
+	return res
+}
@@ -0,0 +1,141 @@
+// Workload contract benchmarks for Tosca LFVM
+// Executes the calls of the compiled contract corpus and checks them
+// against the expected outcomes committed with the fixtures.
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/0xsoniclabs/tosca/go/interpreter/lfvm"
+	"github.com/0xsoniclabs/tosca/go/tosca"
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+)
+
+var outcomesFlag = flag.String("outcomes", "", "write the outcomes observed by TestContracts, TestFailures and TestDeployments as NDJSON to this file")
+
+func loadContracts(tb testing.TB) []corpus.Contract {
+	tb.Helper()
+	root, err := corpus.Root()
+	if err != nil {
+		tb.Fatalf("Failed to locate corpus: %v", err)
+	}
+	contracts, err := corpus.LoadContracts(root)
+	if err != nil {
+		tb.Fatalf("Failed to load contracts: %v", err)
+	}
+	return contracts
+}
+
+// runToscaVector executes a vector once, writing its trace if -traces is set.
+func runToscaVector(interpreter tosca.Interpreter, vector corpus.Vector) (corpus.Outcome, error) {
+	if *tracesFlag == "" {
+		runner := newToscaRunner(interpreter, vector)
+		result, err := runner.run()
+		return runner.outcome(result), err
+	}
+	tracer, err := createToscaTracer(vector.Name)
+	if err != nil {
+		return corpus.Outcome{}, err
+	}
+	runner := newToscaRunner(tracer, vector)
+	result, err := runner.run()
+	return runner.outcome(result), errors.Join(err, tracer.close())
+}
+
+func TestContracts(t *testing.T) {
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+
+	var vectors []corpus.Vector
+	for _, contract := range loadContracts(t) {
+		vectors = append(vectors, contract.Vectors()...)
+	}
+	checkVectors(t, "lfvm", vectors, func(vector corpus.Vector) (corpus.Outcome, error) {
+		return runToscaVector(interpreter, vector)
+	})
+}
+
+// checkVectors runs every vector in a sub-test, checks its outcome against
+// the expected one and appends the observed outcomes to the -outcomes file.
+func checkVectors(t *testing.T, engine string, vectors []corpus.Vector, run func(corpus.Vector) (corpus.Outcome, error)) {
+	var observations []results.Observation
+	for _, vector := range vectors {
+		t.Run(vector.Name, func(t *testing.T) {
+			outcome, err := run(vector)
+			if err != nil {
+				t.Fatalf("Execution failed: %v", err)
+			}
+			observations = append(observations, results.Observation{
+				Key:     results.Key{Engine: engine, Revision: corpusRevision(), Vector: vector.Name},
+				Outcome: outcome,
+			})
+			if err := vector.Expect.Check(outcome); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	writeOutcomes(t, observations)
+}
+
+// truncateOutcomes empties the -outcomes file before the first test of the
+// binary appends to it.
+var truncateOutcomes = sync.OnceValue(func() error {
+	return os.WriteFile(*outcomesFlag, nil, 0o644)
+})
+
+// writeOutcomes appends observations to the -outcomes file, if set.
+func writeOutcomes(t *testing.T, observations []results.Observation) {
+	if *outcomesFlag == "" {
+		return
+	}
+	if err := truncateOutcomes(); err != nil {
+		t.Fatalf("Failed to write outcomes: %v", err)
+	}
+	if err := results.AppendObservations(*outcomesFlag, observations); err != nil {
+		t.Fatalf("Failed to write outcomes: %v", err)
+	}
+}
+
+// checkStatus fails the benchmark unless the outcome has the status the
+// vector expects, if any.
+func checkStatus(b *testing.B, vector corpus.Vector, outcome corpus.Outcome) {
+	if vector.Expect != nil && outcome.Status != vector.Expect.Status {
+		b.Fatalf("Execution ended with %s, want %s", outcome.Status, vector.Expect.Status)
+	}
+}
+
+func BenchmarkContracts(b *testing.B) {
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		b.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+
+	for _, contract := range loadContracts(b) {
+		for _, vector := range contract.Vectors() {
+			b.Run(vector.Name, func(b *testing.B) {
+				runner := newToscaRunner(interpreter, vector)
+				run := func() {
+					result, err := runner.run()
+					if err != nil {
+						b.Fatalf("Execution failed: %v", err)
+					}
+					checkStatus(b, vector, toscaOutcome(vector.Gas, result))
+				}
+				run() // warm-up
+				b.ResetTimer()
+				session := startPerf(b)
+				for i := 0; i < b.N; i++ {
+					run()
+				}
+				stopPerf(b, session, vector)
+			})
+		}
+	}
+}
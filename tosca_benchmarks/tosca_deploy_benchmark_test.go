@@ -0,0 +1,63 @@
+// Contract deployment benchmarks for Tosca LFVM
+// Executes the deployment vectors of the shared corpus, whose factory runs
+// the initcode of the BEP20 token and the workload contracts with CREATE
+// and CREATE2, and checks the created address, code hash and code size.
+package main
+
+import (
+	"testing"
+
+	"github.com/0xsoniclabs/tosca/go/interpreter/lfvm"
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+// loadDeployments returns the deployment vectors executed in the revision.
+func loadDeployments(tb testing.TB) []corpus.Vector {
+	tb.Helper()
+	vectors, err := corpus.Deployments(corpusRevision())
+	if err != nil {
+		tb.Fatalf("Failed to load deployments: %v", err)
+	}
+	if len(vectors) == 0 {
+		tb.Skipf("deployments require %s", corpus.DeploymentsSince)
+	}
+	return vectors
+}
+
+func TestDeployments(t *testing.T) {
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+
+	checkVectors(t, "lfvm", loadDeployments(t), func(vector corpus.Vector) (corpus.Outcome, error) {
+		return runToscaVector(interpreter, vector)
+	})
+}
+
+func BenchmarkDeployments(b *testing.B) {
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		b.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+
+	for _, vector := range loadDeployments(b) {
+		b.Run(vector.Name, func(b *testing.B) {
+			runner := newToscaRunner(interpreter, vector)
+			run := func() {
+				result, err := runner.run()
+				if err != nil {
+					b.Fatalf("Execution failed: %v", err)
+				}
+				checkStatus(b, vector, toscaOutcome(vector.Gas, result))
+			}
+			run() // warm-up
+			b.ResetTimer()
+			session := startPerf(b)
+			for i := 0; i < b.N; i++ {
+				run()
+			}
+			stopPerf(b, session, vector)
+		})
+	}
+}
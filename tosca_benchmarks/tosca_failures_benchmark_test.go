@@ -0,0 +1,50 @@
+// Failure path benchmarks for Tosca LFVM
+// Executes the failure vectors of the shared corpus, which run out of gas,
+// jump to invalid destinations, under- or overflow the stack, hit INVALID
+// or revert, and times the cost of failing.
+package main
+
+import (
+	"testing"
+
+	"github.com/0xsoniclabs/tosca/go/interpreter/lfvm"
+	"github.com/sonicoperations/evmbench/corpus"
+)
+
+func TestFailures(t *testing.T) {
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+
+	checkVectors(t, "lfvm", corpus.FailureVectors(), func(vector corpus.Vector) (corpus.Outcome, error) {
+		return runToscaVector(interpreter, vector)
+	})
+}
+
+func BenchmarkFailures(b *testing.B) {
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		b.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+
+	for _, vector := range corpus.FailureVectors() {
+		b.Run(vector.Name, func(b *testing.B) {
+			runner := newToscaRunner(interpreter, vector)
+			run := func() {
+				result, err := runner.run()
+				if err != nil {
+					b.Fatalf("Execution failed: %v", err)
+				}
+				checkStatus(b, vector, toscaOutcome(vector.Gas, result))
+			}
+			run() // warm-up
+			b.ResetTimer()
+			session := startPerf(b)
+			for i := 0; i < b.N; i++ {
+				run()
+			}
+			stopPerf(b, session, vector)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Mixed workload of Tosca LFVM under the garbage collector
+// Executes the soak mix, the contract corpus, the failure vectors and, from
+// Shanghai, the deployments, round-robin on one long-lived LFVM instance and
+// times every execution, so that the tail latencies caused by GC assists and
+// pauses show up next to the throughput. Latencies are kept per vector and
+// reported as geometric means over the vectors. evmbench gcsweep runs it
+// under a grid of GOGC and GOMEMLIMIT values.
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/0xsoniclabs/tosca/go/interpreter/lfvm"
+	"github.com/sonicoperations/evmbench/latency"
+)
+
+func BenchmarkMixedWorkload(b *testing.B) {
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		b.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+	mix := soakWorkload(b).Mix
+	runners := make([]*toscaRunner, len(mix))
+	for i, vector := range mix {
+		runners[i] = newToscaRunner(interpreter, vector)
+		if _, err := runners[i].run(); err != nil {
+			b.Fatalf("%s: %v", vector.Name, err)
+		}
+	}
+	latencies := make([]latency.Histogram, len(mix))
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := time.Now()
+		if _, err := runners[i%len(runners)].run(); err != nil {
+			b.Fatalf("%s: %v", mix[i%len(mix)].Name, err)
+		}
+		latencies[i%len(mix)].Record(time.Since(start))
+	}
+	b.StopTimer()
+	runtime.ReadMemStats(&after)
+	latency.Report(latencies, b.ReportMetric)
+	b.ReportMetric(float64(after.NumGC-before.NumGC)/b.Elapsed().Seconds(), "gc/s")
+}
@@ -0,0 +1,295 @@
+// Matrix of all interpreters registered in the Tosca registry
+// Runs the corpus on every registered interpreter (lfvm plus the
+// experimental super-instruction, no-sha-cache, no-code-cache, statistics
+// and logging variants) and reports each variant relative to plain lfvm.
+// Run the report with: go test -run TestInterpreterVariantReport -variant-report
+//
+// Interpreters registered by other imported packages are picked up as well.
+// Tosca's geth adapter (go/interpreter/geth) is not among them: it requires
+// the Sonic fork of go-ethereum, while this module builds against upstream
+// go-ethereum, so it cannot be linked here.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xsoniclabs/tosca/go/interpreter/lfvm"
+	"github.com/0xsoniclabs/tosca/go/tosca"
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/results"
+)
+
+var (
+	variantReport  = flag.Bool("variant-report", false, "print the per-variant report of TestInterpreterVariantReport")
+	variantFilter  = flag.String("variants", "", "regular expression selecting the interpreter variants to run; all if empty")
+	variantSamples = flag.Int("variant-samples", 5, "samples per vector and variant in the variant report")
+	variantOut     = flag.String("variant-out", "", "write the variant report measurements as result records to this file")
+)
+
+// baseVariant is the interpreter all variants are related to.
+const baseVariant = "lfvm"
+
+var (
+	registerOnce sync.Once
+	registerErr  error
+)
+
+// registerExperimentalInterpreters registers the experimental LFVM
+// configurations once per process; registering them twice fails. The
+// logging variants write every executed instruction to the os.Stdout of
+// registration time, which is pointed at the null device meanwhile so
+// their cost is measured without flooding the test output.
+func registerExperimentalInterpreters() error {
+	registerOnce.Do(func() {
+		var null *os.File
+		null, registerErr = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		if registerErr != nil {
+			return
+		}
+		stdout := os.Stdout
+		os.Stdout = null
+		defer func() { os.Stdout = stdout }()
+		registerErr = lfvm.RegisterExperimentalInterpreterConfigurations()
+	})
+	return registerErr
+}
+
+// interpreterVariants returns the names of all registered interpreters
+// selected with -variants, the base variant first and the others sorted.
+func interpreterVariants(tb testing.TB) []string {
+	tb.Helper()
+	if err := registerExperimentalInterpreters(); err != nil {
+		tb.Fatalf("Failed to register experimental configurations: %v", err)
+	}
+	filter, err := regexp.Compile(*variantFilter)
+	if err != nil {
+		tb.Fatalf("Invalid -variants: %v", err)
+	}
+	var res []string
+	for name := range tosca.GetAllRegisteredInterpreters() {
+		if name != baseVariant && filter.MatchString(name) {
+			res = append(res, name)
+		}
+	}
+	sort.Strings(res)
+	return append([]string{baseVariant}, res...)
+}
+
+func newInterpreterVariant(tb testing.TB, name string) tosca.Interpreter {
+	tb.Helper()
+	interpreter, err := tosca.NewInterpreter(name)
+	if err != nil {
+		tb.Fatalf("Failed to create interpreter %s: %v", name, err)
+	}
+	return interpreter
+}
+
+func TestInterpreterVariants(t *testing.T) {
+	contracts := loadContracts(t)
+	for _, variant := range interpreterVariants(t) {
+		interpreter := newInterpreterVariant(t, variant)
+		t.Run(variant, func(t *testing.T) {
+			for _, contract := range contracts {
+				for _, vector := range contract.Vectors() {
+					t.Run(vector.Name, func(t *testing.T) {
+						runner := newToscaRunner(interpreter, vector)
+						result, err := runner.run()
+						if err != nil {
+							t.Fatalf("Execution failed: %v", err)
+						}
+						if err := vector.Expect.Check(runner.outcome(result)); err != nil {
+							t.Error(err)
+						}
+					})
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkInterpreterVariants(b *testing.B) {
+	contracts := loadContracts(b)
+	for _, variant := range interpreterVariants(b) {
+		interpreter := newInterpreterVariant(b, variant)
+		b.Run(variant, func(b *testing.B) {
+			for _, contract := range contracts {
+				for _, vector := range contract.Vectors() {
+					b.Run(vector.Name, func(b *testing.B) {
+						runner := newToscaRunner(interpreter, vector)
+						b.ResetTimer()
+						for i := 0; i < b.N; i++ {
+							if _, err := runner.run(); err != nil {
+								b.Fatalf("Execution failed: %v", err)
+							}
+						}
+					})
+				}
+			}
+		})
+	}
+}
+
+// sampleDuration is the minimum time measured per sample of the variant
+// report.
+const sampleDuration = 10 * time.Millisecond
+
+// measureRunner returns the mean time of a run in nanoseconds, running the
+// vector at least once and for at least sampleDuration.
+func measureRunner(t *testing.T, runner *toscaRunner) float64 {
+	start := time.Now()
+	runs := 0
+	for runs == 0 || time.Since(start) < sampleDuration {
+		if _, err := runner.run(); err != nil {
+			t.Fatalf("Execution failed: %v", err)
+		}
+		runs++
+	}
+	return float64(time.Since(start).Nanoseconds()) / float64(runs)
+}
+
+// TestInterpreterVariantReport measures every vector on every variant and
+// summarizes the time of each variant relative to plain lfvm. Samples of
+// all variants are interleaved so that drifts of the machine affect all of
+// them alike.
+func TestInterpreterVariantReport(t *testing.T) {
+	if !*variantReport {
+		t.Skip("use -variant-report to print the interpreter variant report")
+	}
+	variants := interpreterVariants(t)
+	interpreters := make([]tosca.Interpreter, len(variants))
+	for i, variant := range variants {
+		interpreters[i] = newInterpreterVariant(t, variant)
+	}
+
+	env := results.HostEnv()
+	measured := &results.Results{}
+	for _, contract := range loadContracts(t) {
+		for _, vector := range contract.Vectors() {
+			runners := make([]*toscaRunner, len(variants))
+			records := make([]results.Record, len(variants))
+			for i, variant := range variants {
+				runners[i] = newToscaRunner(interpreters[i], vector)
+				records[i] = results.Record{
+					Key:        results.Key{Engine: variant, Revision: corpusRevision(), Vector: vector.Name},
+					VectorHash: vector.Hash(),
+					Env:        env,
+				}
+			}
+			for sample := 0; sample < *variantSamples; sample++ {
+				for i, runner := range runners {
+					records[i].Samples = append(records[i].Samples, results.Sample{
+						Iterations: 1,
+						NsPerOp:    measureRunner(t, runner),
+					})
+				}
+			}
+			measured.Records = append(measured.Records, records...)
+		}
+	}
+
+	if *variantOut != "" {
+		if err := results.WriteFile(*variantOut, measured); err != nil {
+			t.Fatalf("Failed to write measurements: %v", err)
+		}
+	}
+	if err := variantSummary(measured, 0.05).Write(os.Stdout, "markdown"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// variantSummary relates the median times of every variant to those of
+// the base variant. The geometric mean of the ratios over all vectors
+// summarizes a variant; vectors count as faster or slower if the
+// difference is significant at level alpha.
+func variantSummary(measured *results.Results, alpha float64) results.Table {
+	table := results.Table{Header: []string{"Variant", "vs " + baseVariant, "faster", "slower", "best", "worst"}}
+	for _, variant := range measured.Engines() {
+		if variant == baseVariant {
+			continue
+		}
+		logSum, count, faster, slower := 0.0, 0, 0, 0
+		best, worst := "", ""
+		bestRatio, worstRatio := math.Inf(1), math.Inf(-1)
+		for _, record := range measured.Records {
+			if record.Engine != variant {
+				continue
+			}
+			base, found := measured.Lookup(results.Key{Engine: baseVariant, Revision: record.Revision, Vector: record.Vector})
+			if !found {
+				continue
+			}
+			a, b := base.Values("ns/op"), record.Values("ns/op")
+			baseTime, variantTime := results.Summarize(a).Median, results.Summarize(b).Median
+			if baseTime == 0 || variantTime == 0 {
+				continue
+			}
+			ratio := variantTime / baseTime
+			logSum += math.Log(ratio)
+			count++
+			if results.MannWhitneyU(a, b) <= alpha {
+				if ratio < 1 {
+					faster++
+				} else {
+					slower++
+				}
+			}
+			if ratio < bestRatio {
+				bestRatio, best = ratio, record.Vector
+			}
+			if ratio > worstRatio {
+				worstRatio, worst = ratio, record.Vector
+			}
+		}
+		if count == 0 {
+			continue
+		}
+		table.Rows = append(table.Rows, []string{
+			variant,
+			fmt.Sprintf("%.2fx", math.Exp(logSum/float64(count))),
+			fmt.Sprint(faster),
+			fmt.Sprint(slower),
+			fmt.Sprintf("%s %.2fx", best, bestRatio),
+			fmt.Sprintf("%s %.2fx", worst, worstRatio),
+		})
+	}
+	return table
+}
+
+func TestVariantSummary_RelatesVariantsToLFVM(t *testing.T) {
+	samples := func(values ...float64) []results.Sample {
+		var res []results.Sample
+		for _, v := range values {
+			res = append(res, results.Sample{NsPerOp: v})
+		}
+		return res
+	}
+	record := func(variant, vector string, values ...float64) results.Record {
+		return results.Record{
+			Key:     results.Key{Engine: variant, Revision: corpus.Cancun, Vector: vector},
+			Samples: samples(values...),
+		}
+	}
+	measured := &results.Results{Records: []results.Record{
+		record("lfvm", "a", 100, 101, 102, 103, 104),
+		record("lfvm", "b", 100, 101, 102, 103, 104),
+		record("lfvm-si", "a", 50, 51, 52, 53, 54),
+		record("lfvm-si", "b", 200, 201, 202, 203, 204),
+	}}
+
+	table := variantSummary(measured, 0.05)
+	if len(table.Rows) != 1 {
+		t.Fatalf("unexpected rows: %v", table.Rows)
+	}
+	want := []string{"lfvm-si", "1.00x", "1", "1", "a 0.51x", "b 1.98x"}
+	if got := table.Rows[0]; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("unexpected row, wanted %v, got %v", want, got)
+	}
+}
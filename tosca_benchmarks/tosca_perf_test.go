@@ -0,0 +1,59 @@
+// Hardware performance counters for Tosca LFVM benchmarks
+// Run with: go test -bench BenchmarkContracts -perf [-perf-out lfvm.ndjson]
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/perf"
+)
+
+var (
+	perfEnabled = flag.Bool("perf", false, "collect hardware performance counters in benchmarks")
+	perfOut     = flag.String("perf-out", "", "write performance counter records as NDJSON to this file")
+
+	perfCollector   perf.Collector
+	perfUnavailable bool
+	perfPartial     bool
+)
+
+// startPerf starts the counters of a benchmark if enabled. Benchmarks run
+// without counters if they are unavailable.
+func startPerf(b *testing.B) *perf.Session {
+	if !*perfEnabled || perfUnavailable {
+		return nil
+	}
+	session, err := perf.Start()
+	if err != nil {
+		b.Logf("Running without performance counters: %v", err)
+		perfUnavailable = true
+		return nil
+	}
+	if missing := session.Unavailable(); len(missing) > 0 && !perfPartial {
+		b.Logf("Performance counters not supported: %v", missing)
+		perfPartial = true
+	}
+	return session
+}
+
+// stopPerf stops the counters and reports them as benchmark metrics. LFVM
+// does not count EVM instructions; per-step values are derived from the BSC
+// records when comparing.
+func stopPerf(b *testing.B, session *perf.Session, vector corpus.Vector) {
+	if session == nil {
+		return
+	}
+	b.StopTimer()
+	counts, err := session.Stop()
+	if err != nil {
+		b.Fatalf("Failed to read performance counters: %v", err)
+	}
+	record := perf.Record{Engine: "lfvm", Vector: vector.Name, Runs: b.N, Counts: counts}
+	record.ReportMetrics(b.ReportMetric)
+	perfCollector.Path = *perfOut
+	if err := perfCollector.Add(record); err != nil {
+		b.Fatalf("Failed to write performance counters: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+// Soak mode of Tosca LFVM
+// Runs a mix of the contract corpus, the failure vectors and, from
+// Shanghai, the deployments on a single long-lived LFVM instance for the
+// duration of -soak and samples the heap, GC pauses, goroutines and the
+// entries of LFVM's conversion cache into the time series of -soak-out.
+// Every -soak-fresh-th execution calls a contract with code never seen
+// before, whose new code hash makes the cache keyed by it convert and add
+// the code. Fails if memory, the cache or goroutines keep growing after the
+// warm-up. Run it with: go test -run TestSoak -soak 10m -timeout 0
+package main
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/0xsoniclabs/tosca/go/interpreter/lfvm"
+	"github.com/0xsoniclabs/tosca/go/tosca"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/soak"
+)
+
+var (
+	soakFlag         = flag.Duration("soak", 0, "run TestSoak for this duration")
+	soakIntervalFlag = flag.Duration("soak-interval", 5*time.Second, "interval between the runtime samples of TestSoak")
+	soakOutFlag      = flag.String("soak-out", "", "write the runtime samples of TestSoak as NDJSON time series to this file")
+	soakFreshFlag    = flag.Int("soak-fresh", 10, "every n-th execution of TestSoak calls code with a new code hash, 0 for never")
+)
+
+// lfvmCache returns the conversion cache of an LFVM interpreter, held in
+// the unexported converter field, or nil if the interpreter has none. Tosca
+// has no accessor for it; TestLfvmCache_FollowsConversions fails if the
+// fields move.
+func lfvmCache(interpreter tosca.Interpreter) (*lru.Cache[tosca.Hash, lfvm.Code], error) {
+	value := reflect.ValueOf(interpreter)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unexpected LFVM interpreter %T", interpreter)
+	}
+	converter := value.Elem().FieldByName("converter")
+	if !converter.IsValid() || converter.Type() != reflect.TypeFor[*lfvm.Converter]() {
+		return nil, fmt.Errorf("LFVM interpreter %T has no converter", interpreter)
+	}
+	cache := reflect.ValueOf((*lfvm.Converter)(unsafe.Pointer(converter.Pointer()))).Elem().FieldByName("cache")
+	if !cache.IsValid() || cache.Type() != reflect.TypeFor[*lru.Cache[tosca.Hash, lfvm.Code]]() {
+		return nil, fmt.Errorf("LFVM converter has no conversion cache")
+	}
+	return (*lru.Cache[tosca.Hash, lfvm.Code])(unsafe.Pointer(cache.Pointer())), nil
+}
+
+// soakWorkload returns the soak mix executed in the revision.
+func soakWorkload(tb testing.TB) soak.Workload {
+	tb.Helper()
+	workload, err := soak.NewWorkload(corpusRevision(), *soakFreshFlag)
+	if err != nil {
+		tb.Fatalf("Failed to load the soak mix: %v", err)
+	}
+	return workload
+}
+
+func TestSoak(t *testing.T) {
+	if *soakFlag == 0 {
+		t.Skip("use -soak <duration> to run the soak mode")
+	}
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+	cache, err := lfvmCache(interpreter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workload := soakWorkload(t)
+	mix, fresh := workload.Mix, workload.Fresh
+	runners := make([]*toscaRunner, len(mix))
+	for i, vector := range mix {
+		runners[i] = newToscaRunner(interpreter, vector)
+	}
+	freshRunners := make([]*toscaRunner, len(fresh))
+	for i, vector := range fresh {
+		freshRunners[i] = newToscaRunner(interpreter, vector)
+	}
+
+	run := func(runner *toscaRunner, vector corpus.Vector) error {
+		result, err := runner.run()
+		if err != nil {
+			return fmt.Errorf("%s: %w", vector.Name, err)
+		}
+		if status := runner.outcome(result).Status; vector.Expect != nil && status != vector.Expect.Status {
+			return fmt.Errorf("%s: status %s, want %s", vector.Name, status, vector.Expect.Status)
+		}
+		return nil
+	}
+	step := func(n uint64) error {
+		i, code := workload.Step(n)
+		if code == nil {
+			return run(runners[i], mix[i])
+		}
+		runner := freshRunners[i]
+		codeHash := keccak(code)
+		runner.params.Code, runner.params.CodeHash = code, &codeHash
+		return run(runner, fresh[i])
+	}
+	samples, err := soak.Run(soak.Config{
+		Engine:   "lfvm",
+		Duration: *soakFlag,
+		Interval: *soakIntervalFlag,
+		Out:      *soakOutFlag,
+	}, step, cache.Len)
+	if err != nil {
+		t.Fatalf("Soak failed: %v", err)
+	}
+	final := samples[len(samples)-1]
+	t.Logf("%d runs in %v, %d bytes live heap, %d GC cycles, %d cache entries", final.Runs, final.Elapsed, final.HeapLive, final.GCCycles, final.CacheEntries)
+	if err := soak.Check(samples, soak.DefaultLimits); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFreshCode_BehavesLikeOriginal(t *testing.T) {
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+	cache, err := lfvmCache(interpreter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh := soakWorkload(t).Fresh
+	for n, vector := range fresh {
+		vector.Code = soak.FreshCode(vector.Code, uint64(n))
+		runner := newToscaRunner(interpreter, vector)
+		result, err := runner.run()
+		if err != nil {
+			t.Fatalf("%s: %v", vector.Name, err)
+		}
+		if err := vector.Expect.Check(runner.outcome(result)); err != nil {
+			t.Errorf("%s: %v", vector.Name, err)
+		}
+	}
+	if cache.Len() != len(fresh) {
+		t.Errorf("conversion cache holds %d entries, wanted %d", cache.Len(), len(fresh))
+	}
+}
+
+func TestLfvmCache_FollowsConversions(t *testing.T) {
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+	cache, err := lfvmCache(interpreter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache == nil || cache.Len() != 0 {
+		t.Fatalf("expected an empty conversion cache, got %v", cache)
+	}
+	vector := corpus.FailureVectors()[0]
+	for n, want := range []int{1, 1, 2} {
+		if n == 2 {
+			vector.Code = soak.FreshCode(vector.Code, 0)
+		}
+		if _, err := newToscaRunner(interpreter, vector).run(); err != nil {
+			t.Fatalf("%s: %v", vector.Name, err)
+		}
+		if got := cache.Len(); got != want {
+			t.Errorf("after run %d the conversion cache holds %d entries, wanted %d", n, got, want)
+		}
+	}
+}
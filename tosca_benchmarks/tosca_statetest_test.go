@@ -0,0 +1,487 @@
+// Ethereum GeneralStateTests on Tosca LFVM
+// Reads the fixtures from a local checkout given with -statetests, or the
+// corpus sample if unset, and executes every subtest as an Ethereum
+// transaction on the in-memory world state of toscaContext.
+//
+// Tosca's Ethereum compatible transaction processor needs the Sonic fork of
+// go-ethereum and floria follows Sonic's fee rules, so the transaction
+// level (validation, gas purchase, fees, refunds and the removal of
+// destructed and empty accounts) is implemented here. Transactions
+// without recipient create a contract through toscaContext like CREATE
+// does. The post-state root and logs hash are computed with go-ethereum's
+// trie and compared to the fixtures, as are the post-state accounts if the
+// fixture lists them.
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/0xsoniclabs/tosca/go/interpreter/lfvm"
+	"github.com/0xsoniclabs/tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/holiman/uint256"
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/statetest"
+)
+
+var (
+	stateTestsFlag   = flag.String("statetests", "", "GeneralStateTests fixture directory or file; the corpus sample if empty")
+	stateTestFilter  = flag.String("statetest-filter", "", "regular expression selecting subtests by <test>/<fork>/d<i>g<j>v<k>")
+	stateTestResults = flag.String("statetest-results", "", "write the state test results as NDJSON to this file")
+	stateTestVectors = flag.String("statetest-vectors", "", "write failed state tests converted to corpus vectors to this JSON file")
+	vectorFile       = flag.String("vector-file", "", "JSON file of vectors timed by BenchmarkVectorFile")
+)
+
+func TestStateTests(t *testing.T) {
+	filter, err := regexp.Compile(*stateTestFilter)
+	if err != nil {
+		t.Fatalf("Invalid -statetest-filter: %v", err)
+	}
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+	options := statetest.Options{Fixtures: *stateTestsFlag, Results: *stateTestResults, Vectors: *stateTestVectors}
+	loaded, err := options.Load()
+	if err != nil {
+		t.Fatalf("Failed to load state tests: %v", err)
+	}
+	res := statetest.Run(loaded, filter, "lfvm", func(subtest statetest.Subtest) (statetest.Status, string) {
+		if *tracesFlag == "" {
+			return runToscaStateTest(interpreter, subtest)
+		}
+		tracer, err := createToscaTracer(subtest.Name())
+		if err != nil {
+			return statetest.Fail, err.Error()
+		}
+		defer tracer.close()
+		return runToscaStateTest(tracer, subtest)
+	})
+	if err := options.Report(t, os.Stdout, loaded, res); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// BenchmarkVectorFile times the vectors of a -vector-file, e.g. failed
+// state tests converted with -statetest-vectors.
+func BenchmarkVectorFile(b *testing.B) {
+	if *vectorFile == "" {
+		b.Skip("use -vector-file to benchmark vectors of a file")
+	}
+	vectors, err := corpus.ReadVectors(*vectorFile)
+	if err != nil {
+		b.Fatalf("Failed to read vectors: %v", err)
+	}
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		b.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+	for _, vector := range vectors {
+		b.Run(vector.Name, func(b *testing.B) {
+			runner := newToscaRunner(interpreter, vector)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := runner.run(); err != nil {
+					b.Fatalf("Execution failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// stateTestChainID is the chain ID of all state tests.
+const stateTestChainID = 1
+
+// Blob gas parameters of EIP-4844.
+const (
+	blobGasPerBlob            = 1 << 17
+	minBlobBaseFee            = 1
+	blobBaseFeeUpdateFraction = 3338477
+)
+
+// stateTestBlockHash returns the block hashes of state tests, the hash of
+// the block number's decimal representation.
+func stateTestBlockHash(number int64) tosca.Hash {
+	return keccak([]byte(strconv.FormatInt(number, 10)))
+}
+
+// runToscaStateTest executes a subtest on LFVM and checks the post-state.
+func runToscaStateTest(interpreter tosca.Interpreter, subtest statetest.Subtest) (statetest.Status, string) {
+	revision, _ := subtest.Revision()
+	recipient, isCall := subtest.Recipient()
+	test, post := subtest.Test, subtest.Post()
+	tx := test.Transaction
+
+	sender, err := stateTestSender(tx)
+	if err != nil {
+		return statetest.Fail, err.Error()
+	}
+	block := stateTestBlock(test.Env, toscaRevisions[revision])
+	context := newToscaContext(interpreter, block, tosca.TransactionParameters{})
+	context.blockHash = stateTestBlockHash
+	for address, account := range test.Pre {
+		storage := make(map[tosca.Key]tosca.Word, len(account.Storage))
+		for key, value := range account.Storage {
+			storage[tosca.Key(key)] = tosca.Word(value)
+		}
+		context.setAccount(tosca.Address(address), tosca.Code(account.Code), storage)
+		context.accounts[tosca.Address(address)].balance = tosca.Value(account.Balance)
+		context.accounts[tosca.Address(address)].nonce = uint64(account.Nonce)
+	}
+
+	gasPrice, tip, invalid := buyStateTestGas(context, subtest, sender, revision)
+	if invalid != "" {
+		if post.ExpectException != "" {
+			return statetest.Pass, ""
+		}
+		return statetest.Fail, "unexpected invalid transaction: " + invalid
+	}
+	if post.ExpectException != "" {
+		return statetest.Fail, "expected exception " + post.ExpectException
+	}
+
+	// Warm the accounts and slots of EIP-2929, EIP-2930 and EIP-3651.
+	accessList, _ := subtest.AccessList()
+	if revision >= corpus.Berlin {
+		context.warm(sender)
+		if isCall {
+			context.warm(tosca.Address(recipient))
+		}
+		context.warm(toscaPrecompileAddresses(block.Revision)...)
+		for _, tuple := range accessList {
+			context.warm(tosca.Address(tuple.Address))
+			for _, key := range tuple.StorageKeys {
+				context.accessSlots[toscaSlot{tosca.Address(tuple.Address), tosca.Key(key)}] = true
+			}
+		}
+	}
+	if revision >= corpus.Shanghai {
+		context.warm(block.Coinbase)
+	}
+
+	var blobHashes []tosca.Hash
+	for _, hash := range tx.BlobVersionedHashes {
+		blobHashes = append(blobHashes, tosca.Hash(hash))
+	}
+	context.transaction = tosca.TransactionParameters{Origin: sender, GasPrice: gasPrice, BlobHashes: blobHashes}
+
+	// The transaction's message call or creation runs at depth 0, Call
+	// enters the next depth. Creations increment the sender's nonce there.
+	gasLimit := subtest.GasLimit()
+	intrinsic := statetest.IntrinsicGas(subtest.Data(), accessList, !isCall, revision)
+	kind := tosca.Call
+	if !isCall {
+		kind = tosca.Create
+	}
+	context.depth = -1
+	result, err := context.Call(kind, tosca.CallParameters{
+		Sender:    sender,
+		Recipient: tosca.Address(recipient),
+		Value:     tosca.Value(subtest.Value()),
+		Input:     tosca.Data(subtest.Data()),
+		Gas:       tosca.Gas(gasLimit - intrinsic),
+	})
+	context.depth = 0
+	if err != nil {
+		return statetest.Fail, fmt.Sprintf("execution failed: %v", err)
+	}
+
+	// Refund unused gas and the capped refund counter, and pay the tip.
+	gasLeft := uint64(result.GasLeft)
+	quotient := uint64(2)
+	if revision >= corpus.London {
+		quotient = 5
+	}
+	gasLeft += min(uint64(result.GasRefund), (gasLimit-gasLeft)/quotient)
+	context.SetBalance(sender, tosca.Add(context.GetBalance(sender), gasPrice.Scale(gasLeft)))
+	context.SetBalance(block.Coinbase, tosca.Add(context.GetBalance(block.Coinbase), tip.Scale(gasLimit-gasLeft)))
+
+	finalizeStateTest(context, revision)
+	if root := stateRoot(context); root != common.Hash(post.Hash) {
+		return statetest.Fail, fmt.Sprintf("post state root mismatch: got %x, want %x%s", root, post.Hash, diffStateTestAccounts(context, post.State))
+	}
+	if logs := logsHash(context.GetLogs()); logs != common.Hash(post.Logs) {
+		return statetest.Fail, fmt.Sprintf("post state logs hash mismatch: got %x, want %x", logs, post.Logs)
+	}
+	if diff := diffStateTestAccounts(context, post.State); diff != "" {
+		return statetest.Fail, "post state accounts differ:" + diff
+	}
+	return statetest.Pass, ""
+}
+
+// stateTestSender returns the sender of a transaction, given explicitly or
+// by its secret key.
+func stateTestSender(tx statetest.Transaction) (tosca.Address, error) {
+	if tx.Sender != nil {
+		return tosca.Address(*tx.Sender), nil
+	}
+	key, err := crypto.ToECDSA(tx.SecretKey)
+	if err != nil {
+		return tosca.Address{}, fmt.Errorf("invalid secret key: %w", err)
+	}
+	return tosca.Address(crypto.PubkeyToAddress(*key.Public().(*ecdsa.PublicKey))), nil
+}
+
+// stateTestBlock returns the block parameters of a test's environment,
+// with the defaults of go-ethereum's state test driver.
+func stateTestBlock(env statetest.Env, revision tosca.Revision) tosca.BlockParameters {
+	var chainID tosca.Word
+	chainID[31] = stateTestChainID
+	block := tosca.BlockParameters{
+		ChainID:     chainID,
+		BlockNumber: int64(env.Number),
+		Timestamp:   int64(env.Timestamp),
+		Coinbase:    tosca.Address(env.Coinbase),
+		GasLimit:    tosca.Gas(env.GasLimit),
+		Revision:    revision,
+	}
+	if env.Difficulty != nil {
+		block.PrevRandao = tosca.Hash(*env.Difficulty)
+	}
+	if revision >= tosca.R10_London {
+		block.BaseFee = tosca.NewValue(0x0a)
+		if env.BaseFee != nil {
+			block.BaseFee = tosca.Value(*env.BaseFee)
+		}
+		if env.Random != nil {
+			block.PrevRandao = tosca.Hash(*env.Random)
+		}
+	}
+	if revision >= tosca.R13_Cancun {
+		excess := uint64(0)
+		if env.ExcessBlobGas != nil {
+			excess = uint64(*env.ExcessBlobGas)
+		}
+		fee := fakeExponential(uint256.NewInt(minBlobBaseFee), uint256.NewInt(excess), uint256.NewInt(blobBaseFeeUpdateFraction))
+		block.BlobBaseFee = fee.Bytes32()
+	}
+	return block
+}
+
+// fakeExponential approximates factor * e ** (numerator / denominator) as
+// specified by EIP-4844.
+func fakeExponential(factor, numerator, denominator *uint256.Int) *uint256.Int {
+	output, accumulator := new(uint256.Int), new(uint256.Int).Mul(factor, denominator)
+	for i := uint64(1); !accumulator.IsZero(); i++ {
+		output.Add(output, accumulator)
+		accumulator.Mul(accumulator, numerator)
+		accumulator.Div(accumulator, new(uint256.Int).Mul(denominator, uint256.NewInt(i)))
+	}
+	return output.Div(output, denominator)
+}
+
+// buyStateTestGas validates the transaction of a subtest against the
+// pre-state, following go-ethereum's pre-checks, and charges the sender
+// for the gas limit and blob gas and, for message calls, increments its
+// nonce. It returns the
+// effective gas price, the tip per gas paid to the coinbase and, for
+// invalid transactions, the reason.
+func buyStateTestGas(context *toscaContext, subtest statetest.Subtest, sender tosca.Address, revision corpus.Revision) (gasPrice, tip tosca.Value, invalid string) {
+	tx, block := subtest.Test.Transaction, context.block
+	_, hasAccessList := subtest.AccessList()
+	dynamicFee := tx.MaxFeePerGas != nil
+	switch {
+	case hasAccessList && revision < corpus.Berlin:
+		return gasPrice, tip, "access list transaction before Berlin"
+	case dynamicFee && revision < corpus.London:
+		return gasPrice, tip, "dynamic fee transaction before London"
+	case len(tx.BlobVersionedHashes) > 0 && revision < corpus.Cancun:
+		return gasPrice, tip, "blob transaction before Cancun"
+	}
+
+	feeCap, tipCap := tosca.Value{}, tosca.Value{}
+	if dynamicFee {
+		feeCap = tosca.Value(*tx.MaxFeePerGas)
+		if tx.MaxPriorityFeePerGas != nil {
+			tipCap = tosca.Value(*tx.MaxPriorityFeePerGas)
+		}
+	} else if tx.GasPrice != nil {
+		feeCap, tipCap = tosca.Value(*tx.GasPrice), tosca.Value(*tx.GasPrice)
+	}
+	gasPrice, tip = feeCap, feeCap
+	if revision >= corpus.London {
+		if tipCap.Cmp(feeCap) > 0 {
+			return gasPrice, tip, "max priority fee per gas higher than max fee per gas"
+		}
+		if feeCap.Cmp(block.BaseFee) < 0 {
+			return gasPrice, tip, "max fee per gas less than block base fee"
+		}
+		tip = tosca.Min(tipCap, tosca.Sub(feeCap, block.BaseFee))
+		gasPrice = tosca.Add(block.BaseFee, tip)
+	}
+
+	gasLimit := subtest.GasLimit()
+	accessList, _ := subtest.AccessList()
+	_, isCall := subtest.Recipient()
+	switch {
+	case uint64(tx.Nonce) != context.GetNonce(sender):
+		return gasPrice, tip, "nonce mismatch"
+	case len(context.GetCode(sender)) > 0:
+		return gasPrice, tip, "sender not an EOA"
+	case gasLimit > uint64(block.GasLimit):
+		return gasPrice, tip, "gas limit exceeds block gas limit"
+	case gasLimit < statetest.IntrinsicGas(subtest.Data(), accessList, !isCall, revision):
+		return gasPrice, tip, "intrinsic gas too low"
+	case !isCall && revision >= corpus.Shanghai && len(subtest.Data()) > corpus.MaxInitCodeSize:
+		return gasPrice, tip, "max initcode size exceeded"
+	}
+
+	blobGas := uint64(len(tx.BlobVersionedHashes)) * blobGasPerBlob
+	cost := tosca.Add(feeCap.Scale(gasLimit), tosca.Value(subtest.Value()))
+	if blobGas > 0 {
+		if tx.MaxFeePerBlobGas == nil || tosca.Value(*tx.MaxFeePerBlobGas).Cmp(block.BlobBaseFee) < 0 {
+			return gasPrice, tip, "max fee per blob gas less than blob base fee"
+		}
+		cost = tosca.Add(cost, tosca.Value(*tx.MaxFeePerBlobGas).Scale(blobGas))
+	}
+	balance := context.GetBalance(sender)
+	if balance.Cmp(cost) < 0 {
+		return gasPrice, tip, "insufficient funds"
+	}
+
+	charge := tosca.Add(gasPrice.Scale(gasLimit), block.BlobBaseFee.Scale(blobGas))
+	context.SetBalance(sender, tosca.Sub(balance, charge))
+	if isCall {
+		context.SetNonce(sender, context.GetNonce(sender)+1)
+	}
+	return gasPrice, tip, ""
+}
+
+// finalizeStateTest removes the accounts destructed by the transaction,
+// all of them before Cancun and since then those it created, and the
+// accounts it touched and left empty, as EIP-161 requires. Empty accounts
+// of the pre-state the transaction did not touch remain.
+func finalizeStateTest(context *toscaContext, revision corpus.Revision) {
+	for address, account := range context.accounts {
+		destructed := account.selfDestructed && (revision < corpus.Cancun || account.created)
+		empty := context.touched[address] && account.nonce == 0 && account.balance == (tosca.Value{}) && len(account.code) == 0
+		if destructed || empty {
+			delete(context.accounts, address)
+		}
+	}
+}
+
+// stateRoot computes the Merkle Patricia root of the context's accounts.
+func stateRoot(context *toscaContext) common.Hash {
+	entries := map[common.Hash][]byte{}
+	for address, account := range context.accounts {
+		storage := map[common.Hash][]byte{}
+		for key, value := range account.storage {
+			if value != (tosca.Word{}) {
+				encoded, _ := rlp.EncodeToBytes(bytes.TrimLeft(value[:], "\x00"))
+				storage[crypto.Keccak256Hash(key[:])] = encoded
+			}
+		}
+		encoded, err := rlp.EncodeToBytes(&types.StateAccount{
+			Nonce:    account.nonce,
+			Balance:  new(uint256.Int).SetBytes32(account.balance[:]),
+			Root:     trieRoot(storage),
+			CodeHash: crypto.Keccak256(account.code),
+		})
+		if err != nil {
+			panic(err) // accounts consist of plain values
+		}
+		entries[crypto.Keccak256Hash(address[:])] = encoded
+	}
+	return trieRoot(entries)
+}
+
+// trieRoot returns the root of a trie holding the given entries.
+func trieRoot(entries map[common.Hash][]byte) common.Hash {
+	keys := make([]common.Hash, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i][:], keys[j][:]) < 0 })
+	stack := trie.NewStackTrie(nil)
+	for _, key := range keys {
+		stack.Update(key[:], entries[key])
+	}
+	return stack.Hash()
+}
+
+// logsHash returns the keccak256 hash of the RLP encoded logs.
+func logsHash(logs []tosca.Log) common.Hash {
+	converted := make([]*types.Log, 0, len(logs))
+	for _, log := range logs {
+		topics := make([]common.Hash, 0, len(log.Topics))
+		for _, topic := range log.Topics {
+			topics = append(topics, common.Hash(topic))
+		}
+		converted = append(converted, &types.Log{Address: common.Address(log.Address), Topics: topics, Data: log.Data})
+	}
+	encoded, err := rlp.EncodeToBytes(converted)
+	if err != nil {
+		panic(err) // logs consist of plain values
+	}
+	return crypto.Keccak256Hash(encoded)
+}
+
+// diffStateTestAccounts lists the differences between the context and the
+// expected post-state accounts, if the fixture provides them.
+func diffStateTestAccounts(context *toscaContext, expected map[corpus.Address]statetest.Account) string {
+	var diffs []string
+	for address, want := range expected {
+		got := context.account(tosca.Address(address))
+		if got == nil {
+			diffs = append(diffs, fmt.Sprintf("%s missing", address))
+			continue
+		}
+		if got.balance != tosca.Value(want.Balance) {
+			diffs = append(diffs, fmt.Sprintf("%s balance %x, want %x", address, got.balance, want.Balance))
+		}
+		if got.nonce != uint64(want.Nonce) {
+			diffs = append(diffs, fmt.Sprintf("%s nonce %d, want %d", address, got.nonce, want.Nonce))
+		}
+		if !bytes.Equal(got.code, want.Code) {
+			diffs = append(diffs, fmt.Sprintf("%s code differs", address))
+		}
+		for key, value := range want.Storage {
+			if got.storage[tosca.Key(key)] != tosca.Word(value) {
+				diffs = append(diffs, fmt.Sprintf("%s storage %s = %x, want %s", address, key, got.storage[tosca.Key(key)], value))
+			}
+		}
+		for key, value := range got.storage {
+			if _, found := want.Storage[corpus.Hash(key)]; !found && value != (tosca.Word{}) {
+				diffs = append(diffs, fmt.Sprintf("%s unexpected storage %x", address, key))
+			}
+		}
+	}
+	sort.Strings(diffs)
+	if len(diffs) == 0 {
+		return ""
+	}
+	return "\n\t" + strings.Join(diffs, "\n\t")
+}
+
+func TestFinalizeStateTest_RemovesOnlyTouchedEmptyAccounts(t *testing.T) {
+	untouched, touched, reverted := tosca.Address{1}, tosca.Address{2}, tosca.Address{3}
+	context := newToscaContext(nil, tosca.BlockParameters{}, tosca.TransactionParameters{})
+	for _, address := range []tosca.Address{untouched, touched, reverted} {
+		context.setAccount(address, nil, nil)
+	}
+	context.SetBalance(touched, tosca.Value{})
+	snapshot := context.CreateSnapshot()
+	context.SetNonce(reverted, 0)
+	context.RestoreSnapshot(snapshot)
+
+	finalizeStateTest(context, corpus.Cancun)
+	for address, want := range map[tosca.Address]bool{untouched: true, touched: false, reverted: true} {
+		if got := context.AccountExists(address); got != want {
+			t.Errorf("account %x exists: got %t, want %t", address[:1], got, want)
+		}
+	}
+}
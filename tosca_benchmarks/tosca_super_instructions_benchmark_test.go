@@ -158,7 +158,8 @@ func BenchmarkRealWorldPatterns(b *testing.B) {
 		// ERC20 transfer function pattern
 		"ERC20_Transfer": "6000357fffffffff0000000000000000000000000000000000000000000000000000000016806370a082311461006957806395d89b41146100a0578063a9059cbb146100ca578063dd62ed3e146101035763f2fde38b14610136576000600060405180910390fd5b61009c60048036036020811015610080575f80fd5b81019080803573ffffffffffffffffffffffffffffffffffffffff16906020019092919050505061016f565b5050005b6100c860048036036040811015610b7575f80fd5b8101908080359060200190929190803590602001909291905050506001b5565b005b610101600480360360408110156100e1575f80fd5b8101908080359060200190929190803590602001909291905050506102b8565b005b61013460048036036040811015610m9575f80fd5b81019080803590602001909291908035906020019092919050505061041a565b005b6100c860048036036020811015610400575f80fd5b8101908080359060200190929190505050610460565b005b505050",
 
-		// Contract creation pattern
+		// Contract creation pattern, run as a plain call; BenchmarkDeployments
+		// runs initcode through CREATE and CREATE2
 		"Contract_Creation": "608060405234801561001057600080fd5b506040516020806108a08339810180604052810190808051906020019092919050505080600090805190602001906100499291906100de565b5050600160026000600173ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff16815260200190815260200160002060006101000a81548160ff0219169083151502179055505b005b828054600181600116156101000203166002900490600052602060002090601f016020900481019282601f1061011f57805160ff191683800117855561014c565b8280016001018555821561014c579182015b8281111561014b578251825591602001919060010190610130565b5b50905061015991906101dd565b50905b6101fa565b808211156101f65760008160009055506001016101e0565b5090",
 
 		// Complex arithmetic operations from DeFi contracts
@@ -185,7 +186,7 @@ func BenchmarkRealWorldPatterns(b *testing.B) {
 	}{"Standard", stdInterpreter})
 
 	// LFVM with super instructions
-	err = lfvm.RegisterExperimentalInterpreterConfigurations()
+	err = registerExperimentalInterpreters()
 	if err == nil {
 		siInterpreter, err := tosca.NewInterpreter("lfvm-si", nil)
 		if err == nil {
@@ -0,0 +1,370 @@
+// EIP-3155 traces of LFVM
+// Written with -traces for every vector of TestContracts and subtest of
+// TestStateTests. LFVM offers no tracing hooks; its logging runner prints
+// only the opcode, gas and top of stack, too little for EIP-3155. So
+// toscaTracer executes the LFVM code itself one instruction at a time
+// through LFVM's unexported steps function, and maps LFVM program counters
+// back to EVM ones with the observer of its code converter. The mirrors of
+// LFVM's unexported types and functions are pinned to the Tosca version of
+// lfvmMirroredVersion and verified against LFVM's layout before the first
+// trace is taken; TestLfvmMirrors_MatchTosca checks both on every run.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/0xsoniclabs/tosca/go/interpreter/lfvm"
+	"github.com/0xsoniclabs/tosca/go/tosca"
+	"github.com/holiman/uint256"
+	"github.com/sonicoperations/evmbench/asm"
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/trace"
+)
+
+var tracesFlag = flag.String("traces", "", "write EIP-3155 traces of the vectors and state tests run by TestContracts and TestStateTests to this directory")
+
+// lfvmMirroredVersion is the Tosca version the mirrors and go:linkname
+// declarations below were written against. Review them when go.mod moves
+// Tosca to another version and update the pin.
+const lfvmMirroredVersion = "v0.0.0-20250708111444-f020a558b11e"
+
+// lfvmContext mirrors LFVM's execution context.
+type lfvmContext struct {
+	params       tosca.Parameters
+	context      tosca.RunContext
+	code         lfvm.Code
+	pc           int32
+	gas          tosca.Gas
+	refund       tosca.Gas
+	stack        *lfvmStack
+	memory       *lfvmMemory
+	returnData   []byte
+	withShaCache bool
+}
+
+// lfvmStack mirrors LFVM's stack.
+type lfvmStack struct {
+	data         [1024]uint256.Int
+	stackPointer int
+}
+
+// lfvmMemory mirrors LFVM's Memory.
+type lfvmMemory struct {
+	store             []byte
+	currentMemoryCost tosca.Gas
+}
+
+// lfvmInstruction mirrors LFVM's Instruction.
+type lfvmInstruction struct {
+	opcode lfvm.OpCode
+	arg    uint16
+}
+
+// The statuses of LFVM code that has not ended and that failed.
+const (
+	lfvmStatusRunning = 0
+	lfvmStatusFailed  = 5
+)
+
+//go:linkname lfvmSteps github.com/0xsoniclabs/tosca/go/interpreter/lfvm.steps
+func lfvmSteps(c *lfvmContext, oneStepOnly bool) (byte, error)
+
+//go:linkname lfvmGenerateResult github.com/0xsoniclabs/tosca/go/interpreter/lfvm.generateResult
+func lfvmGenerateResult(status byte, c *lfvmContext) (tosca.Result, error)
+
+//go:linkname lfvmConvert github.com/0xsoniclabs/tosca/go/interpreter/lfvm.convertWithObserver
+func lfvmConvert(code []byte, options lfvm.ConversionConfig, observer func(evmPc int, lfvmPc int)) lfvm.Code
+
+// lfvmLayout verifies that the mirrors match LFVM's types.
+var lfvmLayout = sync.OnceValue(func() error {
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		return err
+	}
+	// The context type is the parameter of the runner interface's method.
+	config, _ := reflect.TypeOf(interpreter).Elem().FieldByName("config")
+	runner, _ := config.Type.FieldByName("runner")
+	if runner.Type == nil || runner.Type.Kind() != reflect.Interface || runner.Type.NumMethod() != 1 {
+		return fmt.Errorf("LFVM runner type not found")
+	}
+	context := runner.Type.Method(0).Type.In(0).Elem()
+	return errors.Join(
+		lfvmCheckLayout(context, reflect.TypeOf(lfvmContext{})),
+		lfvmCheckLayout(reflect.TypeOf(lfvm.NewStack()).Elem(), reflect.TypeOf(lfvmStack{})),
+		lfvmCheckLayout(reflect.TypeOf(lfvm.Memory{}), reflect.TypeOf(lfvmMemory{})),
+		lfvmCheckLayout(reflect.TypeOf(lfvm.Instruction{}), reflect.TypeOf(lfvmInstruction{})),
+	)
+})
+
+// lfvmCheckLayout compares the fields of an LFVM type with its mirror.
+// Pointers to unexported types are compared by kind only, all other fields
+// must have identical types.
+func lfvmCheckLayout(actual, mirror reflect.Type) error {
+	if actual.NumField() != mirror.NumField() || actual.Size() != mirror.Size() {
+		return fmt.Errorf("LFVM type %v does not match %v", actual, mirror)
+	}
+	for i := 0; i < actual.NumField(); i++ {
+		a, m := actual.Field(i), mirror.Field(i)
+		if a.Name != m.Name || a.Offset != m.Offset || a.Type.Kind() != m.Type.Kind() ||
+			(a.Type.Kind() != reflect.Pointer && a.Type != m.Type) {
+			return fmt.Errorf("LFVM field %v.%s %v does not match %s %v", actual, a.Name, a.Type, m.Name, m.Type)
+		}
+	}
+	return nil
+}
+
+// toscaTracer is an interpreter executing code like LFVM while writing
+// every executed instruction to a trace. Nested calls run on the tracer
+// as well when it is the interpreter of the toscaContext.
+type toscaTracer struct {
+	writer *trace.Writer
+	frames []*lfvmContext // the active call frames, outermost first
+	err    error
+}
+
+var _ tosca.Interpreter = &toscaTracer{}
+
+// refund returns the refund counter of the transaction: the refunds of the
+// active frames, which include those of their completed nested calls.
+func (t *toscaTracer) refund() uint64 {
+	var res tosca.Gas
+	for _, frame := range t.frames {
+		res += frame.refund
+	}
+	return uint64(max(res, 0))
+}
+
+func (t *toscaTracer) Run(params tosca.Parameters) (tosca.Result, error) {
+	if err := lfvmLayout(); err != nil {
+		return tosca.Result{}, err
+	}
+	if params.Revision > tosca.R14_Prague {
+		return tosca.Result{}, &tosca.ErrUnsupportedRevision{Revision: params.Revision}
+	}
+	if len(params.Code) == 0 {
+		return tosca.Result{GasLeft: params.Gas, Success: true}, nil
+	}
+	toEvm := make([]int, len(params.Code)+1)
+	code := lfvmConvert(params.Code, lfvm.ConversionConfig{}, func(evmPc, lfvmPc int) { toEvm[lfvmPc] = evmPc })
+	if len(code) >= len(toEvm) {
+		return tosca.Result{}, fmt.Errorf("converted code of %d instructions exceeds %d", len(code), len(params.Code))
+	}
+	// Running past the end of the code is an implicit STOP.
+	toEvm[len(code)] = len(params.Code)
+
+	stack := lfvm.NewStack()
+	defer lfvm.ReturnStack(stack)
+	c := &lfvmContext{
+		params:       params,
+		context:      params.Context,
+		code:         code,
+		gas:          params.Gas,
+		stack:        (*lfvmStack)(unsafe.Pointer(stack)),
+		memory:       (*lfvmMemory)(unsafe.Pointer(lfvm.NewMemory())),
+		withShaCache: true,
+	}
+	t.frames = append(t.frames, c)
+	defer func() { t.frames = t.frames[:len(t.frames)-1] }()
+
+	status := byte(lfvmStatusRunning)
+	for status == lfvmStatusRunning {
+		// JUMP_TO skips the padding in front of a JUMPDEST, which is not
+		// an EVM instruction.
+		if int(c.pc) < len(code) && (*lfvmInstruction)(unsafe.Pointer(&code[c.pc])).opcode == lfvm.JUMP_TO {
+			status, _ = lfvmSteps(c, true)
+			continue
+		}
+		pc := toEvm[c.pc]
+		op := byte(0) // STOP past the end of the code
+		if pc < len(params.Code) {
+			op = params.Code[pc]
+		}
+		step := trace.Step{
+			Pc:      uint64(pc),
+			Op:      op,
+			Gas:     trace.Quantity(c.gas),
+			MemSize: len(c.memory.store),
+			Stack:   make([]trace.Word, c.stack.stackPointer),
+			Depth:   params.Depth + 1,
+			Refund:  t.refund(),
+		}
+		for i := range step.Stack {
+			step.Stack[i] = c.stack.data[i].Bytes32()
+		}
+		// Steps starting a frame are written before the steps of the frame,
+		// without their cost, and repeated if they fail as go-ethereum does.
+		frame := isFrameOp(op)
+		if frame {
+			t.err = errors.Join(t.err, t.writer.Step(step))
+		}
+		var err error
+		status, err = lfvmSteps(c, true)
+		if c.gas < tosca.Gas(step.Gas) {
+			step.GasCost = trace.Quantity(tosca.Gas(step.Gas) - c.gas)
+		}
+		// go-ethereum charges the refunds of these with their gas, before
+		// the step is logged.
+		if op == 0x55 || op == 0xff {
+			step.Refund = t.refund()
+		}
+		if err != nil {
+			step.Error = err.Error()
+			status = lfvmStatusFailed
+		}
+		if !frame || err != nil {
+			t.err = errors.Join(t.err, t.writer.Step(step))
+		}
+	}
+	result, err := lfvmGenerateResult(status, c)
+	if err == nil && params.Depth == 0 {
+		t.err = errors.Join(t.err, t.writer.Summary(trace.OutcomeSummary(toscaOutcome(uint64(params.Gas), result))))
+	}
+	return result, err
+}
+
+// isFrameOp reports whether op is a call or create instruction.
+func isFrameOp(op byte) bool {
+	switch op {
+	case 0xf0, 0xf1, 0xf2, 0xf4, 0xf5, 0xfa:
+		return true
+	}
+	return false
+}
+
+// createToscaTracer creates a tracer writing the trace file of a vector or
+// state test into the -traces directory.
+func createToscaTracer(name string) (*toscaTracer, error) {
+	writer, err := trace.Create(filepath.Join(*tracesFlag, trace.FileName(name)))
+	if err != nil {
+		return nil, err
+	}
+	return &toscaTracer{writer: writer}, nil
+}
+
+// close closes the trace file and reports any error writing the trace.
+func (t *toscaTracer) close() error {
+	return errors.Join(t.err, t.writer.Close())
+}
+
+// traceProgram is traced by both engines' tests, which expect the same
+// steps: memory expansion, a refund from resetting a slot and a REVERT.
+const traceProgram = `
+	PUSH1 0x20 PUSH1 0x01 MSTORE
+	PUSH1 0x07 PUSH1 0x00 SSTORE
+	PUSH1 0x00 PUSH1 0x00 SSTORE
+	PUSH1 0x00 SLOAD
+	PUSH1 0x00 PUSH1 0x00 REVERT`
+
+func TestLfvmMirrors_MatchTosca(t *testing.T) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		t.Fatal("no build info")
+	}
+	for _, module := range info.Deps {
+		if module.Path != "github.com/0xsoniclabs/tosca" {
+			continue
+		}
+		if module.Replace != nil {
+			module = module.Replace
+		}
+		if module.Version != lfvmMirroredVersion {
+			t.Errorf("Tosca %s differs from %s the LFVM mirrors were written for", module.Version, lfvmMirroredVersion)
+		}
+	}
+	if err := lfvmLayout(); err != nil {
+		t.Error(err)
+	}
+
+	// PUSH1 1 JUMPDEST STOP: the JUMPDEST is padded in front with JUMP_TO.
+	var pcs [][2]int
+	code := lfvmConvert([]byte{0x60, 0x01, 0x5b, 0x00}, lfvm.ConversionConfig{}, func(evmPc, lfvmPc int) {
+		pcs = append(pcs, [2]int{evmPc, lfvmPc})
+	})
+	if len(pcs) != 3 || pcs[0] != [2]int{0, 0} || pcs[2][0] != 3 || int(pcs[2][1]) >= len(code) {
+		t.Errorf("unexpected program counter mapping %v of %d instructions", pcs, len(code))
+	}
+	if op := (*lfvmInstruction)(unsafe.Pointer(&code[pcs[1][1]])).opcode; op != lfvm.JUMPDEST {
+		t.Errorf("unexpected instruction %v at the JUMPDEST", op)
+	}
+}
+
+func TestTrace_RecordsSteps(t *testing.T) {
+	var buffer bytes.Buffer
+	tracer := &toscaTracer{writer: trace.NewWriter(&buffer)}
+	vector := corpus.Vector{Name: "trace", Code: asm.MustAssemble(traceProgram), Gas: 100_000}
+	result, err := newToscaRunner(tracer, vector).run()
+	if err != nil || tracer.err != nil {
+		t.Fatal(errors.Join(err, tracer.err))
+	}
+	if outcome := toscaOutcome(vector.Gas, result); outcome.Status != corpus.Revert {
+		t.Errorf("unexpected outcome %+v", outcome)
+	}
+	got, err := trace.Read(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := func(values ...byte) []trace.Word {
+		res := []trace.Word{}
+		for _, value := range values {
+			res = append(res, trace.Word{31: value})
+		}
+		return res
+	}
+	step := func(pc uint64, op asm.Opcode, gas, cost uint64, memSize int, refund uint64, stack ...byte) trace.Step {
+		return trace.Step{Pc: pc, Op: byte(op), Gas: trace.Quantity(gas), GasCost: trace.Quantity(cost), MemSize: memSize, Stack: words(stack...), Depth: 1, Refund: refund}
+	}
+	expected := trace.Trace{Steps: []trace.Step{
+		step(0, asm.PUSH1, 100000, 3, 0, 0),
+		step(2, asm.PUSH1, 99997, 3, 0, 0, 0x20),
+		step(4, asm.MSTORE, 99994, 9, 0, 0, 0x20, 0x01),
+		step(5, asm.PUSH1, 99985, 3, 64, 0),
+		step(7, asm.PUSH1, 99982, 3, 64, 0, 0x07),
+		step(9, asm.SSTORE, 99979, 22100, 64, 0, 0x07, 0x00),
+		step(10, asm.PUSH1, 77879, 3, 64, 0),
+		step(12, asm.PUSH1, 77876, 3, 64, 0, 0x00),
+		step(14, asm.SSTORE, 77873, 100, 64, 19900, 0x00, 0x00),
+		step(15, asm.PUSH1, 77773, 3, 64, 19900),
+		step(17, asm.SLOAD, 77770, 100, 64, 19900, 0x00),
+		step(18, asm.PUSH1, 77670, 3, 64, 19900, 0x00),
+		step(20, asm.PUSH1, 77667, 3, 64, 19900, 0x00, 0x00),
+		step(22, asm.REVERT, 77664, 0, 64, 19900, 0x00, 0x00, 0x00),
+	}, Summary: &trace.Summary{GasUsed: 22336, Error: "execution reverted"}}
+	if d := trace.Diff(got, expected); d != nil {
+		t.Errorf("unexpected trace: %v", d)
+	}
+}
+
+func TestTrace_MatchesInterpreter(t *testing.T) {
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+	for _, contract := range loadContracts(t) {
+		for _, vector := range contract.Vectors() {
+			var buffer bytes.Buffer
+			tracer := &toscaTracer{writer: trace.NewWriter(&buffer)}
+			tracedRunner := newToscaRunner(tracer, vector)
+			traced, err := tracedRunner.run()
+			if err != nil || tracer.err != nil {
+				t.Fatalf("%s: %v", vector.Name, errors.Join(err, tracer.err))
+			}
+			runner := newToscaRunner(interpreter, vector)
+			result, err := runner.run()
+			if err != nil {
+				t.Fatalf("%s: %v", vector.Name, err)
+			}
+			if want, got := runner.outcome(result), tracedRunner.outcome(traced); want.Check(got) != nil || want.GasUsed != got.GasUsed || !corpus.EqualLogs(want.Logs, got.Logs) {
+				t.Errorf("%s: traced outcome %+v differs from %+v", vector.Name, got, want)
+			}
+		}
+	}
+}
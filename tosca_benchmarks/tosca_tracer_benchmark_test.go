@@ -0,0 +1,191 @@
+// Tracer overhead of Tosca LFVM
+// Tosca interpreters have no tracing hooks, so the counterparts of
+// go-ethereum's tracers are interpreters wrapping LFVM or observing it from
+// within: a no-op wrapper forwarding every frame, a call tracer recording
+// the frames, the experimental statistics and logging variants, and the
+// EIP-3155 step tracer of -traces writing to io.Discard. Every level
+// reports its slowdown against plain LFVM.
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/0xsoniclabs/tosca/go/tosca"
+	"github.com/sonicoperations/evmbench/asm"
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/trace"
+)
+
+// toscaTracerLevel is a tracing configuration of BenchmarkTracers.
+// newInterpreter returns the interpreter of an execution, a fresh one for
+// tracers holding state of a transaction.
+type toscaTracerLevel struct {
+	name           string
+	newInterpreter func() tosca.Interpreter
+}
+
+// toscaTracerLevels returns the levels from plain LFVM to the step tracer.
+func toscaTracerLevels(tb testing.TB) []toscaTracerLevel {
+	tb.Helper()
+	if err := registerExperimentalInterpreters(); err != nil {
+		tb.Fatalf("Failed to register experimental configurations: %v", err)
+	}
+	plain := newInterpreterVariant(tb, baseVariant)
+	shared := func(name string) func() tosca.Interpreter {
+		interpreter := newInterpreterVariant(tb, name)
+		return func() tosca.Interpreter { return interpreter }
+	}
+	return []toscaTracerLevel{
+		{"none", func() tosca.Interpreter { return plain }},
+		{"noop", func() tosca.Interpreter { return &toscaNoopTracer{interpreter: plain} }},
+		{"call", func() tosca.Interpreter { return &toscaCallTracer{interpreter: plain} }},
+		{"stats", shared("lfvm-stats")},
+		{"logging", shared("lfvm-logging")},
+		{"json", func() tosca.Interpreter { return &toscaTracer{writer: trace.NewWriter(io.Discard)} }},
+	}
+}
+
+// toscaNoopTracer forwards every frame to the interpreter it wraps, the
+// counterpart of go-ethereum's no-op tracer.
+type toscaNoopTracer struct {
+	interpreter tosca.Interpreter
+}
+
+func (t *toscaNoopTracer) Run(params tosca.Parameters) (tosca.Result, error) {
+	return t.interpreter.Run(params)
+}
+
+// toscaCallFrame is a frame recorded by toscaCallTracer.
+type toscaCallFrame struct {
+	kind          tosca.CallKind
+	from, to      tosca.Address
+	value         tosca.Value
+	gas, gasUsed  tosca.Gas
+	input, output []byte
+	success       bool
+	calls         []toscaCallFrame
+}
+
+// toscaCallTracer records the frames run by the interpreter it wraps, like
+// go-ethereum's call tracer. Calls of precompiles and accounts without code
+// do not reach the interpreter and are not recorded.
+type toscaCallTracer struct {
+	interpreter tosca.Interpreter
+	stack       []toscaCallFrame // the active frames, outermost first
+	root        toscaCallFrame   // of the last completed transaction
+}
+
+func (t *toscaCallTracer) Run(params tosca.Parameters) (tosca.Result, error) {
+	input := []byte(params.Input)
+	if params.Kind == tosca.Create || params.Kind == tosca.Create2 {
+		input = params.Code // the initcode, the input of a creation
+	}
+	t.stack = append(t.stack, toscaCallFrame{
+		kind:  params.Kind,
+		from:  params.Sender,
+		to:    params.Recipient,
+		value: params.Value,
+		gas:   params.Gas,
+		input: bytes.Clone(input),
+	})
+	result, err := t.interpreter.Run(params)
+	frame := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+	frame.gasUsed = params.Gas - result.GasLeft
+	frame.output = bytes.Clone(result.Output)
+	frame.success = result.Success
+	if len(t.stack) == 0 {
+		t.root = frame
+	} else {
+		parent := &t.stack[len(t.stack)-1]
+		parent.calls = append(parent.calls, frame)
+	}
+	return result, err
+}
+
+// run executes the vector once on the interpreter of the level.
+func (l toscaTracerLevel) run(runner *toscaRunner) (tosca.Result, error) {
+	runner.context.interpreter = l.newInterpreter()
+	return runner.run()
+}
+
+// TestTracers checks that no tracing level changes the outcome of a vector.
+func TestTracers(t *testing.T) {
+	levels := toscaTracerLevels(t)
+	for _, contract := range loadContracts(t) {
+		for _, vector := range contract.Vectors() {
+			t.Run(vector.Name, func(t *testing.T) {
+				for _, level := range levels {
+					runner := newToscaRunner(nil, vector)
+					result, err := level.run(runner)
+					if err != nil {
+						t.Fatalf("Execution with tracer %s failed: %v", level.name, err)
+					}
+					if err := vector.Expect.Check(runner.outcome(result)); err != nil {
+						t.Errorf("tracer %s: %v", level.name, err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestCallTracer_RecordsNestedFrames(t *testing.T) {
+	// The initcode PUSH1 0 PUSH1 0 RETURN is stored at 27 and created.
+	code := asm.MustAssemble(`
+		PUSH5 0x60006000f3 PUSH1 0 MSTORE
+		PUSH1 5 PUSH1 27 PUSH1 0 CREATE POP STOP`)
+	tracer := &toscaCallTracer{interpreter: newInterpreterVariant(t, baseVariant)}
+	runner := newToscaRunner(tracer, corpus.Vector{Name: "create", Code: code, Gas: 100_000})
+	result, err := runner.run()
+	if err != nil || !result.Success {
+		t.Fatalf("Execution failed: %v, %+v", err, result)
+	}
+	root := tracer.root
+	if root.kind != tosca.Call || root.to != tosca.Address(corpus.DefaultRecipient) || !root.success || root.gasUsed != 100_000-result.GasLeft {
+		t.Errorf("unexpected root frame %+v", root)
+	}
+	if len(root.calls) != 1 {
+		t.Fatalf("unexpected nested frames %+v", root.calls)
+	}
+	if create := root.calls[0]; create.kind != tosca.Create || create.from != root.to || !bytes.Equal(create.input, []byte{0x60, 0, 0x60, 0, 0xf3}) || !create.success {
+		t.Errorf("unexpected create frame %+v", create)
+	}
+}
+
+// BenchmarkTracers times the contract corpus at every tracing level, named
+// <vector>/<level>, after a warm-up run converting the code. Levels after
+// none report their slowdown, the time per execution relative to that of
+// none for the same vector.
+func BenchmarkTracers(b *testing.B) {
+	levels := toscaTracerLevels(b)
+	for _, contract := range loadContracts(b) {
+		for _, vector := range contract.Vectors() {
+			b.Run(vector.Name, func(b *testing.B) {
+				base := 0.0
+				for _, level := range levels {
+					b.Run(level.name, func(b *testing.B) {
+						runner := newToscaRunner(nil, vector)
+						if _, err := level.run(runner); err != nil {
+							b.Fatalf("Execution failed: %v", err)
+						}
+						b.ResetTimer()
+						for i := 0; i < b.N; i++ {
+							if _, err := level.run(runner); err != nil {
+								b.Fatalf("Execution failed: %v", err)
+							}
+						}
+						ns := float64(b.Elapsed().Nanoseconds()) / float64(b.N)
+						if level.name == "none" {
+							base = ns
+						} else if base > 0 {
+							b.ReportMetric(ns/base, "slowdown")
+						}
+					})
+				}
+			})
+		}
+	}
+}
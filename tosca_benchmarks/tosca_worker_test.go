@@ -0,0 +1,48 @@
+// Worker process for interleaved benchmarks with evmbench
+// Serves the evmbench worker protocol on stdin and stdout, executing vectors
+// shipped by the coordinator on LFVM. Started by evmbench interleave as
+// <test binary> -test.run '^TestWorker$' -worker -revision <revision>.
+package main
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/0xsoniclabs/tosca/go/interpreter/lfvm"
+	"github.com/sonicoperations/evmbench/corpus"
+	"github.com/sonicoperations/evmbench/worker"
+)
+
+var workerFlag = flag.Bool("worker", false, "serve the evmbench worker protocol on stdin and stdout")
+
+func TestWorker(t *testing.T) {
+	if !*workerFlag {
+		t.Skip("use -worker to serve the evmbench worker protocol")
+	}
+	interpreter, err := lfvm.NewInterpreter(lfvm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create LFVM interpreter: %v", err)
+	}
+
+	// Keep stray output off the protocol stream.
+	protocol := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = protocol }()
+
+	err = worker.Serve(os.Stdin, protocol, "lfvm", corpusRevision(), func(vector corpus.Vector) (worker.Runner, corpus.Outcome, error) {
+		runner := newToscaRunner(interpreter, vector)
+		result, err := runner.run()
+		if err != nil {
+			return nil, corpus.Outcome{}, err
+		}
+		run := func() error {
+			_, err := runner.run()
+			return err
+		}
+		return run, runner.outcome(result), nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("Worker failed: %v", err)
+	}
+}